@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
@@ -236,25 +237,25 @@ func (bd *BusinessDemo) testGitOperations() error {
 	defer bd.systemOps.RemoveDirectory(testDir)
 	
 	// 测试Git仓库检查
-	isRepo := bd.gitOps.IsRepo(testDir)
+	isRepo := bd.gitOps.IsRepo(context.Background(), testDir)
 	fmt.Printf("✅ Git仓库检查: %v\n", isRepo)
 	
 	// 测试Git初始化
-	success, err := bd.gitOps.InitRepo(testDir, true)
+	success, err := bd.gitOps.InitRepo(context.Background(), testDir, true)
 	if err != nil {
 		return fmt.Errorf("Git初始化失败: %w", err)
 	}
 	fmt.Printf("✅ Git初始化: %v\n", success)
 	
 	// 测试Git状态
-	status, err := bd.gitOps.GetStatus(testDir)
+	status, err := bd.gitOps.GetStatus(context.Background(), testDir)
 	if err != nil {
 		return fmt.Errorf("获取Git状态失败: %w", err)
 	}
 	fmt.Printf("✅ Git状态: %s\n", status)
 	
 	// 测试分支操作
-	branch, err := bd.gitOps.GetBranch(testDir)
+	branch, err := bd.gitOps.GetBranch(context.Background(), testDir)
 	if err != nil {
 		return fmt.Errorf("获取分支失败: %w", err)
 	}
@@ -323,7 +324,7 @@ func (bd *BusinessDemo) testErrorHandling() error {
 	fmt.Println("\n--- 测试错误处理 ---")
 	
 	// 测试无效路径的Git操作
-	_, err := bd.gitOps.GetStatus("/invalid/path/that/does/not/exist")
+	_, err := bd.gitOps.GetStatus(context.Background(), "/invalid/path/that/does/not/exist")
 	if err != nil {
 		fmt.Printf("✅ 错误处理测试: 正确捕获了无效路径错误\n")
 	}
@@ -528,7 +528,7 @@ func testGitOperations() {
 	
 	// 测试Git仓库检查
 	cwd, _ := os.Getwd()
-	if gitOps.IsRepo(cwd) {
+	if gitOps.IsRepo(context.Background(), cwd) {
 		fmt.Println("✓ 当前目录是Git仓库")
 	} else {
 		fmt.Println("✓ 当前目录不是Git仓库")
@@ -544,7 +544,7 @@ func testGitOperations() {
 	defer sysOps.RemoveDirectory(tempDir)
 	
 	// 测试Git仓库初始化
-	success, err := gitOps.InitRepo(tempDir, true)
+	success, err := gitOps.InitRepo(context.Background(), tempDir, true)
 	if err == nil && success {
 		fmt.Println("✓ Git仓库初始化成功")
 	} else if err == nil && !success {
@@ -1,6 +1,8 @@
 package business
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -16,19 +18,32 @@ type DownloadHandler struct {
 	templateProvider types.TemplateProvider
 	authProvider     types.AuthProvider
 	uiRenderer       types.UIRenderer
+
+	// 下载步骤解析出的发布版本和资源（用于结构化输出）
+	release      *types.GitHubRelease
+	asset        *types.Asset
+	templatePath string
 }
 
 // NewDownloadHandler 创建新的下载处理器
 func NewDownloadHandler() *DownloadHandler {
+	return NewDownloadHandlerWithConfig(nil)
+}
+
+// NewDownloadHandlerWithConfig 使用指定网络配置创建下载处理器
+func NewDownloadHandlerWithConfig(networkConfig *types.NetworkConfig) *DownloadHandler {
 	return &DownloadHandler{
-		templateProvider: infrastructure.NewTemplateProvider(),
-		authProvider:     infrastructure.NewAuthProvider(),
+		templateProvider: infrastructure.NewTemplateProviderWithConfig(networkConfig, nil),
+		authProvider:     infrastructure.NewAuthProviderWithConfig(networkConfig),
 		uiRenderer:       ui.NewRenderer(),
 	}
 }
 
 // Execute 执行下载流程
-func (h *DownloadHandler) Execute(opts types.DownloadOptions) error {
+//
+// ctx被取消（Ctrl-C/SIGTERM）时下载和解压尽快停止，未完成的下载保留在下载目录中，
+// 再次运行时继续下载。
+func (h *DownloadHandler) Execute(ctx context.Context, opts types.DownloadOptions) error {
 	// 创建步骤跟踪器
 	tracker := ui.NewStepTracker("Template Download")
 	
@@ -39,18 +54,50 @@ func (h *DownloadHandler) Execute(opts types.DownloadOptions) error {
 	tracker.Display()
 
 	// 执行下载流程
-	if err := h.executeSteps(tracker, opts); err != nil {
-		ui.ShowError(fmt.Sprintf("Download failed: %v", err))
+	if err := h.executeSteps(ctx, tracker, opts); err != nil {
+		if ctx.Err() != nil {
+			fmt.Println()
+			ui.ShowWarning("Download interrupted")
+			err = fmt.Errorf("download interrupted: %w", ctx.Err())
+		} else {
+			ui.ShowError(fmt.Sprintf("Download failed: %v", err))
+		}
+		ui.EmitResult("download", tracker, h.resultData(opts), err)
 		return err
 	}
 
 	// 显示完成状态
 	tracker.Display()
 	ui.ShowSuccess("Template download completed successfully!")
+	ui.EmitResult("download", tracker, h.resultData(opts), nil)
 	
 	return nil
 }
 
+// resultData 汇总结构化输出中的download结果数据
+func (h *DownloadHandler) resultData(opts types.DownloadOptions) map[string]interface{} {
+	data := map[string]interface{}{
+		"ai_assistant": opts.AIAssistant,
+		"script_type":  opts.ScriptType,
+		"download_dir": opts.DownloadDir,
+	}
+	if h.templatePath != "" {
+		data["template_path"] = h.templatePath
+	}
+	addReleaseData(data, h.release, h.asset)
+	return data
+}
+
+// addReleaseData 将解析出的发布版本和资源加入结果数据
+func addReleaseData(data map[string]interface{}, release *types.GitHubRelease, asset *types.Asset) {
+	if release != nil {
+		data["release"] = release.TagName
+	}
+	if asset != nil {
+		data["asset"] = asset
+	}
+}
+
 // setupSteps 设置下载步骤
 func (h *DownloadHandler) setupSteps(tracker *ui.StepTracker, opts types.DownloadOptions) {
 	tracker.AddStep("validate", "Validate download options")
@@ -62,7 +109,7 @@ func (h *DownloadHandler) setupSteps(tracker *ui.StepTracker, opts types.Downloa
 }
 
 // executeSteps 执行下载步骤
-func (h *DownloadHandler) executeSteps(tracker *ui.StepTracker, opts types.DownloadOptions) error {
+func (h *DownloadHandler) executeSteps(ctx context.Context, tracker *ui.StepTracker, opts types.DownloadOptions) error {
 	// 步骤1: 验证选项
 	if err := h.validateOptions(tracker, opts); err != nil {
 		return err
@@ -74,7 +121,7 @@ func (h *DownloadHandler) executeSteps(tracker *ui.StepTracker, opts types.Downl
 	}
 
 	// 步骤3: 下载模板
-	if err := h.downloadTemplate(tracker, opts); err != nil {
+	if err := h.downloadTemplate(ctx, tracker, opts); err != nil {
 		return err
 	}
 
@@ -132,6 +179,12 @@ func (h *DownloadHandler) validateOptions(tracker *ui.StepTracker, opts types.Do
 		}
 	}
 
+	// 验证冲突处理策略
+	if err := infrastructure.ValidateConflictResolution(opts.OnConflict); err != nil {
+		tracker.SetStepError("validate", err.Error())
+		return err
+	}
+
 	tracker.SetStepDone("validate", "Options validated successfully")
 	return nil
 }
@@ -164,16 +217,26 @@ func (h *DownloadHandler) prepareEnvironment(tracker *ui.StepTracker, opts types
 }
 
 // downloadTemplate 下载模板
-func (h *DownloadHandler) downloadTemplate(tracker *ui.StepTracker, opts types.DownloadOptions) error {
+func (h *DownloadHandler) downloadTemplate(ctx context.Context, tracker *ui.StepTracker, opts types.DownloadOptions) error {
 	tracker.SetStepRunning("download", "Downloading template files")
 
-	// 使用模板提供者下载
-	templatePath, err := h.templateProvider.Download(opts)
+	// 使用模板提供者下载。始终启用断点续传：下载被中断（Ctrl-C或进程被终止）后，
+	// 再次运行同一命令从<下载目录>/<资源>.part继续，远程文件变化时重新下载
+	opts.EnableResume = true
+	opts.OnResolved = func(release *types.GitHubRelease, asset *types.Asset) {
+		h.release, h.asset = release, asset
+	}
+	templatePath, err := h.templateProvider.Download(ctx, opts)
 	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			tracker.SetStepError("download", "Download cancelled")
+			return err
+		}
 		tracker.SetStepError("download", fmt.Sprintf("Download failed: %v", err))
 		return fmt.Errorf("failed to download template: %w", err)
 	}
 
+	h.templatePath = templatePath
 	if opts.Verbose {
 		ui.ShowInfo(fmt.Sprintf("Template downloaded to: %s", templatePath))
 	}
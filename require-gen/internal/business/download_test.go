@@ -0,0 +1,118 @@
+package business
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"specify-cli/internal/types"
+)
+
+// createTemplateZip 创建包含claude模板目录结构的压缩包
+func createTemplateZip(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	files := map[string]string{
+		"README.md": "# Template",
+		".claude/.specify/templates/spec-template.md": "# Spec",
+		".claude/.specify/scripts/setup.sh":           "#!/bin/sh\n" + strings.Repeat("# padding\n", 2000),
+	}
+	for name, content := range files {
+		f, err := w.Create(name)
+		require.NoError(t, err)
+		_, err = f.Write([]byte(content))
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	return buf.Bytes()
+}
+
+// TestDownloadHandler_ResumeInterrupted 测试中断的download在再次执行时从部分下载继续
+func TestDownloadHandler_ResumeInterrupted(t *testing.T) {
+	t.Setenv("GH_TOKEN", "")
+	t.Setenv("GITHUB_TOKEN", "")
+	// 发布信息缓存写入临时目录
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("LOCALAPPDATA", t.TempDir())
+
+	content := createTemplateZip(t)
+	half := len(content) / 2
+	const assetName = "spec-kit-template-claude-sh-v1.0.0.zip"
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		once     sync.Once
+		ranges   []string
+		ifRanges []string
+	)
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/repos/o/r/releases/latest":
+			fmt.Fprintf(w, `{"tag_name":"v1.0.0","assets":[{"name":"%s","size":%d,"browser_download_url":"%s/download/%s"}]}`,
+				assetName, len(content), server.URL, assetName)
+		case "/download/" + assetName:
+			w.Header().Set("ETag", `"v1"`)
+			if r.Method == http.MethodGet && ctx.Err() == nil {
+				// 第一次下载：发送一半数据后中断
+				w.Header().Set("Content-Range", fmt.Sprintf("bytes 0-%d/%d", len(content)-1, len(content)))
+				w.WriteHeader(http.StatusPartialContent)
+				w.Write(content[:half])
+				w.(http.Flusher).Flush()
+				// 留出时间让客户端写入已收到的数据
+				once.Do(func() { time.AfterFunc(200*time.Millisecond, cancel) })
+				<-r.Context().Done()
+				return
+			}
+			if rng := r.Header.Get("Range"); rng != "" {
+				mu.Lock()
+				ranges = append(ranges, rng)
+				ifRanges = append(ifRanges, r.Header.Get("If-Range"))
+				mu.Unlock()
+			}
+			http.ServeContent(w, r, assetName, time.Time{}, bytes.NewReader(content))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	downloadDir := t.TempDir()
+	opts := types.DownloadOptions{
+		AIAssistant:  "claude",
+		ScriptType:   "sh",
+		DownloadDir:  downloadDir,
+		TemplateRepo: "o/r",
+	}
+	networkConfig := &types.NetworkConfig{APIBaseURL: server.URL}
+
+	err := NewDownloadHandlerWithConfig(networkConfig).Execute(ctx, opts)
+	require.ErrorIs(t, err, context.Canceled)
+	assert.FileExists(t, filepath.Join(downloadDir, assetName+".part.json"), "partial download is kept")
+
+	require.NoError(t, NewDownloadHandlerWithConfig(networkConfig).Execute(context.Background(), opts))
+	data, err := os.ReadFile(filepath.Join(downloadDir, ".claude", ".specify", "templates", "spec-template.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# Spec", string(data))
+	assert.NoFileExists(t, filepath.Join(downloadDir, assetName+".part.json"))
+
+	// 只请求缺少的部分，并校验远程文件没有变化
+	assert.Equal(t, []string{fmt.Sprintf("bytes=%d-%d", half, len(content)-1)}, ranges)
+	assert.Equal(t, []string{`"v1"`}, ifRanges)
+}
@@ -1,11 +1,14 @@
 package business
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
 
 	"github.com/fatih/color"
 	"specify-cli/internal/config"
@@ -40,6 +43,14 @@ type InitHandler struct {
 	templateProvider types.TemplateProvider
 	authProvider     types.AuthProvider
 	uiRenderer       types.UIRenderer
+
+	// 当前初始化事务（由createProjectDirectory开启）
+	transaction *initTransaction
+	txMu        sync.Mutex
+
+	// 下载步骤解析出的发布版本和资源（用于结构化输出）
+	release *types.GitHubRelease
+	asset   *types.Asset
 }
 
 // NewInitHandler 创建新的初始化处理器实例
@@ -67,13 +78,21 @@ type InitHandler struct {
 // 使用示例：
 //
 //	handler := business.NewInitHandler()
-//	err := handler.Execute(initOptions)
+//	err := handler.Execute(ctx, initOptions)
 func NewInitHandler() *InitHandler {
+	return NewInitHandlerWithConfig(nil)
+}
+
+// NewInitHandlerWithConfig 使用指定网络配置创建初始化处理器
+//
+// networkConfig中的API地址、代理、CA证书和客户端证书用于模板下载，
+// 为nil时使用默认配置（代理取自HTTPS_PROXY/NO_PROXY环境变量）。
+func NewInitHandlerWithConfig(networkConfig *types.NetworkConfig) *InitHandler {
 	return &InitHandler{
 		toolChecker:      infrastructure.NewToolChecker(),
 		gitOps:           infrastructure.NewGitOperations(),
-		templateProvider: infrastructure.NewTemplateProvider(),
-		authProvider:     infrastructure.NewAuthProvider(),
+		templateProvider: infrastructure.NewTemplateProviderWithConfig(networkConfig, nil),
+		authProvider:     infrastructure.NewAuthProviderWithConfig(networkConfig),
 		uiRenderer:       ui.NewRenderer(),
 	}
 }
@@ -97,11 +116,18 @@ func NewInitHandler() *InitHandler {
 //  1. 创建步骤跟踪器并设置所有初始化步骤
 //  2. 显示初始化进度界面
 //  3. 按顺序执行9个核心步骤（验证、选择、检查、创建、下载、配置等）
-//  4. 在任何步骤失败时立即停止并显示错误信息
-//  5. 成功完成后显示完成状态和成功消息
+//  4. 在任何步骤失败时立即停止、回滚已做的修改并显示错误信息
+//  5. 成功完成后提交事务，显示完成状态和成功消息
+//
+// 事务保证：
+//   - 模板先提取到临时暂存目录，全部就绪后才移动到项目目录
+//   - 步骤失败或ctx被取消（Ctrl-C/SIGTERM）时恢复目录原状，
+//     包括删除本次新建的.git目录
 //
 // 参数：
 //
+//	ctx - 取消上下文，由CLI层绑定到SIGINT/SIGTERM；取消后正在进行的
+//	      HTTP请求、解压和git子进程都会尽快停止
 //	opts - 初始化选项配置，包含以下字段：
 //	  - ProjectName: 项目名称（当Here为false时必需）
 //	  - Here: 是否在当前目录初始化（默认false）
@@ -113,7 +139,8 @@ func NewInitHandler() *InitHandler {
 //
 // 返回值：
 //
-//	error - 如果初始化过程中任何步骤失败，返回相应的错误信息；成功时返回nil
+//	error - 如果初始化过程中任何步骤失败，返回相应的错误信息；成功时返回nil。
+//	        被取消时返回的错误满足errors.Is(err, context.Canceled)
 //
 // 错误处理：
 //   - 参数验证失败：返回参数相关错误
@@ -131,11 +158,11 @@ func NewInitHandler() *InitHandler {
 //	    ScriptType:  "sh",
 //	    Verbose:     true,
 //	}
-//	err := handler.Execute(opts)
+//	err := handler.Execute(cmd.Context(), opts)
 //	if err != nil {
 //	    log.Fatalf("项目初始化失败: %v", err)
 //	}
-func (h *InitHandler) Execute(opts types.InitOptions) error {
+func (h *InitHandler) Execute(ctx context.Context, opts types.InitOptions) error {
 	// 创建步骤跟踪器
 	tracker := ui.NewStepTracker("Project Initialization")
 
@@ -146,15 +173,33 @@ func (h *InitHandler) Execute(opts types.InitOptions) error {
 	tracker.Display()
 
 	// 执行初始化流程
-	if err := h.executeSteps(tracker, &opts); err != nil {
-		ui.ShowError(fmt.Sprintf("Initialization failed: %v", err))
+	if err := h.executeSteps(ctx, tracker, &opts); err != nil {
+		if ctx.Err() != nil {
+			fmt.Println()
+			h.rollback("Initialization interrupted")
+			err = fmt.Errorf("initialization interrupted: %w", ctx.Err())
+		} else {
+			ui.ShowError(fmt.Sprintf("Initialization failed: %v", err))
+			h.rollback("Initialization failed")
+		}
+		ui.EmitResult("init", tracker, h.resultData(opts), err)
 		return err
 	}
 
+	// 提交事务，清理暂存目录
+	if tx := h.currentTransaction(); tx != nil {
+		tx.commit()
+	}
+
 	// 显示完成状态
 	tracker.Display()
 	ui.ShowSuccess("Project initialization completed successfully!")
 
+	if ui.IsStructuredOutput() {
+		ui.EmitResult("init", tracker, h.resultData(opts), nil)
+		return nil
+	}
+
 	// 显示后续命令指导
 	h.showNextStepsGuidance(&opts)
 
@@ -171,11 +216,12 @@ func (h *InitHandler) Execute(opts types.InitOptions) error {
 //  2. select_ai - 选择或确认AI助手类型
 //  3. select_script - 选择或确认脚本类型（bash/PowerShell）
 //  4. check_tools - 检查系统中必需工具的可用性
-//  5. create_dir - 创建项目目录或确认当前目录
-//  6. download_template - 从GitHub下载项目模板
-//  7. init_git - 初始化Git仓库（如果不存在）
-//  8. configure - 配置项目设置和环境
-//  9. complete - 完成最终设置和清理工作
+//  5. create_dir - 确认项目目录并开启初始化事务
+//  6. download_template - 从GitHub下载项目模板到暂存目录
+//  7. install - 将暂存的项目文件移动到项目目录
+//  8. init_git - 初始化Git仓库（如果不存在）
+//  9. configure - 配置项目设置和环境
+//  10. complete - 完成最终设置和清理工作
 //
 // 参数：
 //
@@ -193,6 +239,7 @@ func (h *InitHandler) setupSteps(tracker *ui.StepTracker, opts types.InitOptions
 	tracker.AddStep("check_tools", "Check required tools")
 	tracker.AddStep("create_dir", "Create project directory")
 	tracker.AddStep("download_template", "Download template")
+	tracker.AddStep("install", "Install project files")
 	tracker.AddStep("init_git", "Initialize Git repository")
 	tracker.AddStep("configure", "Configure project")
 	tracker.AddStep("complete", "Finalize setup")
@@ -215,14 +262,16 @@ func (h *InitHandler) setupSteps(tracker *ui.StepTracker, opts types.InitOptions
 //  2. selectAIAssistant - 交互式选择或确认AI助手
 //  3. selectScriptType - 交互式选择或确认脚本类型
 //  4. checkTools - 验证系统工具依赖
-//  5. createProjectDirectory - 创建或确认工作目录
-//  6. downloadTemplate - 下载和提取项目模板
-//  7. initializeGit - 初始化版本控制
-//  8. configureProject - 应用项目特定配置
-//  9. finalizeSetup - 完成最终设置和清理
+//  5. createProjectDirectory - 确认工作目录并开启事务
+//  6. downloadTemplate - 下载和提取项目模板到暂存目录
+//  7. installProject - 将暂存文件移动到项目目录
+//  8. initializeGit - 初始化版本控制
+//  9. configureProject - 应用项目特定配置
+//  10. finalizeSetup - 完成最终设置和清理
 //
 // 参数：
 //
+//	ctx - 取消上下文，传递给涉及网络、文件系统和git的步骤
 //	tracker - 步骤跟踪器，用于更新和显示进度状态
 //	opts - 初始化选项配置，包含所有必要的设置参数
 //
@@ -234,7 +283,7 @@ func (h *InitHandler) setupSteps(tracker *ui.StepTracker, opts types.InitOptions
 //   - 每个步骤的错误都会被捕获并传播到上层
 //   - 错误信息包含失败步骤的上下文信息
 //   - 步骤跟踪器会显示失败状态和错误消息
-func (h *InitHandler) executeSteps(tracker *ui.StepTracker, opts *types.InitOptions) error {
+func (h *InitHandler) executeSteps(ctx context.Context, tracker *ui.StepTracker, opts *types.InitOptions) error {
 	// 步骤1: 验证选项
 	if err := h.validateOptions(tracker, opts); err != nil {
 		return err
@@ -261,22 +310,27 @@ func (h *InitHandler) executeSteps(tracker *ui.StepTracker, opts *types.InitOpti
 	}
 
 	// 步骤6: 下载模板
-	if err := h.downloadTemplate(tracker, *opts); err != nil {
+	if err := h.downloadTemplate(ctx, tracker, *opts); err != nil {
 		return err
 	}
 
-	// 步骤7: 初始化Git
-	if err := h.initializeGit(tracker, *opts); err != nil {
+	// 步骤7: 安装项目文件
+	if err := h.installProject(ctx, tracker, *opts); err != nil {
 		return err
 	}
 
-	// 步骤8: 配置项目
+	// 步骤8: 初始化Git
+	if err := h.initializeGit(ctx, tracker, *opts); err != nil {
+		return err
+	}
+
+	// 步骤9: 配置项目
 	if err := h.configureProject(tracker, *opts); err != nil {
 		return err
 	}
 
-	// 步骤9: 完成设置
-	if err := h.finalizeSetup(tracker, *opts); err != nil {
+	// 步骤10: 完成设置
+	if err := h.finalizeSetup(ctx, tracker, *opts); err != nil {
 		return err
 	}
 
@@ -346,6 +400,12 @@ func (h *InitHandler) validateOptions(tracker *ui.StepTracker, opts *types.InitO
 		}
 	}
 
+	// 验证冲突处理策略
+	if err := infrastructure.ValidateConflictResolution(opts.OnConflict); err != nil {
+		tracker.SetStepError("validate", err.Error())
+		return err
+	}
+
 	tracker.SetStepDone("validate", "Options validated successfully")
 	return nil
 }
@@ -561,18 +621,16 @@ func (h *InitHandler) checkTools(tracker *ui.StepTracker, opts types.InitOptions
 // 目录操作流程：
 //  1. 检查用户的目录选择偏好（新建 vs 当前）
 //  2. 如果需要新建目录：
-//     - 使用项目名称创建目录
-//     - 设置适当的目录权限（0755）
-//     - 切换到新创建的目录
+//     - 检查目录是否已存在（未指定--force时拒绝覆盖）
+//     - 目录本身在安装步骤中才会创建，失败时不留下空目录
 //  3. 如果使用当前目录：
-//     - 验证当前目录的可写性
+//     - 检查目录是否为空，必要时请求用户确认
 //     - 获取并显示当前目录信息
-//  4. 更新步骤跟踪器显示目录信息
+//  4. 开启初始化事务，创建模板暂存目录
+//  5. 更新步骤跟踪器显示目录信息
 //
-// 权限设置：
-//   - 新创建的目录使用0755权限（rwxr-xr-x）
-//   - 确保目录对所有者可读写执行
-//   - 确保组和其他用户可读执行
+// 注意：
+//   - 该步骤不再切换工作目录，后续步骤均使用事务中的绝对路径
 //
 // 参数：
 //
@@ -584,13 +642,11 @@ func (h *InitHandler) checkTools(tracker *ui.StepTracker, opts types.InitOptions
 //	error - 如果目录创建或切换失败，返回详细错误信息；成功时返回nil
 //
 // 副作用：
-//   - 可能在文件系统中创建新目录
-//   - 改变当前工作目录（当创建新目录时）
+//   - 在系统临时目录中创建暂存和备份目录
 //   - 更新步骤跟踪器显示目录路径
 //
 // 错误处理：
-//   - 目录创建失败：权限不足、磁盘空间不足等
-//   - 目录切换失败：目录不存在、权限问题等
+//   - 暂存目录创建失败：权限不足、磁盘空间不足等
 //   - 路径解析错误：无效的项目名称或路径字符
 //   - 文件系统错误：I/O错误、网络驱动器问题等
 func (h *InitHandler) createProjectDirectory(tracker *ui.StepTracker, opts types.InitOptions) error {
@@ -605,17 +661,12 @@ func (h *InitHandler) createProjectDirectory(tracker *ui.StepTracker, opts types
 			}
 		}
 
-		if err := os.MkdirAll(opts.ProjectName, 0755); err != nil {
-			tracker.SetStepError("create_dir", fmt.Sprintf("Failed to create directory: %v", err))
-			return fmt.Errorf("failed to create project directory: %w", err)
-		}
-
-		if err := os.Chdir(opts.ProjectName); err != nil {
-			tracker.SetStepError("create_dir", fmt.Sprintf("Failed to change directory: %v", err))
-			return fmt.Errorf("failed to change to project directory: %w", err)
+		if err := h.beginTransaction(opts.ProjectName); err != nil {
+			tracker.SetStepError("create_dir", fmt.Sprintf("Failed to prepare directory: %v", err))
+			return err
 		}
 
-		tracker.SetStepDone("create_dir", fmt.Sprintf("Created and entered directory: %s", opts.ProjectName))
+		tracker.SetStepDone("create_dir", fmt.Sprintf("Prepared project directory: %s", opts.ProjectName))
 	} else {
 		// 使用当前目录，检查是否为空
 		cwd, _ := os.Getwd()
@@ -645,11 +696,76 @@ func (h *InitHandler) createProjectDirectory(tracker *ui.StepTracker, opts types
 		} else {
 			tracker.SetStepDone("create_dir", fmt.Sprintf("Using current directory: %s", filepath.Base(cwd)))
 		}
+
+		if err := h.beginTransaction(cwd); err != nil {
+			tracker.SetStepError("create_dir", fmt.Sprintf("Failed to prepare directory: %v", err))
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resultData 汇总结构化输出中的init结果数据
+func (h *InitHandler) resultData(opts types.InitOptions) map[string]interface{} {
+	data := map[string]interface{}{
+		"project_name": opts.ProjectName,
+		"here":         opts.Here,
+		"ai_assistant": opts.AIAssistant,
+		"script_type":  opts.ScriptType,
+	}
+	if tx := h.currentTransaction(); tx != nil {
+		data["project_path"] = tx.targetDir
+	}
+	addReleaseData(data, h.release, h.asset)
+	return data
+}
+
+// beginTransaction 为项目目录开启初始化事务
+func (h *InitHandler) beginTransaction(targetDir string) error {
+	tx, err := newInitTransaction(targetDir)
+	if err != nil {
+		return fmt.Errorf("failed to start initialization: %w", err)
 	}
 
+	h.txMu.Lock()
+	h.transaction = tx
+	h.txMu.Unlock()
 	return nil
 }
 
+// currentTransaction 获取当前初始化事务
+func (h *InitHandler) currentTransaction() *initTransaction {
+	h.txMu.Lock()
+	defer h.txMu.Unlock()
+	return h.transaction
+}
+
+// projectDir 获取项目目录的绝对路径
+func (h *InitHandler) projectDir() string {
+	if tx := h.currentTransaction(); tx != nil {
+		return tx.targetDir
+	}
+	cwd, _ := os.Getwd()
+	return cwd
+}
+
+// rollback 回滚当前初始化事务并提示用户
+func (h *InitHandler) rollback(reason string) {
+	tx := h.currentTransaction()
+	if tx == nil {
+		return
+	}
+
+	if err := tx.rollback(); err != nil {
+		ui.ShowError(fmt.Sprintf("%s; rollback incomplete: %v", reason, err))
+		return
+	}
+	if tx.wasInstalled() {
+		ui.ShowWarning(fmt.Sprintf("%s; changes to %s have been rolled back", reason, tx.targetDir))
+	}
+}
+
 // downloadTemplate 从GitHub下载并提取项目模板
 //
 // 该函数负责从远程GitHub仓库下载适合所选AI助手的项目模板，
@@ -671,7 +787,7 @@ func (h *InitHandler) createProjectDirectory(tracker *ui.StepTracker, opts types
 //  1. 构建下载选项配置
 //  2. 调用模板提供者的下载方法
 //  3. 验证下载的模板完整性
-//  4. 提取模板文件到项目目录
+//  4. 提取模板文件到事务的暂存目录
 //  5. 清理临时下载文件
 //  6. 更新步骤跟踪器显示结果
 //
@@ -702,26 +818,81 @@ func (h *InitHandler) createProjectDirectory(tracker *ui.StepTracker, opts types
 //   - 下载错误：文件损坏、传输中断等
 //   - 文件系统错误：磁盘空间不足、权限问题等
 //   - 模板格式错误：无效的模板结构或配置
-func (h *InitHandler) downloadTemplate(tracker *ui.StepTracker, opts types.InitOptions) error {
+func (h *InitHandler) downloadTemplate(ctx context.Context, tracker *ui.StepTracker, opts types.InitOptions) error {
 	tracker.SetStepRunning("download_template", "Downloading project template")
 
 	downloadOpts := types.DownloadOptions{
 		AIAssistant:  opts.AIAssistant,
-		DownloadDir:  ".",
+		DownloadDir:  h.currentTransaction().stagingDir,
 		ScriptType:   opts.ScriptType,
 		Verbose:      opts.Verbose,
 		ShowProgress: true,
 		GitHubToken:  opts.GitHubToken,
+		GitLabToken:  opts.GitLabToken,
+		GiteaToken:   opts.GiteaToken,
 		SkipTLS:      opts.SkipTLS, // 传递SkipTLS标志到下载选项
+		OnConflict:   opts.OnConflict,
+		TemplateRepo: opts.TemplateRepo,
+		TemplateGit:  opts.TemplateGit,
+		SigningKey:   opts.SigningKey,
+		OnResolved: func(release *types.GitHubRelease, asset *types.Asset) {
+			h.release, h.asset = release, asset
+		},
+	}
+	limitRate, err := infrastructure.ParseRate(opts.LimitRate)
+	if err != nil {
+		tracker.SetStepError("download_template", err.Error())
+		return err
+	}
+	downloadOpts.LimitRate = limitRate
+	if downloadOpts.Mirrors, err = infrastructure.ParseMirrors(opts.Mirrors); err != nil {
+		tracker.SetStepError("download_template", err.Error())
+		return err
+	}
+	if opts.Checksum != "" {
+		checksumType, checksum, err := infrastructure.ParseChecksum(opts.Checksum)
+		if err != nil {
+			tracker.SetStepError("download_template", err.Error())
+			return err
+		}
+		downloadOpts.ChecksumType, downloadOpts.Checksum = checksumType, checksum
 	}
 
-	templatePath, err := h.templateProvider.Download(downloadOpts)
+	templatePath, err := h.templateProvider.Download(ctx, downloadOpts)
 	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			tracker.SetStepError("download_template", "Download cancelled")
+			return err
+		}
 		tracker.SetStepError("download_template", fmt.Sprintf("Download failed: %v", err))
 		return fmt.Errorf("failed to download template: %w", err)
 	}
 
-	tracker.SetStepDone("download_template", fmt.Sprintf("Template downloaded to: %s", templatePath))
+	tracker.SetStepDone("download_template", fmt.Sprintf("Template staged in: %s", templatePath))
+	return nil
+}
+
+// installProject 将暂存目录中的项目文件移动到项目目录
+//
+// 新项目目录会通过一次重命名整体移动到位；已有目录（--here或--force）
+// 则逐个文件安装，按--on-conflict策略处理冲突，并在覆盖前备份原文件，
+// 以便后续步骤失败时回滚。
+func (h *InitHandler) installProject(ctx context.Context, tracker *ui.StepTracker, opts types.InitOptions) error {
+	// 安装开始后不再中断，避免目标目录停留在半安装状态
+	if err := ctx.Err(); err != nil {
+		tracker.SetStepError("install", "Installation cancelled")
+		return err
+	}
+
+	tracker.SetStepRunning("install", "Moving project files into place")
+
+	tx := h.currentTransaction()
+	if err := tx.install(opts.OnConflict, opts.Verbose); err != nil {
+		tracker.SetStepError("install", fmt.Sprintf("Install failed: %v", err))
+		return fmt.Errorf("failed to install project files: %w", err)
+	}
+
+	tracker.SetStepDone("install", fmt.Sprintf("Project files installed in: %s", tx.targetDir))
 	return nil
 }
 
@@ -781,7 +952,7 @@ func (h *InitHandler) downloadTemplate(tracker *ui.StepTracker, opts types.InitO
 //   - 当前目录已经是Git仓库的一部分
 //   - 父目录中存在.git目录（子模块场景）
 //   - 用户明确禁用了Git初始化
-func (h *InitHandler) initializeGit(tracker *ui.StepTracker, opts types.InitOptions) error {
+func (h *InitHandler) initializeGit(ctx context.Context, tracker *ui.StepTracker, opts types.InitOptions) error {
 	// 如果使用--no-git标志，跳过Git初始化
 	if opts.NoGit {
 		tracker.SetStepSkipped("init_git", "Git initialization skipped (--no-git flag)")
@@ -790,13 +961,13 @@ func (h *InitHandler) initializeGit(tracker *ui.StepTracker, opts types.InitOpti
 
 	tracker.SetStepRunning("init_git", "Initializing Git repository")
 
-	cwd, _ := os.Getwd()
-	if h.gitOps.IsRepo(cwd) {
+	cwd := h.projectDir()
+	if h.gitOps.IsRepo(ctx, cwd) {
 		tracker.SetStepSkipped("init_git", "Git repository already exists")
 		return nil
 	}
 
-	created, err := h.gitOps.InitRepo(cwd, !opts.Verbose)
+	created, err := h.gitOps.InitRepo(ctx, cwd, !opts.Verbose)
 	if err != nil {
 		tracker.SetStepError("init_git", fmt.Sprintf("Git initialization failed: %v", err))
 		return fmt.Errorf("failed to initialize Git repository: %w", err)
@@ -940,13 +1111,18 @@ func (h *InitHandler) configureProject(tracker *ui.StepTracker, opts types.InitO
 //   - 提供详细的错误信息和修复建议
 //   - 确保即使部分步骤失败，项目仍然可用
 //   - 记录所有问题以便后续诊断
-func (h *InitHandler) finalizeSetup(tracker *ui.StepTracker, opts types.InitOptions) error {
+func (h *InitHandler) finalizeSetup(ctx context.Context, tracker *ui.StepTracker, opts types.InitOptions) error {
 	tracker.SetStepRunning("complete", "Finalizing project setup")
 
 	// 创建初始提交
-	cwd, _ := os.Getwd()
-	if h.gitOps.IsRepo(cwd) {
-		if err := h.gitOps.AddAndCommit(cwd, "Initial commit: Project setup with Specify CLI"); err != nil {
+	cwd := h.projectDir()
+	if h.gitOps.IsRepo(ctx, cwd) {
+		if err := h.gitOps.AddAndCommit(ctx, cwd, "Initial commit: Project setup with Specify CLI"); err != nil {
+			// 被取消时git子进程已被终止，交由上层回滚
+			if ctx.Err() != nil {
+				tracker.SetStepError("complete", "Initial commit cancelled")
+				return ctx.Err()
+			}
 			ui.ShowWarning(fmt.Sprintf("Failed to create initial commit: %v", err))
 		}
 	}
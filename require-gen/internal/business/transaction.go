@@ -0,0 +1,129 @@
+package business
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"specify-cli/internal/infrastructure"
+)
+
+// stagingTTL 暂存目录的最长保留时间，进程异常退出后由临时文件管理器兜底清理
+const stagingTTL = 2 * time.Hour
+
+// initTransaction 事务化的项目初始化
+//
+// initTransaction 让init命令具备"要么全部完成，要么不留痕迹"的语义：
+//   - 模板先下载并提取到临时暂存目录，不触碰目标目录
+//   - 暂存完成后由StagedInstaller一次性移动到目标目录
+//   - 任何后续步骤失败或用户按下Ctrl-C时，调用rollback恢复目标目录原状，
+//     包括删除本次新建的.git目录
+//
+// 整个流程不再调用os.Chdir，所有步骤都基于targetDir绝对路径操作。
+type initTransaction struct {
+	targetDir  string // 项目目录（绝对路径）
+	stagingDir string // 模板暂存目录
+	backupDir  string // 被覆盖文件的备份目录
+	hadGit     bool   // 初始化前目标目录是否已有.git
+	installer  *infrastructure.StagedInstaller
+	installed  bool // 暂存文件是否已移动到目标目录
+	finished   bool // 事务是否已提交或回滚
+	mu         sync.Mutex
+}
+
+// newInitTransaction 为目标目录开启初始化事务，创建暂存和备份目录
+func newInitTransaction(targetDir string) (*initTransaction, error) {
+	absTarget, err := filepath.Abs(targetDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve project directory: %w", err)
+	}
+
+	sysOps := infrastructure.NewSystemOperations().(*infrastructure.SystemOperations)
+
+	stagingDir, err := sysOps.CreateTempDirectoryWithTTL("specify-init-*", stagingTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create staging directory: %w", err)
+	}
+
+	backupDir, err := sysOps.CreateTempDirectoryWithTTL("specify-backup-*", stagingTTL)
+	if err != nil {
+		os.RemoveAll(stagingDir)
+		return nil, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	_, gitErr := os.Stat(filepath.Join(absTarget, ".git"))
+
+	return &initTransaction{
+		targetDir:  absTarget,
+		stagingDir: stagingDir,
+		backupDir:  backupDir,
+		hadGit:     gitErr == nil,
+		installer:  infrastructure.NewStagedInstaller(backupDir),
+	}, nil
+}
+
+// install 将暂存目录中的项目文件移动到目标目录
+func (t *initTransaction) install(onConflict string, verbose bool) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.finished {
+		return fmt.Errorf("initialization was aborted")
+	}
+
+	// 先标记已安装，保证安装中途失败时回滚也会处理已移动的部分文件
+	t.installed = true
+	return t.installer.Install(t.stagingDir, t.targetDir, onConflict, verbose)
+}
+
+// wasInstalled 暂存文件是否已（至少部分）移动到目标目录
+func (t *initTransaction) wasInstalled() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.installed
+}
+
+// commit 提交事务，清理暂存和备份目录
+func (t *initTransaction) commit() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.finished {
+		return
+	}
+	t.finished = true
+	t.cleanup()
+}
+
+// rollback 回滚事务，恢复目标目录的原始状态
+func (t *initTransaction) rollback() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.finished {
+		return nil
+	}
+	t.finished = true
+	defer t.cleanup()
+
+	if !t.installed {
+		return nil
+	}
+
+	// 删除本次初始化新建的Git仓库
+	if !t.hadGit && !t.installer.CreatedTarget() {
+		if err := os.RemoveAll(filepath.Join(t.targetDir, ".git")); err != nil {
+			return fmt.Errorf("failed to remove .git: %w", err)
+		}
+	}
+
+	return t.installer.Rollback()
+}
+
+// cleanup 删除暂存和备份目录
+func (t *initTransaction) cleanup() {
+	os.RemoveAll(t.stagingDir)
+	os.RemoveAll(t.backupDir)
+}
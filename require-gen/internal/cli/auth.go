@@ -0,0 +1,352 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+	"specify-cli/internal/config"
+	"specify-cli/internal/infrastructure"
+	"specify-cli/internal/types"
+	"specify-cli/internal/ui"
+)
+
+// authCmd auth子命令
+var authCmd = &cobra.Command{
+	Use:   "auth",
+	Short: "Show and manage GitHub authentication",
+	Long: `Log in to GitHub and show which token Specify uses for API requests and template downloads.
+
+Tokens are looked up in this order (highest first):
+  --token / SPECIFY_TOKEN > GH_TOKEN > GITHUB_TOKEN > specify auth login >
+  GitHub CLI (gh auth token) > git credential helper > ~/.netrc
+
+Examples:
+  gh auth token | specify auth login       # Store a token read from stdin
+  specify auth login --api-url https://ghe.example.com/api/v3
+  specify auth status                      # Show the token source and user
+  specify auth token                       # Print the token in use
+  specify auth logout                      # Remove the stored token`,
+}
+
+// authLoginCmd auth login子命令
+var authLoginCmd = &cobra.Command{
+	Use:   "login",
+	Short: "Validate a token read from stdin and store it for the API host",
+	Long: `Read a GitHub token from stdin (or prompt for it on a terminal), check it against
+the GitHub API and store it for the API host.
+
+The token is saved in the system keyring when one is available (macOS Keychain,
+Windows Credential Manager, Secret Service on Linux). Otherwise, or with
+--insecure-storage, it is written to the user config directory, readable only by you.`,
+	Args: cobra.NoArgs,
+	RunE: runAuthLogin,
+}
+
+// authLogoutCmd auth logout子命令
+var authLogoutCmd = &cobra.Command{
+	Use:   "logout",
+	Short: "Remove the stored token for the API host",
+	Args:  cobra.NoArgs,
+	RunE:  runAuthLogout,
+}
+
+// authTokenCmd auth token子命令
+var authTokenCmd = &cobra.Command{
+	Use:   "token",
+	Short: "Print the GitHub token Specify would use",
+	Args:  cobra.NoArgs,
+	RunE:  runAuthToken,
+}
+
+// insecureStorage auth login的标志：使用文件而不是系统密钥环保存令牌
+var insecureStorage bool
+
+// authStatusCmd auth status子命令
+var authStatusCmd = &cobra.Command{
+	Use:   "status",
+	Short: "Show where the GitHub token comes from and whether it is valid",
+	Args:  cobra.NoArgs,
+	RunE:  runAuthStatus,
+}
+
+func init() {
+	authLoginCmd.Flags().BoolVar(&insecureStorage, "insecure-storage", false, "Store the token in a file instead of the system keyring")
+
+	for _, cmd := range []*cobra.Command{authLoginCmd, authLogoutCmd, authStatusCmd, authTokenCmd} {
+		addNetworkFlags(cmd)
+		documentEnvBindings(cmd)
+		authCmd.AddCommand(cmd)
+	}
+}
+
+// newAuthProvider 创建与模板下载使用相同令牌和API地址的认证提供者
+//
+// 返回的来源描述中，SPECIFY_TOKEN显示为环境变量而不是CLI参数。
+func newAuthProvider(cmd *cobra.Command) (types.AuthProvider, *types.NetworkConfig, string, error) {
+	if err := applySettings(cmd); err != nil {
+		return nil, nil, "", err
+	}
+	networkConfig, err := resolveNetworkConfig()
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	auth := infrastructure.NewAuthProviderWithConfig(networkConfig)
+	resolved := settings.Resolve(config.KeyGitHubToken)
+	if resolved.Value == "" {
+		return auth, networkConfig, auth.GetTokenSource(), nil
+	}
+
+	auth.SetCLIToken(resolved.Value)
+	source := auth.GetTokenSource()
+	if resolved.Source == config.SourceEnv {
+		source = resolved.Origin + " environment variable"
+	}
+	return auth, networkConfig, source, nil
+}
+
+// maskToken 隐藏令牌中间部分，只保留便于辨认的前缀和末尾4个字符
+func maskToken(token string) string {
+	if len(token) < 12 {
+		return "****"
+	}
+	prefix := token[:4]
+	if i := strings.Index(token, "_"); i > 0 && i < 12 {
+		prefix = token[:i+1]
+	}
+	return prefix + "****" + token[len(token)-4:]
+}
+
+// runAuthStatus 执行auth status命令
+func runAuthStatus(cmd *cobra.Command, args []string) error {
+	auth, networkConfig, source, err := newAuthProvider(cmd)
+	if err != nil {
+		return err
+	}
+
+	host := infrastructure.APIHost(networkConfig)
+	token := auth.GetToken()
+	status := map[string]interface{}{
+		"host":          host,
+		"authenticated": token != "",
+		"source":        source,
+	}
+
+	var details *types.TokenDetails
+	var validateErr error
+	if token != "" {
+		status["token"] = maskToken(token)
+		details, validateErr = auth.GetTokenDetails()
+		if details != nil {
+			status["details"] = details
+		}
+		status["valid"] = validateErr == nil
+		if validateErr != nil {
+			status["error"] = validateErr.Error()
+		}
+	}
+
+	if ui.IsStructuredOutput() {
+		ui.EmitResult("auth status", nil, status, nil)
+		return nil
+	}
+
+	fmt.Println("=== GitHub Authentication ===")
+	fmt.Printf("  %-15s: %s\n", "Host", host)
+	if token == "" {
+		fmt.Printf("  %-15s: no (set GH_TOKEN, run 'gh auth login', or configure a git credential helper)\n", "Authenticated")
+		return nil
+	}
+
+	if source == infrastructure.TokenSourceStored.String() {
+		if _, store, err := infrastructure.StoredToken(host); err == nil {
+			source += " (" + store.Name() + ")"
+		}
+	}
+	fmt.Printf("  %-15s: %s\n", "Token Source", source)
+	fmt.Printf("  %-15s: %s\n", "Token", maskToken(token))
+	if validateErr != nil {
+		fmt.Printf("  %-15s: invalid (%v)\n", "Status", validateErr)
+		if details != nil && details.Expired() {
+			fmt.Printf("  %-15s: %s\n", "Expired", details.ExpiresAt.Local().Format("2006-01-02 15:04"))
+		}
+		return nil
+	}
+	printTokenDetails(details)
+	return nil
+}
+
+// printTokenDetails 显示令牌类型、所属用户、权限和过期时间
+func printTokenDetails(details *types.TokenDetails) {
+	fmt.Printf("  %-15s: %s\n", "Token Type", details.Type)
+	if details.Login != "" {
+		fmt.Printf("  %-15s: %s\n", "Logged in as", details.Login)
+	}
+	if len(details.Scopes) > 0 {
+		fmt.Printf("  %-15s: %s\n", "Scopes", strings.Join(details.Scopes, ", "))
+	}
+	if len(details.Permissions) > 0 {
+		var granted []string
+		for name, ok := range details.Permissions {
+			if ok {
+				granted = append(granted, name)
+			}
+		}
+		sort.Strings(granted)
+		fmt.Printf("  %-15s: %s\n", "Permissions", strings.Join(granted, ", "))
+	}
+	if details.ExpiresAt == nil {
+		return
+	}
+	expires := details.ExpiresAt.Local().Format("2006-01-02 15:04")
+	if remaining := time.Until(*details.ExpiresAt); remaining < 7*24*time.Hour {
+		expires += fmt.Sprintf(" (in %s)", remaining.Round(time.Minute))
+	}
+	fmt.Printf("  %-15s: %s\n", "Expires", expires)
+}
+
+// readToken 从标准输入读取令牌；标准输入为终端时提示输入且不回显
+func readToken() (string, error) {
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		fmt.Fprint(os.Stderr, "Paste your GitHub token: ")
+		data, err := term.ReadPassword(fd)
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return "", fmt.Errorf("failed to read token: %w", err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+
+	data, err := io.ReadAll(io.LimitReader(os.Stdin, 64*1024))
+	if err != nil {
+		return "", fmt.Errorf("failed to read token from stdin: %w", err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// runAuthLogin 执行auth login命令
+func runAuthLogin(cmd *cobra.Command, args []string) error {
+	if err := applySettings(cmd); err != nil {
+		return err
+	}
+	networkConfig, err := resolveNetworkConfig()
+	if err != nil {
+		return err
+	}
+	host := infrastructure.APIHost(networkConfig)
+
+	token, err := readToken()
+	if err != nil {
+		return err
+	}
+	if token == "" {
+		return fmt.Errorf("no token provided on stdin")
+	}
+
+	// 验证令牌并获取类型、用户名、权限和过期时间
+	auth := infrastructure.NewAuthProviderWithConfig(networkConfig)
+	auth.SetCLIToken(token)
+	details, err := auth.GetTokenDetails()
+	if err != nil {
+		return fmt.Errorf("token rejected by %s: %w", host, err)
+	}
+
+	store, err := infrastructure.NewTokenStore(insecureStorage)
+	if err != nil {
+		return err
+	}
+	if err := store.Set(host, token); err != nil {
+		if insecureStorage {
+			return fmt.Errorf("failed to store token: %w", err)
+		}
+		// 密钥环写入失败（如被锁定）时退回到文件存储
+		infrastructure.DefaultLogger().Warn("failed to store token in keyring, using file storage", "error", err)
+		if store, err = infrastructure.NewFileTokenStore(); err != nil {
+			return err
+		}
+		if err := store.Set(host, token); err != nil {
+			return fmt.Errorf("failed to store token: %w", err)
+		}
+	}
+
+	if ui.IsStructuredOutput() {
+		ui.EmitResult("auth login", nil, map[string]interface{}{
+			"host":    host,
+			"details": details,
+			"storage": store.Name(),
+		}, nil)
+		return nil
+	}
+
+	ui.ShowSuccess(fmt.Sprintf("Logged in to %s", host))
+	printTokenDetails(details)
+	fmt.Printf("  %-15s: %s\n", "Stored in", store.Name())
+	return nil
+}
+
+// runAuthLogout 执行auth logout命令
+func runAuthLogout(cmd *cobra.Command, args []string) error {
+	if err := applySettings(cmd); err != nil {
+		return err
+	}
+	networkConfig, err := resolveNetworkConfig()
+	if err != nil {
+		return err
+	}
+	host := infrastructure.APIHost(networkConfig)
+
+	stores, err := infrastructure.DeleteStoredToken(host)
+	if errors.Is(err, infrastructure.ErrTokenNotStored) {
+		return fmt.Errorf("not logged in to %s", host)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to remove stored token: %w", err)
+	}
+
+	names := make([]string, 0, len(stores))
+	for _, store := range stores {
+		names = append(names, store.Name())
+	}
+
+	if ui.IsStructuredOutput() {
+		ui.EmitResult("auth logout", nil, map[string]interface{}{"host": host, "removed_from": names}, nil)
+		return nil
+	}
+
+	ui.ShowSuccess(fmt.Sprintf("Logged out of %s", host))
+	// 环境变量等其他来源的令牌仍然有效
+	auth := infrastructure.NewAuthProviderWithConfig(networkConfig)
+	if auth.GetToken() != "" {
+		fmt.Printf("  Note: a token from %s is still used\n", auth.GetTokenSource())
+	}
+	return nil
+}
+
+// runAuthToken 执行auth token命令
+func runAuthToken(cmd *cobra.Command, args []string) error {
+	auth, networkConfig, source, err := newAuthProvider(cmd)
+	if err != nil {
+		return err
+	}
+
+	host := infrastructure.APIHost(networkConfig)
+	token := auth.GetToken()
+	if token == "" {
+		return fmt.Errorf("no token found for %s", host)
+	}
+
+	if ui.IsStructuredOutput() {
+		ui.EmitResult("auth token", nil, map[string]interface{}{"host": host, "token": token, "source": source}, nil)
+		return nil
+	}
+	fmt.Println(token)
+	return nil
+}
@@ -68,24 +68,37 @@ func runCheck(cmd *cobra.Command, args []string) {
 	// 创建工具检查器
 	toolChecker := infrastructure.NewToolChecker()
 
-	// 检查Git
-	tracker.AddStep("git", "Git version control")
-	gitAvailable := toolChecker.CheckTool("git", &types.StepTracker{
-		Title: "Git Check",
-		Steps: make(map[string]*types.Step),
-	})
-	
-	if gitAvailable {
-		tracker.SetStepDone("git", "available")
-		if showVersions {
-			if version, err := toolChecker.GetToolVersion("git"); err == nil {
-				tracker.SetStepDone("git", fmt.Sprintf("available (v%s)", version))
+	// 结构化输出的工具检查结果
+	tools := make(map[string]interface{})
+
+	// checkTool 检查单个工具并更新跟踪器和结果
+	// 结构化输出模式下总是获取版本号，便于调用方直接使用
+	checkTool := func(key, title string) bool {
+		available := toolChecker.CheckTool(key, &types.StepTracker{
+			Title: title,
+			Steps: make(map[string]*types.Step),
+		})
+
+		result := map[string]interface{}{"available": available}
+		if available {
+			tracker.SetStepDone(key, "available")
+			if showVersions || ui.IsStructuredOutput() {
+				if version, err := toolChecker.GetToolVersion(key); err == nil {
+					tracker.SetStepDone(key, fmt.Sprintf("available (v%s)", version))
+					result["version"] = version
+				}
 			}
+		} else {
+			tracker.SetStepError(key, "not found")
 		}
-	} else {
-		tracker.SetStepError("git", "not found")
+		tools[key] = result
+		return available
 	}
 
+	// 检查Git
+	tracker.AddStep("git", "Git version control")
+	gitAvailable := checkTool("git", "Git Check")
+
 	// 检查AI助手工具
 	agents := config.GetAllAgents()
 	agentResults := make(map[string]bool)
@@ -98,62 +111,35 @@ func runCheck(cmd *cobra.Command, args []string) {
 		if !exists || !agentInfo.RequiresCLI {
 			tracker.SetStepSkipped(agentKey, "no CLI required")
 			agentResults[agentKey] = true // 不需要CLI的视为可用
+			tools[agentKey] = map[string]interface{}{"available": true, "requires_cli": false}
 			continue
 		}
 		
 		// 检查需要CLI的AI助手
-		available := toolChecker.CheckTool(agentKey, &types.StepTracker{
-			Title: fmt.Sprintf("%s Check", agentName),
-			Steps: make(map[string]*types.Step),
-		})
-		
-		agentResults[agentKey] = available
-		
-		if available {
-			tracker.SetStepDone(agentKey, "available")
-			if showVersions {
-				if version, err := toolChecker.GetToolVersion(agentKey); err == nil {
-					tracker.SetStepDone(agentKey, fmt.Sprintf("available (v%s)", version))
-				}
-			}
-		} else {
-			tracker.SetStepError(agentKey, "not found")
-		}
+		agentResults[agentKey] = checkTool(agentKey, fmt.Sprintf("%s Check", agentName))
 	}
 
 	// 检查VS Code变体
 	tracker.AddStep("code", "Visual Studio Code")
-	codeAvailable := toolChecker.CheckTool("code", &types.StepTracker{
-		Title: "VS Code Check",
-		Steps: make(map[string]*types.Step),
-	})
-	
-	if codeAvailable {
-		tracker.SetStepDone("code", "available")
-		if showVersions {
-			if version, err := toolChecker.GetToolVersion("code"); err == nil {
-				tracker.SetStepDone("code", fmt.Sprintf("available (v%s)", version))
-			}
-		}
-	} else {
-		tracker.SetStepError("code", "not found")
-	}
+	checkTool("code", "VS Code Check")
 
 	tracker.AddStep("code-insiders", "Visual Studio Code Insiders")
-	codeInsidersAvailable := toolChecker.CheckTool("code-insiders", &types.StepTracker{
-		Title: "VS Code Insiders Check",
-		Steps: make(map[string]*types.Step),
-	})
-	
-	if codeInsidersAvailable {
-		tracker.SetStepDone("code-insiders", "available")
-		if showVersions {
-			if version, err := toolChecker.GetToolVersion("code-insiders"); err == nil {
-				tracker.SetStepDone("code-insiders", fmt.Sprintf("available (v%s)", version))
+	checkTool("code-insiders", "VS Code Insiders Check")
+
+	// 结构化输出：汇总结果后直接返回
+	if ui.IsStructuredOutput() {
+		data := map[string]interface{}{"tools": tools}
+		if showDetails {
+			data["system"] = collectSystemInfo(toolChecker)
+			rateLimit, authenticated, err := checkRateLimit(cmd)
+			githubAPI := map[string]interface{}{"authenticated": authenticated, "rate_limit": rateLimit}
+			if err != nil {
+				githubAPI["error"] = err.Error()
 			}
+			data["github_api"] = githubAPI
 		}
-	} else {
-		tracker.SetStepError("code-insiders", "not found")
+		ui.EmitResult("check", tracker, data, nil)
+		return
 	}
 
 	// 显示检查结果
@@ -199,5 +185,51 @@ func runCheck(cmd *cobra.Command, args []string) {
 		fmt.Printf("  %-15s: %s\n", "Go Version", runtime.Version())
 		fmt.Printf("  %-15s: %s/%s\n", "OS/Arch", runtime.GOOS, runtime.GOARCH)
 		fmt.Printf("  %-15s: %s\n", "Compiler", runtime.Compiler)
+
+		fmt.Println()
+		fmt.Println("=== GitHub API ===")
+		rateLimit, authenticated, err := checkRateLimit(cmd)
+		if err != nil {
+			fmt.Printf("  %-15s: unavailable (%v)\n", "Rate Limit", err)
+		} else {
+			fmt.Printf("  %-15s: %d/%d requests remaining (resets at %s)\n", "Rate Limit",
+				rateLimit.Remaining, rateLimit.Limit, rateLimit.Reset.Local().Format("15:04:05"))
+		}
+		if authenticated {
+			fmt.Printf("  %-15s: yes\n", "Authenticated")
+		} else {
+			fmt.Printf("  %-15s: no (set GH_TOKEN or SPECIFY_TOKEN for 5000 requests/hour)\n", "Authenticated")
+		}
+	}
+}
+
+// checkRateLimit 查询模板下载使用的GitHub API的剩余配额
+//
+// 令牌的选择与下载模板时一致：--token/SPECIFY_TOKEN优先，其次是GH_TOKEN、GITHUB_TOKEN、
+// gh auth token、git凭据助手和~/.netrc。
+func checkRateLimit(cmd *cobra.Command) (*types.RateLimit, bool, error) {
+	networkConfig, err := resolveNetworkConfig()
+	if err != nil {
+		return nil, settings.Value(config.KeyGitHubToken) != "", err
+	}
+
+	token := settings.Value(config.KeyGitHubToken)
+	if token == "" {
+		token = infrastructure.NewAuthProviderWithConfig(networkConfig).GetToken()
 	}
-}
\ No newline at end of file
+	rateLimit, err := infrastructure.FetchRateLimit(cmd.Context(), networkConfig, token)
+	return rateLimit, token != "", err
+}
+
+// collectSystemInfo 汇总结构化输出中的系统信息
+func collectSystemInfo(toolChecker types.ToolChecker) map[string]interface{} {
+	info := make(map[string]interface{})
+	for key, value := range toolChecker.GetSystemInfo() {
+		info[key] = value
+	}
+	info["go_version"] = runtime.Version()
+	info["os"] = runtime.GOOS
+	info["arch"] = runtime.GOARCH
+	info["compiler"] = runtime.Compiler
+	return info
+}
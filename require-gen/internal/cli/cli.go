@@ -1,10 +1,15 @@
 package cli
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/spf13/cobra"
+	"specify-cli/internal/infrastructure"
 	"specify-cli/internal/ui"
 )
 
@@ -12,6 +17,12 @@ var (
 	// 全局标志
 	verbose bool
 	debug   bool
+	output  string
+
+	// 日志标志
+	logFile   string
+	logFormat string
+	logCloser io.Closer
 )
 
 // rootCmd 根命令
@@ -21,6 +32,18 @@ var rootCmd = &cobra.Command{
 	Long: `A powerful toolkit for spec-driven development with AI assistants.
 Supports multiple AI platforms and script types for cross-platform development.`,
 	Version: "1.0.0",
+	// 在任何子命令运行前应用 --output、日志设置和配置文件
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		format, err := ui.ParseOutputFormat(output)
+		if err != nil {
+			return err
+		}
+		ui.SetOutputFormat(format)
+		if err := setupLogging(cmd, args); err != nil {
+			return err
+		}
+		return loadSettings(cmd)
+	},
 }
 
 // rootHelpFunc 自定义根命令的help函数，在显示help前先显示banner
@@ -47,6 +70,9 @@ func rootHelpFunc(cmd *cobra.Command, args []string) {
 // 支持的全局标志：
 // - --verbose, -v: 启用详细输出模式
 // - --debug: 启用调试模式，显示详细的诊断信息
+// - --output, -o: 输出格式（text, json, ndjson）
+// - --log-file: 将日志写入文件（追加），便于附在问题报告中
+// - --log-format: 日志格式（text, json）
 // - --help, -h: 显示帮助信息
 // - --version: 显示版本信息
 //
@@ -54,7 +80,10 @@ func rootHelpFunc(cmd *cobra.Command, args []string) {
 // - init: 初始化新的spec-driven项目
 // - download: 下载项目模板和资源
 // - version: 显示版本和系统信息
-// - config: 管理配置设置
+// - config: 显示配置信息，get/set/unset/list/path 管理用户和项目配置
+//
+// 配置优先级：
+// 命令行标志 > 环境变量 > 项目配置（.specify/config.json）> 用户配置 > 内置默认值
 //
 // 返回值：
 // - error: 命令执行过程中的错误，nil表示成功
@@ -70,12 +99,28 @@ func rootHelpFunc(cmd *cobra.Command, args []string) {
 //       os.Exit(1)
 //   }
 //
+// 信号处理：
+// - SIGINT/SIGTERM会取消传给子命令的context（cmd.Context()），
+//   各层据此中止HTTP请求、解压和git子进程，并清理临时文件
+// - 第一次信号后恢复默认信号行为，再次按下Ctrl-C会立即退出
+// - 返回前总是关闭键盘监听，避免终端停留在原始模式
+//
 // 注意事项：
 // - 该函数会阻塞直到命令执行完成
 // - 错误信息会自动格式化并输出到stderr
-// - 支持信号处理和优雅退出
+// - 被取消时返回的错误满足errors.Is(err, context.Canceled)
 func Execute() error {
-	return rootCmd.Execute()
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	defer ui.CloseKeyboard()
+	defer closeLogging()
+
+	go func() {
+		<-ctx.Done()
+		stop()
+	}()
+
+	return rootCmd.ExecuteContext(ctx)
 }
 
 // init 初始化CLI命令
@@ -95,6 +140,9 @@ func init() {
 	// 添加全局标志
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose output")
 	rootCmd.PersistentFlags().BoolVar(&debug, "debug", false, "Enable debug mode")
+	rootCmd.PersistentFlags().StringVarP(&output, "output", "o", "text", "Output format: text, json, ndjson")
+	rootCmd.PersistentFlags().StringVar(&logFile, "log-file", "", "Append logs to this file instead of stderr")
+	rootCmd.PersistentFlags().StringVar(&logFormat, "log-format", "text", "Log format: text, json")
 
 	// 添加子命令
 	rootCmd.AddCommand(initCmd)
@@ -102,6 +150,7 @@ func init() {
 	rootCmd.AddCommand(checkCmd)
 	rootCmd.AddCommand(versionCmd)
 	rootCmd.AddCommand(configCmd)
+	rootCmd.AddCommand(authCmd)
 }
 
 // GetVerbose 获取verbose标志状态
@@ -119,16 +168,31 @@ func printError(err error) {
 	fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 }
 
-// printVerbose 打印详细信息
-func printVerbose(format string, args ...interface{}) {
-	if verbose {
-		fmt.Printf("[VERBOSE] "+format+"\n", args...)
+// setupLogging 根据 --verbose/--debug/--log-file/--log-format 配置日志
+//
+// 日志器被设置为infrastructure的进程级默认日志器，之后由处理器创建的
+// TemplateProvider、GitOperations等组件都会使用它。
+func setupLogging(cmd *cobra.Command, args []string) error {
+	logger, closer, err := infrastructure.NewLogger(&infrastructure.LoggerConfig{
+		Level:  infrastructure.LogLevelFromFlags(verbose, debug),
+		Format: logFormat,
+		File:   logFile,
+	})
+	if err != nil {
+		return err
 	}
+
+	infrastructure.SetDefaultLogger(logger)
+	logCloser = closer
+
+	logger.Debug("command started", "command", cmd.CommandPath(), "args", args)
+	return nil
 }
 
-// printDebug 打印调试信息
-func printDebug(format string, args ...interface{}) {
-	if debug {
-		fmt.Printf("[DEBUG] "+format+"\n", args...)
+// closeLogging 关闭日志文件
+func closeLogging() {
+	if logCloser != nil {
+		logCloser.Close()
+		logCloser = nil
 	}
 }
\ No newline at end of file
@@ -0,0 +1,328 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"specify-cli/internal/config"
+	"specify-cli/internal/infrastructure"
+	"specify-cli/internal/types"
+	"specify-cli/internal/ui"
+)
+
+var (
+	// settings 当前命令的有效配置（由根命令的PersistentPreRunE加载）
+	settings *config.SettingsResolver
+
+	// config set/unset 的标志
+	configProject bool
+)
+
+// settingFlags 与配置项绑定的命令行标志（标志名 -> 配置项键名）
+//
+// 未显式指定这些标志时，其值取自环境变量、项目配置、用户配置或内置默认值；
+// 显式指定时记录为flag来源，在 specify config list 中可见。
+var settingFlags = map[string]string{
+	"ai":                 config.KeyAIAssistant,
+	"script":             config.KeyScriptType,
+	"template-repo":      config.KeyTemplateRepo,
+	"template-git":       config.KeyTemplateGit,
+	"api-url":            config.KeyAPIURL,
+	"proxy":              config.KeyProxy,
+	"ca-file":            config.KeyCAFile,
+	"client-cert":        config.KeyClientCert,
+	"client-key":         config.KeyClientKey,
+	"signing-key":        config.KeySigningKey,
+	"checksum":           config.KeyChecksum,
+	"on-conflict":        config.KeyOnConflict,
+	"limit-rate":         config.KeyLimitRate,
+	"mirrors":            config.KeyMirrors,
+	"name":               config.KeyProjectName,
+	"here":               config.KeyHere,
+	"force":              config.KeyForce,
+	"no-git":             config.KeyNoGit,
+	"ignore-agent-tools": config.KeyIgnoreTools,
+	"skip-tls":           config.KeySkipTLS,
+	"token":              config.KeyGitHubToken,
+	"dir":                config.KeyDownloadDir,
+	"progress":           config.KeyShowProgress,
+}
+
+// configGetCmd config get子命令
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print the effective value of a setting",
+	Long: `Print the effective value of a setting.
+
+Sensitive settings such as github_token are shown as ****.
+Use 'specify auth token' to print the token itself.`,
+	Args: cobra.ExactArgs(1),
+	RunE: runConfigGet,
+}
+
+// configSetCmd config set子命令
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Save a setting to the user (or project) config file",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runConfigSet,
+}
+
+// configUnsetCmd config unset子命令
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset <key>",
+	Short: "Remove a setting from the user (or project) config file",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runConfigUnset,
+}
+
+// configListCmd config list子命令
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all settings with their effective values and sources",
+	Args:  cobra.NoArgs,
+	RunE:  runConfigList,
+}
+
+// configPathCmd config path子命令
+var configPathCmd = &cobra.Command{
+	Use:   "path",
+	Short: "Print the location of the config files",
+	Args:  cobra.NoArgs,
+	RunE:  runConfigPath,
+}
+
+func init() {
+	configSetCmd.Flags().BoolVar(&configProject, "project", false, "Write to the project config (.specify/config.json) instead of the user config")
+	configUnsetCmd.Flags().BoolVar(&configProject, "project", false, "Remove from the project config instead of the user config")
+
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configUnsetCmd)
+	configCmd.AddCommand(configListCmd)
+	configCmd.AddCommand(configPathCmd)
+
+	// 主题名称、冲突策略和模板源格式由其他包定义，在此注册校验
+	config.SetSettingValidator(config.KeyTheme, func(value string) error {
+		_, err := ui.GetThemeManager().GetTheme(value)
+		return err
+	})
+	config.SetSettingValidator(config.KeyOnConflict, infrastructure.ValidateConflictResolution)
+	config.SetSettingValidator(config.KeyTemplateRepo, infrastructure.ValidateTemplateSource)
+	config.SetSettingValidator(config.KeyTemplateGit, infrastructure.ValidateGitTemplateSource)
+	config.SetSettingValidator(config.KeySigningKey, infrastructure.ValidateSigningKey)
+	config.SetSettingValidator(config.KeyChecksum, infrastructure.ValidateChecksum)
+	config.SetSettingValidator(config.KeyLimitRate, infrastructure.ValidateRate)
+	config.SetSettingValidator(config.KeyMirrors, infrastructure.ValidateMirrors)
+}
+
+// documentEnvBindings 在绑定标志的帮助文本中注明对应的环境变量
+//
+// 需要在命令的标志定义完成后调用（各命令文件的init函数末尾）。
+func documentEnvBindings(cmd *cobra.Command) {
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		key, ok := settingFlags[flag.Name]
+		if !ok {
+			return
+		}
+		if setting, ok := config.LookupSetting(key); ok && setting.EnvVar != "" {
+			flag.Usage += fmt.Sprintf(" [$%s]", setting.EnvVar)
+		}
+	})
+}
+
+// addNetworkFlags 添加API地址、代理和TLS证书相关的标志
+func addNetworkFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&apiURL, "api-url", "", "GitHub API base URL, e.g. https://ghe.example.com/api/v3 for GitHub Enterprise")
+	cmd.Flags().StringVar(&proxyURL, "proxy", "", "HTTP(S) proxy URL (default: HTTPS_PROXY/NO_PROXY from the environment)")
+	cmd.Flags().StringVar(&caFile, "ca-file", "", "PEM file with additional CA certificates to trust")
+	cmd.Flags().StringVar(&clientCert, "client-cert", "", "PEM client certificate for mutual TLS")
+	cmd.Flags().StringVar(&clientKey, "client-key", "", "PEM private key for --client-cert")
+}
+
+// addVerifyFlags 添加模板完整性校验相关的标志（init与download共用）
+func addVerifyFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&checksum, "checksum", "", "Expected sha256 of the template archive (sha256:<hex>)")
+	cmd.Flags().StringVar(&signingKey, "signing-key", "", "minisign or SSH public key (or key file) that must sign the release SHA256SUMS")
+}
+
+// addDownloadFlags 添加下载限速和镜像标志（init与download共用）
+func addDownloadFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVar(&limitRate, "limit-rate", "", "Maximum download speed in bytes per second, e.g. 500k or 2M (shared by all connections)")
+	cmd.Flags().StringVar(&mirrors, "mirrors", "", "Comma-separated mirror base URLs to try in order if the release asset download fails")
+}
+
+// resolveNetworkConfig 根据有效配置构建网络配置并检查证书文件
+//
+// 需要在applySettings之后调用，使显式指定的标志生效。
+func resolveNetworkConfig() (*types.NetworkConfig, error) {
+	networkConfig := settings.NetworkConfig()
+	if err := infrastructure.ValidateNetworkConfig(networkConfig); err != nil {
+		return nil, err
+	}
+	return networkConfig, nil
+}
+
+// loadSettings 加载当前目录的有效配置并应用UI主题
+//
+// config子命令下配置校验失败不会中止命令，以便用户修复错误的配置值。
+func loadSettings(cmd *cobra.Command) error {
+	workDir, err := os.Getwd()
+	if err != nil {
+		workDir = "."
+	}
+
+	settings, err = config.LoadSettings(workDir)
+	if err != nil {
+		return err
+	}
+
+	if err := settings.Validate(); err != nil {
+		if isConfigCommand(cmd) {
+			ui.ShowWarning(err.Error())
+			return nil
+		}
+		return err
+	}
+
+	ui.SetGlobalTheme(settings.Value(config.KeyTheme))
+	return nil
+}
+
+// isConfigCommand 判断是否为config命令或其子命令
+func isConfigCommand(cmd *cobra.Command) bool {
+	for c := cmd; c.HasParent(); c = c.Parent() {
+		if c.Name() == "config" && !c.Parent().HasParent() {
+			return true
+		}
+	}
+	return false
+}
+
+// applySettings 将配置值填入未显式指定的绑定标志
+//
+// 显式指定的标志记录到settings中作为最高优先级，并按配置项规则校验；
+// 其余标志使用更低优先级层的有效值（内置默认值为空时保持标志的零值），
+// 值无法赋给标志（例如布尔标志的环境变量不是布尔值）时返回包含来源的错误。
+func applySettings(cmd *cobra.Command) error {
+	var firstErr error
+	cmd.Flags().VisitAll(func(flag *pflag.Flag) {
+		key, ok := settingFlags[flag.Name]
+		if !ok {
+			return
+		}
+		if flag.Changed {
+			if err := config.ValidateSetting(key, flag.Value.String()); err != nil && firstErr == nil {
+				firstErr = fmt.Errorf("--%s: %w", flag.Name, err)
+			}
+			settings.SetFlag(key, "--"+flag.Name, flag.Value.String())
+			return
+		}
+		resolved := settings.Resolve(key)
+		if resolved.Value == "" {
+			return
+		}
+		if err := flag.Value.Set(resolved.Value); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("invalid %s %q (from %s %s): %w", key, resolved.Redacted().Value, resolved.Source, resolved.Origin, err)
+		}
+	})
+	return firstErr
+}
+
+// runConfigGet 执行config get命令
+func runConfigGet(cmd *cobra.Command, args []string) error {
+	if _, ok := config.LookupSetting(args[0]); !ok {
+		return config.ValidateSetting(args[0], "")
+	}
+
+	// 令牌等敏感值不输出到日志，需要时使用 specify auth token
+	resolved := settings.Resolve(args[0]).Redacted()
+	if ui.IsStructuredOutput() {
+		ui.EmitResult("config get", nil, map[string]interface{}{"setting": resolved}, nil)
+		return nil
+	}
+
+	fmt.Println(resolved.Value)
+	return nil
+}
+
+// runConfigSet 执行config set命令
+func runConfigSet(cmd *cobra.Command, args []string) error {
+	workDir, _ := os.Getwd()
+	path, err := settings.SetValue(args[0], args[1], configProject, workDir)
+	if err != nil {
+		return err
+	}
+
+	if ui.IsStructuredOutput() {
+		ui.EmitResult("config set", nil, map[string]interface{}{
+			"key": args[0], "value": args[1], "file": path,
+		}, nil)
+		return nil
+	}
+
+	ui.ShowSuccess(fmt.Sprintf("Set %s = %s in %s", args[0], args[1], path))
+	return nil
+}
+
+// runConfigUnset 执行config unset命令
+func runConfigUnset(cmd *cobra.Command, args []string) error {
+	workDir, _ := os.Getwd()
+	path, err := settings.UnsetValue(args[0], configProject, workDir)
+	if err != nil {
+		return err
+	}
+
+	if ui.IsStructuredOutput() {
+		ui.EmitResult("config unset", nil, map[string]interface{}{
+			"key": args[0], "file": path,
+		}, nil)
+		return nil
+	}
+
+	ui.ShowSuccess(fmt.Sprintf("Removed %s from %s", args[0], path))
+	return nil
+}
+
+// runConfigList 执行config list命令
+func runConfigList(cmd *cobra.Command, args []string) error {
+	all := settings.All()
+	if ui.IsStructuredOutput() {
+		ui.EmitResult("config list", nil, map[string]interface{}{"settings": all}, nil)
+		return nil
+	}
+
+	printSettings(all)
+	return nil
+}
+
+// printSettings 以表格形式打印配置项及其来源
+func printSettings(all []config.ResolvedSetting) {
+	fmt.Printf("  %-20s %-30s %s\n", "KEY", "VALUE", "SOURCE")
+	for _, resolved := range all {
+		source := string(resolved.Source)
+		if resolved.Origin != "" {
+			source += " (" + resolved.Origin + ")"
+		}
+		fmt.Printf("  %-20s %-30s %s\n", resolved.Key, resolved.Value, source)
+	}
+}
+
+// runConfigPath 执行config path命令
+func runConfigPath(cmd *cobra.Command, args []string) error {
+	if ui.IsStructuredOutput() {
+		ui.EmitResult("config path", nil, map[string]interface{}{
+			"user":    settings.UserPath(),
+			"project": settings.ProjectPath(),
+		}, nil)
+		return nil
+	}
+
+	fmt.Printf("User:    %s\n", settings.UserPath())
+	if settings.ProjectPath() != "" {
+		fmt.Printf("Project: %s\n", settings.ProjectPath())
+	}
+	return nil
+}
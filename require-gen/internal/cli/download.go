@@ -5,6 +5,8 @@ import (
 
 	"github.com/spf13/cobra"
 	"specify-cli/internal/business"
+	"specify-cli/internal/config"
+	"specify-cli/internal/infrastructure"
 	"specify-cli/internal/types"
 	"specify-cli/internal/ui"
 )
@@ -27,7 +29,20 @@ Useful for offline development or custom project setups.
 Examples:
   specify download claude-code              # Download Claude templates
   specify download github-copilot --dir ./templates  # Download to specific directory
-  specify download --progress               # Show download progress`,
+  specify download --progress               # Show download progress
+  specify download claude --on-conflict skip  # Keep files that already exist
+  specify download claude --proxy http://proxy:8080 --ca-file corp-ca.pem  # Behind a corporate proxy
+  specify download claude --limit-rate 500k  # Leave bandwidth for other traffic on a VPN
+  specify download claude --mirrors https://artifactory.example.com/artifactory/github  # Fall back to a mirror
+
+An interrupted download resumes when the same command is run again.
+
+The assistant defaults to the ai_assistant setting (see 'specify config list').
+Options can also be set with SPECIFY_* environment variables, including
+SPECIFY_SCRIPT, SPECIFY_SKIP_TLS and SPECIFY_TOKEN which have no download flag.
+SPECIFY_TOKEN is only sent to GitHub; gitlab:// and gitea:// template sources
+use SPECIFY_GITLAB_TOKEN and SPECIFY_GITEA_TOKEN.
+Without --proxy the standard HTTPS_PROXY and NO_PROXY variables are used.`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runDownload,
 }
@@ -47,14 +62,33 @@ func init() {
 	// 添加download命令的标志
 	downloadCmd.Flags().StringVar(&downloadDir, "dir", "", "Directory to download templates to")
 	downloadCmd.Flags().BoolVar(&showProgress, "progress", false, "Show download progress")
+	downloadCmd.Flags().StringVar(&onConflict, "on-conflict", "", "How to handle existing files: skip, overwrite, rename, prompt, merge")
+	downloadCmd.Flags().StringVar(&templateRepo, "template-repo", "", "Template source: owner/repo (GitHub), gitlab://host/group/project, gitea://host/owner/repo, or an index.json URL")
+	downloadCmd.Flags().StringVar(&templateGit, "template-git", "", "Build templates from a git repository (url@ref), overrides --template-repo")
+	addNetworkFlags(downloadCmd)
+	addVerifyFlags(downloadCmd)
+	addDownloadFlags(downloadCmd)
+
+	documentEnvBindings(downloadCmd)
 }
 
 // runDownload 执行download命令
 func runDownload(cmd *cobra.Command, args []string) error {
-	// 解析AI助手参数
-	var assistant string
+	// 未显式指定的标志使用配置文件和环境变量中的值
+	if err := applySettings(cmd); err != nil {
+		return err
+	}
+	defaults := settings.Defaults()
+	networkConfig, err := resolveNetworkConfig()
+	if err != nil {
+		return err
+	}
+
+	// 解析AI助手参数，未指定时使用配置的默认AI助手
+	assistant := defaults.AIAssistant
 	if len(args) > 0 {
 		assistant = args[0]
+		settings.SetFlag(config.KeyAIAssistant, "argument", assistant)
 	}
 
 	// 如果没有指定AI助手，提示用户选择
@@ -66,15 +100,33 @@ func runDownload(cmd *cobra.Command, args []string) error {
 	opts := types.DownloadOptions{
 		AIAssistant:  assistant,
 		DownloadDir:  downloadDir,
-		ScriptType:   scriptType,
+		ScriptType:   defaults.ScriptType,
 		Verbose:      GetVerbose(),
 		ShowProgress: showProgress,
-		GitHubToken:  githubToken,
+		GitHubToken:  settings.Value(config.KeyGitHubToken),
+		GitLabToken:  settings.Value(config.KeyGitLabToken),
+		GiteaToken:   settings.Value(config.KeyGiteaToken),
+		SkipTLS:      settings.Bool(config.KeySkipTLS),
+		OnConflict:   onConflict,
+		TemplateRepo: templateRepo,
+		TemplateGit:  templateGit,
+		SigningKey:   signingKey,
+	}
+	if opts.LimitRate, err = infrastructure.ParseRate(limitRate); err != nil {
+		return err
+	}
+	if opts.Mirrors, err = infrastructure.ParseMirrors(mirrors); err != nil {
+		return err
+	}
+	if checksum != "" {
+		if opts.ChecksumType, opts.Checksum, err = infrastructure.ParseChecksum(checksum); err != nil {
+			return err
+		}
 	}
 
 	// 创建业务逻辑处理器
-	downloadHandler := business.NewDownloadHandler()
+	downloadHandler := business.NewDownloadHandlerWithConfig(networkConfig)
 
 	// 执行下载流程
-	return downloadHandler.Execute(opts)
+	return downloadHandler.Execute(cmd.Context(), opts)
 }
\ No newline at end of file
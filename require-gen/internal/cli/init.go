@@ -18,12 +18,26 @@ var (
 	here        bool
 	aiAssistant string
 	scriptType  string
-	githubToken string
+	githubToken  string
+	templateRepo string
+	templateGit  string
+	// 网络标志（init与download共用）
+	apiURL     string
+	proxyURL   string
+	caFile     string
+	clientCert string
+	clientKey  string
+	limitRate  string
+	mirrors    string
+	// 完整性校验标志（init与download共用）
+	checksum   string
+	signingKey string
 	// 新增的CLI标志
 	force       bool
 	noGit       bool
 	ignoreTools bool
 	skipTLS     bool
+	onConflict  string
 )
 
 // initCmd init子命令
@@ -47,7 +61,23 @@ Examples:
   specify init my-project --force           # Force overwrite existing directory
   specify init my-project --no-git          # Skip Git repository initialization
   specify init my-project --ignore-agent-tools  # Ignore tool availability checks
-  specify init my-project --skip-tls        # Skip TLS certificate verification`,
+  specify init my-project --skip-tls        # Skip TLS certificate verification
+  specify init my-project --template-repo my-org/spec-kit  # Use templates from a fork
+  specify init my-project --ca-file corp-ca.pem  # Trust a TLS-inspecting proxy's CA
+  specify init my-project --api-url https://ghe.example.com/api/v3 --template-repo corp/spec-kit  # GitHub Enterprise
+  specify init my-project --template-repo gitlab://gitlab.example.com/team/spec-kit  # GitLab releases
+  specify init my-project --template-repo https://files.example.com/spec-kit/index.json  # Static index
+  specify init my-project --template-git https://github.com/my-org/spec-kit.git@v0.0.50  # Build from a git tag
+  specify init my-project --checksum sha256:<hex>  # Pin the exact template archive
+  specify init my-project --signing-key ./minisign.pub  # Require a signed SHA256SUMS
+  specify init --here --force --on-conflict prompt  # Review each conflicting file
+
+Every flag can also be set with the SPECIFY_* environment variable shown next
+to it, e.g. SPECIFY_AI=claude SPECIFY_NO_GIT=true specify init my-project.
+SPECIFY_TIMEOUT and SPECIFY_THEME configure settings that have no flag.
+Without --proxy the standard HTTPS_PROXY and NO_PROXY variables are used;
+NO_PROXY exceptions also apply to an explicit --proxy.
+Run 'specify config' to see the effective value and source of each option.`,
 	Args: cobra.MaximumNArgs(1),
 	RunE: runInit,
 }
@@ -74,16 +104,33 @@ func init() {
 	initCmd.Flags().StringVarP(&aiAssistant, "ai", "a", "", "AI assistant to use")
 	initCmd.Flags().StringVarP(&scriptType, "script", "s", "", "Script type (sh/ps)")
 	initCmd.Flags().StringVarP(&githubToken, "token", "t", "", "GitHub token for private repositories")
+	initCmd.Flags().StringVar(&templateRepo, "template-repo", "", "Template source: owner/repo (GitHub), gitlab://host/group/project, gitea://host/owner/repo, or an index.json URL")
+	initCmd.Flags().StringVar(&templateGit, "template-git", "", "Build templates from a git repository (url@ref), overrides --template-repo")
 	
 	// 新增的CLI标志
 	initCmd.Flags().BoolVar(&force, "force", false, "Force overwrite existing project directory")
 	initCmd.Flags().BoolVar(&noGit, "no-git", false, "Skip Git repository initialization")
 	initCmd.Flags().BoolVar(&ignoreTools, "ignore-agent-tools", false, "Ignore AI assistant tool availability checks")
 	initCmd.Flags().BoolVar(&skipTLS, "skip-tls", false, "Skip TLS certificate verification")
+	initCmd.Flags().StringVar(&onConflict, "on-conflict", "", "How to handle existing files: skip, overwrite, rename, prompt, merge")
+	addNetworkFlags(initCmd)
+	addVerifyFlags(initCmd)
+	addDownloadFlags(initCmd)
+
+	documentEnvBindings(initCmd)
 }
 
 // runInit 执行init命令
 func runInit(cmd *cobra.Command, args []string) error {
+	// 未显式指定的标志使用配置文件和环境变量中的值
+	if err := applySettings(cmd); err != nil {
+		return err
+	}
+	networkConfig, err := resolveNetworkConfig()
+	if err != nil {
+		return err
+	}
+
 	// 解析项目名称
 	if len(args) > 0 && projectName == "" {
 		projectName = args[0]
@@ -101,6 +148,8 @@ func runInit(cmd *cobra.Command, args []string) error {
 		AIAssistant:  aiAssistant,
 		ScriptType:   scriptType,
 		GitHubToken:  githubToken,
+		GitLabToken:  settings.Value(config.KeyGitLabToken),
+		GiteaToken:   settings.Value(config.KeyGiteaToken),
 		Verbose:      GetVerbose(),
 		Debug:        GetDebug(),
 		// 新增的CLI标志
@@ -108,16 +157,23 @@ func runInit(cmd *cobra.Command, args []string) error {
 		NoGit:        noGit,
 		IgnoreTools:  ignoreTools,
 		SkipTLS:      skipTLS,
+		OnConflict:   onConflict,
+		TemplateRepo: templateRepo,
+		TemplateGit:  templateGit,
+		Checksum:     checksum,
+		SigningKey:   signingKey,
+		LimitRate:    limitRate,
+		Mirrors:      mirrors,
 	}
 
 	// 显示横幅
 	ui.ShowBanner()
 
 	// 创建业务逻辑处理器
-	initHandler := business.NewInitHandler()
+	initHandler := business.NewInitHandlerWithConfig(networkConfig)
 
 	// 执行初始化流程
-	return initHandler.Execute(opts)
+	return initHandler.Execute(cmd.Context(), opts)
 }
 
 // validateInitOptions 验证初始化选项
@@ -20,8 +20,14 @@ var versionCmd = &cobra.Command{
 // configCmd config子命令
 var configCmd = &cobra.Command{
 	Use:   "config",
-	Short: "Show configuration information",
-	Long:  "Display current configuration including available AI assistants and script types.",
+	Short: "Show and manage configuration",
+	Long: `Display current configuration including effective settings, available AI assistants and script types.
+
+Settings are resolved in this order (highest first):
+  flags > environment variables > project config (.specify/config.json) > user config > built-in defaults
+
+Use 'specify config set <key> <value>' to save a setting to the user config file,
+or add --project to save it to the project config.`,
 	Run:   runConfig,
 }
 
@@ -50,6 +56,17 @@ func init() {
 
 // runVersion 执行version命令
 func runVersion(cmd *cobra.Command, args []string) {
+	if ui.IsStructuredOutput() {
+		ui.EmitResult("version", nil, map[string]interface{}{
+			"version":    rootCmd.Version,
+			"go_version": runtime.Version(),
+			"os":         runtime.GOOS,
+			"arch":       runtime.GOARCH,
+			"compiler":   runtime.Compiler,
+		}, nil)
+		return
+	}
+
 	fmt.Printf("Specify CLI v%s\n", rootCmd.Version)
 	fmt.Printf("Go version: %s\n", runtime.Version())
 	fmt.Printf("OS/Arch: %s/%s\n", runtime.GOOS, runtime.GOARCH)
@@ -58,9 +75,17 @@ func runVersion(cmd *cobra.Command, args []string) {
 
 // runConfig 执行config命令
 func runConfig(cmd *cobra.Command, args []string) {
+	if ui.IsStructuredOutput() {
+		ui.EmitResult("config", nil, collectConfigInfo(), nil)
+		return
+	}
+
 	fmt.Println("=== Specify CLI Configuration ===")
 	fmt.Printf("Version: %s\n", rootCmd.Version)
 	fmt.Printf("Default Script Type: %s\n", config.GetDefaultScriptType())
+
+	fmt.Println("\n=== Settings ===")
+	printSettings(settings.All())
 	
 	fmt.Println("\n=== Available AI Assistants ===")
 	agents := config.GetAllAgents()
@@ -87,4 +112,39 @@ func runConfig(cmd *cobra.Command, args []string) {
 	fmt.Printf("  OS: %s\n", runtime.GOOS)
 	fmt.Printf("  Architecture: %s\n", runtime.GOARCH)
 	fmt.Printf("  Go Version: %s\n", runtime.Version())
-}
\ No newline at end of file
+}
+
+// collectConfigInfo 汇总结构化输出中的配置信息
+func collectConfigInfo() map[string]interface{} {
+	agents := make(map[string]interface{})
+	for key, name := range config.GetAllAgents() {
+		info, _ := config.GetAgentInfo(key)
+		agents[key] = map[string]interface{}{
+			"name":         name,
+			"requires_cli": info.RequiresCLI,
+			"install_url":  info.InstallURL,
+		}
+	}
+
+	scripts := make(map[string]interface{})
+	for key, desc := range config.GetAllScriptTypes() {
+		scriptInfo, _ := config.GetScriptType(key)
+		scripts[key] = map[string]interface{}{
+			"description": desc,
+			"extension":   scriptInfo.Extension,
+		}
+	}
+
+	return map[string]interface{}{
+		"version":             rootCmd.Version,
+		"default_script_type": config.GetDefaultScriptType(),
+		"settings":            settings.All(),
+		"ai_assistants":       agents,
+		"script_types":        scripts,
+		"runtime": map[string]interface{}{
+			"os":         runtime.GOOS,
+			"arch":       runtime.GOARCH,
+			"go_version": runtime.Version(),
+		},
+	}
+}
@@ -0,0 +1,673 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"specify-cli/internal/types"
+)
+
+// SettingSource 配置值的来源
+type SettingSource string
+
+// 配置来源，按优先级从低到高排列
+const (
+	SourceDefault SettingSource = "default"
+	SourceUser    SettingSource = "user"
+	SourceProject SettingSource = "project"
+	SourceEnv     SettingSource = "env"
+	SourceFlag    SettingSource = "flag"
+)
+
+// 配置项键名，与types.DefaultConfig和types.DownloadOptions的JSON标签一致
+const (
+	KeyAIAssistant  = "ai_assistant"
+	KeyScriptType   = "script_type"
+	KeyTemplateRepo = "template_repo"
+	KeyTemplateGit  = "template_git"
+	KeyAPIURL       = "api_url"
+	KeyProxy        = "proxy"
+	KeyCAFile       = "ca_file"
+	KeyClientCert   = "client_cert"
+	KeyClientKey    = "client_key"
+	KeySigningKey   = "signing_key"
+	KeyTimeout      = "timeout"
+	KeyTheme        = "theme"
+	KeyOnConflict   = "on_conflict"
+	KeyLimitRate    = "limit_rate"
+	KeyMirrors      = "mirrors"
+
+	// 仅能通过命令行标志或环境变量设置的选项
+	KeyProjectName  = "project_name"
+	KeyHere         = "here"
+	KeyForce        = "force"
+	KeyNoGit        = "no_git"
+	KeyIgnoreTools  = "ignore_agent_tools"
+	KeySkipTLS      = "skip_tls"
+	KeyGitHubToken  = "github_token"
+	KeyGitLabToken  = "gitlab_token"
+	KeyGiteaToken   = "gitea_token"
+	KeyDownloadDir  = "download_dir"
+	KeyShowProgress = "show_progress"
+	KeyChecksum     = "checksum"
+)
+
+// Setting 可持久化的配置项定义
+//
+// 字段说明：
+//   - Key: 配置文件和 specify config 子命令中使用的键名
+//   - Description: 在 specify config list 中显示的说明
+//   - Default: 内置默认值，空表示未设置（例如未设置AI助手时交互选择）
+//   - EnvVar: 覆盖该配置项的环境变量
+//   - Validate: 值校验函数，nil表示不校验
+//   - EnvOnly: 只能通过标志或环境变量设置，不能写入配置文件
+//     （一次性的选项如 --force，或不宜明文保存的令牌）
+//   - Sensitive: 显示时隐藏实际值
+type Setting struct {
+	Key         string
+	Description string
+	Default     string
+	EnvVar      string
+	Validate    func(value string) error
+	EnvOnly     bool
+	Sensitive   bool
+}
+
+// Settings 所有支持的配置项，按显示顺序排列
+var Settings = []Setting{
+	{
+		Key:         KeyAIAssistant,
+		Description: "Default AI assistant",
+		EnvVar:      "SPECIFY_AI",
+		Validate:    validateAIAssistant,
+	},
+	{
+		Key:         KeyScriptType,
+		Description: "Default script type (sh/ps)",
+		EnvVar:      "SPECIFY_SCRIPT",
+		Validate:    validateScriptType,
+	},
+	{
+		Key:         KeyTemplateRepo,
+		Description: "Template source: owner/repo (GitHub), gitlab://host/group/project, gitea://host/owner/repo, or an index.json URL",
+		Default:     types.DefaultTemplateRepo,
+		EnvVar:      "SPECIFY_TEMPLATE_REPO",
+	},
+	{
+		Key:         KeyTemplateGit,
+		Description: "Git repository to build templates from (url@ref); overrides template_repo",
+		EnvVar:      "SPECIFY_TEMPLATE_GIT",
+	},
+	{
+		Key:         KeyAPIURL,
+		Description: "GitHub API base URL (GitHub Enterprise: https://<host>/api/v3)",
+		Default:     types.DefaultGitHubAPIURL,
+		EnvVar:      "SPECIFY_API_URL",
+		Validate:    validateAPIURL,
+	},
+	{
+		Key:         KeyProxy,
+		Description: "HTTP(S) proxy URL",
+		EnvVar:      "SPECIFY_PROXY",
+		Validate:    validateProxy,
+	},
+	{
+		Key:         KeyCAFile,
+		Description: "Additional CA certificates (PEM) to trust, e.g. for a TLS-inspecting proxy",
+		EnvVar:      "SPECIFY_CA_FILE",
+		Validate:    validateFile,
+	},
+	{
+		Key:         KeyClientCert,
+		Description: "Client certificate (PEM) for mutual TLS",
+		EnvVar:      "SPECIFY_CLIENT_CERT",
+		Validate:    validateFile,
+	},
+	{
+		Key:         KeyClientKey,
+		Description: "Private key (PEM) for the client certificate",
+		EnvVar:      "SPECIFY_CLIENT_KEY",
+		Validate:    validateFile,
+	},
+	{
+		Key:         KeySigningKey,
+		Description: "Public key (minisign or SSH) that must sign the release SHA256SUMS, or a file containing it",
+		EnvVar:      "SPECIFY_SIGNING_KEY",
+	},
+	{
+		Key:         KeyTimeout,
+		Description: "HTTP request timeout (e.g. 30s, 2m)",
+		Default:     "30s",
+		EnvVar:      "SPECIFY_TIMEOUT",
+		Validate:    validateTimeout,
+	},
+	{
+		Key:         KeyTheme,
+		Description: "UI color theme",
+		Default:     "default",
+		EnvVar:      "SPECIFY_THEME",
+	},
+	{
+		Key:         KeyOnConflict,
+		Description: "How to handle existing files (skip, overwrite, rename, prompt, merge)",
+		EnvVar:      "SPECIFY_ON_CONFLICT",
+	},
+	{
+		Key:         KeyLimitRate,
+		Description: "Maximum template download speed in bytes per second (e.g. 500k, 2M); empty for no limit",
+		EnvVar:      "SPECIFY_LIMIT_RATE",
+	},
+	{
+		Key:         KeyMirrors,
+		Description: "Comma-separated mirror URLs tried in order when a release asset download fails",
+		EnvVar:      "SPECIFY_MIRRORS",
+	},
+	{
+		Key:         KeyProjectName,
+		Description: "Project name for init",
+		EnvVar:      "SPECIFY_PROJECT_NAME",
+		EnvOnly:     true,
+	},
+	{
+		Key:         KeyHere,
+		Description: "Initialize in the current directory",
+		EnvVar:      "SPECIFY_HERE",
+		Validate:    validateBool,
+		EnvOnly:     true,
+	},
+	{
+		Key:         KeyForce,
+		Description: "Force overwrite of an existing project directory",
+		EnvVar:      "SPECIFY_FORCE",
+		Validate:    validateBool,
+		EnvOnly:     true,
+	},
+	{
+		Key:         KeyNoGit,
+		Description: "Skip Git repository initialization",
+		EnvVar:      "SPECIFY_NO_GIT",
+		Validate:    validateBool,
+		EnvOnly:     true,
+	},
+	{
+		Key:         KeyIgnoreTools,
+		Description: "Ignore AI assistant tool availability checks",
+		EnvVar:      "SPECIFY_IGNORE_AGENT_TOOLS",
+		Validate:    validateBool,
+		EnvOnly:     true,
+	},
+	{
+		Key:         KeySkipTLS,
+		Description: "Skip TLS certificate verification",
+		EnvVar:      "SPECIFY_SKIP_TLS",
+		Validate:    validateBool,
+		EnvOnly:     true,
+	},
+	{
+		Key:         KeyGitHubToken,
+		Description: "GitHub token for API requests",
+		EnvVar:      "SPECIFY_TOKEN",
+		EnvOnly:     true,
+		Sensitive:   true,
+	},
+	{
+		Key:         KeyGitLabToken,
+		Description: "GitLab token for gitlab:// template sources",
+		EnvVar:      "SPECIFY_GITLAB_TOKEN",
+		EnvOnly:     true,
+		Sensitive:   true,
+	},
+	{
+		Key:         KeyGiteaToken,
+		Description: "Gitea or Forgejo token for gitea:// template sources",
+		EnvVar:      "SPECIFY_GITEA_TOKEN",
+		EnvOnly:     true,
+		Sensitive:   true,
+	},
+	{
+		Key:         KeyDownloadDir,
+		Description: "Directory for downloaded templates",
+		EnvVar:      "SPECIFY_DIR",
+		EnvOnly:     true,
+	},
+	{
+		Key:         KeyShowProgress,
+		Description: "Show download progress",
+		EnvVar:      "SPECIFY_PROGRESS",
+		Validate:    validateBool,
+		EnvOnly:     true,
+	},
+	{
+		Key:         KeyChecksum,
+		Description: "Expected checksum of the template archive (sha256:<hex>)",
+		EnvVar:      "SPECIFY_CHECKSUM",
+		EnvOnly:     true,
+	},
+}
+
+// LookupSetting 按键名查找配置项定义
+func LookupSetting(key string) (Setting, bool) {
+	for _, setting := range Settings {
+		if setting.Key == key {
+			return setting, true
+		}
+	}
+	return Setting{}, false
+}
+
+// ValidateSetting 校验配置项的值
+func ValidateSetting(key, value string) error {
+	setting, ok := LookupSetting(key)
+	if !ok {
+		return fmt.Errorf("unknown config key '%s' (valid: %s)", key, strings.Join(SettingKeys(), ", "))
+	}
+	if setting.Validate == nil || value == "" {
+		return nil
+	}
+	if err := setting.Validate(value); err != nil {
+		return fmt.Errorf("invalid value for %s: %w", key, err)
+	}
+	return nil
+}
+
+// SettingKeys 返回所有配置项键名
+func SettingKeys() []string {
+	keys := make([]string, 0, len(Settings))
+	for _, setting := range Settings {
+		keys = append(keys, setting.Key)
+	}
+	return keys
+}
+
+// validateBool 校验布尔值（true/false/1/0等）
+func validateBool(value string) error {
+	if _, err := strconv.ParseBool(value); err != nil {
+		return fmt.Errorf("expected true or false, got '%s'", value)
+	}
+	return nil
+}
+
+// validateAIAssistant 校验AI助手
+func validateAIAssistant(value string) error {
+	if _, exists := AgentConfig[value]; !exists {
+		return fmt.Errorf("unknown AI assistant: %s", value)
+	}
+	return nil
+}
+
+// validateScriptType 校验脚本类型
+func validateScriptType(value string) error {
+	if _, exists := ScriptTypeChoices[value]; !exists {
+		return fmt.Errorf("unknown script type: %s", value)
+	}
+	return nil
+}
+
+// validateProxy 校验代理地址
+func validateProxy(value string) error {
+	u, err := url.Parse(value)
+	if err != nil {
+		return err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("expected a URL such as http://proxy:8080, got '%s'", value)
+	}
+	return nil
+}
+
+// validateFile 校验文件是否存在
+func validateFile(value string) error {
+	info, err := os.Stat(value)
+	if err != nil {
+		return fmt.Errorf("cannot access file: %w", err)
+	}
+	if info.IsDir() {
+		return fmt.Errorf("'%s' is a directory", value)
+	}
+	return nil
+}
+
+// validateAPIURL 校验API基础URL
+func validateAPIURL(value string) error {
+	u, err := url.Parse(value)
+	if err != nil {
+		return err
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("expected a URL such as https://ghe.example.com/api/v3, got '%s'", value)
+	}
+	return nil
+}
+
+// validateTimeout 校验超时时间
+func validateTimeout(value string) error {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+	if d <= 0 {
+		return fmt.Errorf("timeout must be positive")
+	}
+	return nil
+}
+
+// SettingsFile 配置文件内容，键为配置项键名
+type SettingsFile map[string]string
+
+// UserConfigPath 获取用户配置文件路径
+//
+// 位于平台配置目录下：
+//   - Linux: $XDG_CONFIG_HOME/specify/config.json（默认 ~/.config）
+//   - macOS: ~/Library/Application Support/specify/config.json
+//   - Windows: %APPDATA%\specify\config.json
+func UserConfigPath() (string, error) {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to locate user config directory: %w", err)
+	}
+	return filepath.Join(dir, "specify", "config.json"), nil
+}
+
+// ProjectConfigPath 获取项目配置文件路径
+func ProjectConfigPath(projectDir string) string {
+	return filepath.Join(projectDir, ".specify", "config.json")
+}
+
+// FindProjectConfig 从startDir向上查找项目配置文件
+//
+// 返回找到的第一个 .specify/config.json，未找到时返回空字符串。
+func FindProjectConfig(startDir string) string {
+	dir, err := filepath.Abs(startDir)
+	if err != nil {
+		return ""
+	}
+	for {
+		path := ProjectConfigPath(dir)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// LoadSettingsFile 读取配置文件，文件不存在时返回空配置
+func LoadSettingsFile(path string) (SettingsFile, error) {
+	settings := SettingsFile{}
+	if path == "" {
+		return settings, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return settings, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+
+	if err := json.Unmarshal(data, &settings); err != nil {
+		return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return settings, nil
+}
+
+// SaveSettingsFile 写入配置文件，必要时创建目录
+func SaveSettingsFile(path string, settings SettingsFile) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(settings, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// ResolvedSetting 合并各配置层后的有效值
+//
+// Origin 说明值的具体出处：配置文件路径、环境变量名或标志名。
+type ResolvedSetting struct {
+	Key    string        `json:"key"`
+	Value  string        `json:"value"`
+	Source SettingSource `json:"source"`
+	Origin string        `json:"origin,omitempty"`
+}
+
+// Redacted 返回用于显示的副本，敏感配置项的值被隐藏
+func (s ResolvedSetting) Redacted() ResolvedSetting {
+	if setting, _ := LookupSetting(s.Key); setting.Sensitive && s.Value != "" {
+		s.Value = "****"
+	}
+	return s
+}
+
+// SettingsResolver 按优先级合并配置层
+//
+// 优先级：命令行标志 > 环境变量 > 项目配置 > 用户配置 > 内置默认值。
+// 空字符串视为未设置，会继续查找更低优先级的层。
+type SettingsResolver struct {
+	userPath    string
+	projectPath string
+	user        SettingsFile
+	project     SettingsFile
+	flags       map[string]ResolvedSetting
+	getenv      func(string) string
+}
+
+// LoadSettings 加载用户配置和workDir所在项目的配置
+func LoadSettings(workDir string) (*SettingsResolver, error) {
+	userPath, err := UserConfigPath()
+	if err != nil {
+		userPath = ""
+	}
+	return LoadSettingsFrom(userPath, FindProjectConfig(workDir))
+}
+
+// LoadSettingsFrom 从指定的用户和项目配置文件加载，路径为空表示该层不存在
+func LoadSettingsFrom(userPath, projectPath string) (*SettingsResolver, error) {
+	user, err := LoadSettingsFile(userPath)
+	if err != nil {
+		return nil, err
+	}
+	project, err := LoadSettingsFile(projectPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SettingsResolver{
+		userPath:    userPath,
+		projectPath: projectPath,
+		user:        user,
+		project:     project,
+		flags:       make(map[string]ResolvedSetting),
+		getenv:      os.Getenv,
+	}, nil
+}
+
+// UserPath 用户配置文件路径
+func (r *SettingsResolver) UserPath() string {
+	return r.userPath
+}
+
+// ProjectPath 项目配置文件路径，未找到项目配置时为空
+func (r *SettingsResolver) ProjectPath() string {
+	return r.projectPath
+}
+
+// SetFlag 记录由命令行显式指定的值，origin为标志名或参数说明
+func (r *SettingsResolver) SetFlag(key, origin, value string) {
+	r.flags[key] = ResolvedSetting{Key: key, Value: value, Source: SourceFlag, Origin: origin}
+}
+
+// Resolve 获取配置项的有效值
+func (r *SettingsResolver) Resolve(key string) ResolvedSetting {
+	if resolved, ok := r.flags[key]; ok && resolved.Value != "" {
+		return resolved
+	}
+
+	setting, _ := LookupSetting(key)
+	if setting.EnvVar != "" {
+		if value := r.getenv(setting.EnvVar); value != "" {
+			return ResolvedSetting{Key: key, Value: value, Source: SourceEnv, Origin: setting.EnvVar}
+		}
+	}
+	if !setting.EnvOnly {
+		if value := r.project[key]; value != "" {
+			return ResolvedSetting{Key: key, Value: value, Source: SourceProject, Origin: r.projectPath}
+		}
+		if value := r.user[key]; value != "" {
+			return ResolvedSetting{Key: key, Value: value, Source: SourceUser, Origin: r.userPath}
+		}
+	}
+	return ResolvedSetting{Key: key, Value: setting.Default, Source: SourceDefault}
+}
+
+// Value 获取配置项的有效值字符串
+func (r *SettingsResolver) Value(key string) string {
+	return r.Resolve(key).Value
+}
+
+// Bool 获取布尔配置项的有效值，未设置或无效时为false
+func (r *SettingsResolver) Bool(key string) bool {
+	value, _ := strconv.ParseBool(r.Value(key))
+	return value
+}
+
+// All 获取所有配置项的有效值（用于显示，敏感值已隐藏）
+func (r *SettingsResolver) All() []ResolvedSetting {
+	all := make([]ResolvedSetting, 0, len(Settings))
+	for _, setting := range Settings {
+		all = append(all, r.Resolve(setting.Key).Redacted())
+	}
+	return all
+}
+
+// Validate 校验所有有效值，返回第一个错误
+//
+// 配置文件可能被手动编辑，环境变量也可能设置错误，
+// 在命令执行前统一校验可以给出包含来源的错误信息。
+func (r *SettingsResolver) Validate() error {
+	for _, setting := range Settings {
+		resolved := r.Resolve(setting.Key)
+		if resolved.Source == SourceDefault {
+			continue
+		}
+		if err := ValidateSetting(resolved.Key, resolved.Value); err != nil {
+			return fmt.Errorf("%w (from %s %s)", err, resolved.Source, resolved.Origin)
+		}
+	}
+	return nil
+}
+
+// Defaults 将有效值转换为types.DefaultConfig
+func (r *SettingsResolver) Defaults() *types.DefaultConfig {
+	timeout, _ := time.ParseDuration(r.Value(KeyTimeout))
+	return &types.DefaultConfig{
+		AIAssistant:  r.Value(KeyAIAssistant),
+		ScriptType:   r.Value(KeyScriptType),
+		TemplateRepo: r.Value(KeyTemplateRepo),
+		TemplateGit:  r.Value(KeyTemplateGit),
+		Proxy:        r.Value(KeyProxy),
+		Timeout:      timeout,
+		Theme:        r.Value(KeyTheme),
+	}
+}
+
+// NetworkConfig 将API地址、代理、超时和TLS相关的有效值转换为types.NetworkConfig
+//
+// 未配置代理时ProxyURL为空，此时使用HTTPS_PROXY/NO_PROXY环境变量；
+// 未配置任何证书文件时TLS为nil，使用系统默认的证书校验。
+func (r *SettingsResolver) NetworkConfig() *types.NetworkConfig {
+	timeout, _ := time.ParseDuration(r.Value(KeyTimeout))
+	networkConfig := &types.NetworkConfig{
+		ProxyURL:   r.Value(KeyProxy),
+		APIBaseURL: r.Value(KeyAPIURL),
+		Timeout:    timeout,
+	}
+
+	caFile, certFile, keyFile := r.Value(KeyCAFile), r.Value(KeyClientCert), r.Value(KeyClientKey)
+	if caFile != "" || certFile != "" || keyFile != "" {
+		networkConfig.TLS = &types.TLSConfig{
+			CAFile:   caFile,
+			CertFile: certFile,
+			KeyFile:  keyFile,
+		}
+	}
+	return networkConfig
+}
+
+// SetValue 校验并写入配置文件中的配置项
+//
+// project为true时写入项目配置（当前目录的 .specify/config.json，
+// 或已找到的项目配置），否则写入用户配置。返回写入的文件路径。
+func (r *SettingsResolver) SetValue(key, value string, project bool, workDir string) (string, error) {
+	if err := ValidateSetting(key, value); err != nil {
+		return "", err
+	}
+	if setting, _ := LookupSetting(key); setting.EnvOnly {
+		return "", fmt.Errorf("%s can only be set with a flag or the %s environment variable", key, setting.EnvVar)
+	}
+	return r.update(project, workDir, func(file SettingsFile) {
+		file[key] = value
+	})
+}
+
+// UnsetValue 从配置文件中删除配置项，返回写入的文件路径
+func (r *SettingsResolver) UnsetValue(key string, project bool, workDir string) (string, error) {
+	if _, ok := LookupSetting(key); !ok {
+		return "", ValidateSetting(key, "")
+	}
+	return r.update(project, workDir, func(file SettingsFile) {
+		delete(file, key)
+	})
+}
+
+// update 修改并保存用户或项目配置文件
+func (r *SettingsResolver) update(project bool, workDir string, modify func(SettingsFile)) (string, error) {
+	path := r.userPath
+	file := r.user
+	if project {
+		if r.projectPath == "" {
+			r.projectPath = ProjectConfigPath(workDir)
+		}
+		path = r.projectPath
+		file = r.project
+	}
+	if path == "" {
+		return "", fmt.Errorf("config file location is unknown")
+	}
+
+	modify(file)
+	if err := SaveSettingsFile(path, file); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// SetSettingValidator 设置配置项的校验函数
+//
+// 用于依赖其他包的校验（例如主题名称由ui包管理），避免循环依赖。
+func SetSettingValidator(key string, validate func(value string) error) {
+	for i := range Settings {
+		if Settings[i].Key == key {
+			Settings[i].Validate = validate
+			return
+		}
+	}
+}
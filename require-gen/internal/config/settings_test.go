@@ -0,0 +1,236 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestResolver 使用临时目录中的用户和项目配置以及给定环境变量创建解析器
+func newTestResolver(t *testing.T, user, project SettingsFile, env map[string]string) *SettingsResolver {
+	t.Helper()
+	dir := t.TempDir()
+
+	userPath := filepath.Join(dir, "user", "config.json")
+	projectPath := ProjectConfigPath(filepath.Join(dir, "project"))
+	if user != nil {
+		require.NoError(t, SaveSettingsFile(userPath, user))
+	}
+	if project != nil {
+		require.NoError(t, SaveSettingsFile(projectPath, project))
+	}
+
+	resolver, err := LoadSettingsFrom(userPath, projectPath)
+	require.NoError(t, err)
+	resolver.getenv = func(key string) string { return env[key] }
+	return resolver
+}
+
+// TestSettingsResolver_Precedence 测试标志 > 环境变量 > 项目配置 > 用户配置 > 默认值
+func TestSettingsResolver_Precedence(t *testing.T) {
+	tests := []struct {
+		name       string
+		key        string
+		user       SettingsFile
+		project    SettingsFile
+		env        map[string]string
+		flag       string
+		wantValue  string
+		wantSource SettingSource
+	}{
+		{
+			name:       "default",
+			key:        KeyTimeout,
+			wantValue:  "30s",
+			wantSource: SourceDefault,
+		},
+		{
+			name:       "unset without default",
+			key:        KeyAIAssistant,
+			wantValue:  "",
+			wantSource: SourceDefault,
+		},
+		{
+			name:       "user overrides default",
+			key:        KeyTimeout,
+			user:       SettingsFile{KeyTimeout: "1m"},
+			wantValue:  "1m",
+			wantSource: SourceUser,
+		},
+		{
+			name:       "project overrides user",
+			key:        KeyAIAssistant,
+			user:       SettingsFile{KeyAIAssistant: "copilot"},
+			project:    SettingsFile{KeyAIAssistant: "claude"},
+			wantValue:  "claude",
+			wantSource: SourceProject,
+		},
+		{
+			name:       "env overrides project",
+			key:        KeyAIAssistant,
+			project:    SettingsFile{KeyAIAssistant: "claude"},
+			env:        map[string]string{"SPECIFY_AI": "gemini"},
+			wantValue:  "gemini",
+			wantSource: SourceEnv,
+		},
+		{
+			name:       "flag overrides env",
+			key:        KeyAIAssistant,
+			project:    SettingsFile{KeyAIAssistant: "claude"},
+			env:        map[string]string{"SPECIFY_AI": "gemini"},
+			flag:       "copilot",
+			wantValue:  "copilot",
+			wantSource: SourceFlag,
+		},
+		{
+			name:       "empty values fall through",
+			key:        KeyScriptType,
+			user:       SettingsFile{KeyScriptType: "ps"},
+			project:    SettingsFile{KeyScriptType: ""},
+			env:        map[string]string{"SPECIFY_SCRIPT": ""},
+			wantValue:  "ps",
+			wantSource: SourceUser,
+		},
+		{
+			name:       "env-only setting ignores config files",
+			key:        KeyForce,
+			user:       SettingsFile{KeyForce: "true"},
+			project:    SettingsFile{KeyForce: "true"},
+			wantValue:  "",
+			wantSource: SourceDefault,
+		},
+		{
+			name:       "env-only setting from env",
+			key:        KeyForce,
+			env:        map[string]string{"SPECIFY_FORCE": "true"},
+			wantValue:  "true",
+			wantSource: SourceEnv,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resolver := newTestResolver(t, tt.user, tt.project, tt.env)
+			if tt.flag != "" {
+				resolver.SetFlag(tt.key, "--flag", tt.flag)
+			}
+
+			resolved := resolver.Resolve(tt.key)
+			assert.Equal(t, tt.wantValue, resolved.Value)
+			assert.Equal(t, tt.wantSource, resolved.Source)
+			switch tt.wantSource {
+			case SourceUser:
+				assert.Equal(t, resolver.UserPath(), resolved.Origin)
+			case SourceProject:
+				assert.Equal(t, resolver.ProjectPath(), resolved.Origin)
+			}
+		})
+	}
+}
+
+// TestSettingsResolver_Validate 测试校验错误包含值的来源
+func TestSettingsResolver_Validate(t *testing.T) {
+	resolver := newTestResolver(t, nil, SettingsFile{KeyTimeout: "soon"}, nil)
+	err := resolver.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), resolver.ProjectPath())
+
+	resolver = newTestResolver(t, nil, nil, map[string]string{"SPECIFY_SCRIPT": "fish"})
+	err = resolver.Validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "SPECIFY_SCRIPT")
+}
+
+// TestSettingsResolver_SetUnsetValue 测试写入和删除用户、项目配置
+func TestSettingsResolver_SetUnsetValue(t *testing.T) {
+	dir := t.TempDir()
+	userPath := filepath.Join(dir, "user", "config.json")
+	workDir := filepath.Join(dir, "work")
+
+	resolver, err := LoadSettingsFrom(userPath, "")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		key      string
+		value    string
+		project  bool
+		wantPath string
+		wantErr  bool
+	}{
+		{name: "user", key: KeyAIAssistant, value: "claude", wantPath: userPath},
+		{name: "project", key: KeyScriptType, value: "ps", project: true, wantPath: ProjectConfigPath(workDir)},
+		{name: "invalid value", key: KeyScriptType, value: "fish", wantErr: true},
+		{name: "unknown key", key: "no_such_key", value: "x", wantErr: true},
+		{name: "env-only key", key: KeyGitHubToken, value: "ghp_secret", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path, err := resolver.SetValue(tt.key, tt.value, tt.project, workDir)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantPath, path)
+
+			file, err := LoadSettingsFile(path)
+			require.NoError(t, err)
+			assert.Equal(t, tt.value, file[tt.key])
+		})
+	}
+
+	// 令牌不会写入任何配置文件
+	data, err := os.ReadFile(userPath)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "ghp_secret")
+
+	// 删除后回退到更低优先级的层
+	resolver, err = LoadSettingsFrom(userPath, ProjectConfigPath(workDir))
+	require.NoError(t, err)
+	_, err = resolver.SetValue(KeyAIAssistant, "gemini", true, workDir)
+	require.NoError(t, err)
+	assert.Equal(t, "gemini", resolver.Value(KeyAIAssistant))
+
+	path, err := resolver.UnsetValue(KeyAIAssistant, true, workDir)
+	require.NoError(t, err)
+	assert.Equal(t, ProjectConfigPath(workDir), path)
+	resolved := resolver.Resolve(KeyAIAssistant)
+	assert.Equal(t, "claude", resolved.Value)
+	assert.Equal(t, SourceUser, resolved.Source)
+
+	_, err = resolver.UnsetValue("no_such_key", false, workDir)
+	assert.Error(t, err)
+}
+
+// TestFindProjectConfig 测试从子目录向上查找项目配置
+func TestFindProjectConfig(t *testing.T) {
+	root := t.TempDir()
+	project := filepath.Join(root, "project")
+	nested := filepath.Join(project, "src", "pkg")
+	require.NoError(t, os.MkdirAll(nested, 0755))
+	require.NoError(t, SaveSettingsFile(ProjectConfigPath(project), SettingsFile{KeyAIAssistant: "claude"}))
+
+	// .specify/config.json 为目录时不算项目配置
+	outside := filepath.Join(root, "other")
+	require.NoError(t, os.MkdirAll(ProjectConfigPath(outside), 0755))
+
+	tests := []struct {
+		name  string
+		start string
+		want  string
+	}{
+		{name: "project root", start: project, want: ProjectConfigPath(project)},
+		{name: "nested directory", start: nested, want: ProjectConfigPath(project)},
+		{name: "config path is a directory", start: outside, want: ""},
+		{name: "no project", start: root, want: ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, FindProjectConfig(tt.start))
+		})
+	}
+}
@@ -1,11 +1,13 @@
 package infrastructure
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"os"
 	"strings"
+	"sync"
 	"time"
 
 	"specify-cli/internal/types"
@@ -128,7 +130,7 @@ func NewTokenFormatError(details string) *AuthError {
 		Type:       ErrorTypeTokenFormat,
 		Message:    "Token format is invalid",
 		Cause:      details,
-		Suggestion: "GitHub tokens should be 40 characters long and contain only alphanumeric characters and underscores",
+		Suggestion: "GitHub tokens start with ghp_, github_pat_, gho_, ghu_ or ghs_ and contain only letters, digits and underscores",
 	}
 }
 
@@ -170,8 +172,13 @@ func NewFileError(operation, path string, err error) *AuthError {
 
 // AuthProvider 认证提供者实现
 type AuthProvider struct {
-	token    string
-	cliToken string // CLI参数传入的令牌
+	token      string
+	cliToken   string            // CLI参数传入的令牌
+	apiBaseURL string            // GitHub API基础URL，空表示api.github.com
+	transport  http.RoundTripper // API请求使用的传输层，nil表示默认传输层
+
+	credentialOnce sync.Once // 外部凭据来源只查询一次
+	credential     TokenInfo // gh、git凭据助手或netrc中的令牌
 }
 
 // TokenSource 令牌来源类型
@@ -182,6 +189,10 @@ const (
 	TokenSourceGHToken
 	TokenSourceGitHubToken
 	TokenSourceFile
+	TokenSourceStored
+	TokenSourceGHCLI
+	TokenSourceGitCredential
+	TokenSourceNetrc
 	TokenSourceNone
 )
 
@@ -211,6 +222,37 @@ func NewAuthProvider() types.AuthProvider {
 	return &AuthProvider{}
 }
 
+// NewAuthProviderWithConfig 使用网络配置创建认证提供者实例
+//
+// 令牌验证和用户信息请求发送到networkConfig.APIBaseURL（GitHub Enterprise
+// 为 https://<host>/api/v3），并使用其中的代理和TLS证书配置。
+// 代理或证书配置无效时使用默认传输层（命令行会事先校验）。
+func NewAuthProviderWithConfig(networkConfig *types.NetworkConfig) types.AuthProvider {
+	ap := &AuthProvider{}
+	if networkConfig == nil {
+		return ap
+	}
+
+	ap.apiBaseURL = networkConfig.APIBaseURL
+	if transport, err := newNetworkTransport(networkConfig); err == nil {
+		ap.transport = transport
+	}
+	return ap
+}
+
+// apiURL 拼接GitHub API请求地址
+func (ap *AuthProvider) apiURL(path string) string {
+	return apiBaseURL(&types.NetworkConfig{APIBaseURL: ap.apiBaseURL}) + path
+}
+
+// httpClient 创建API请求使用的HTTP客户端
+func (ap *AuthProvider) httpClient() *http.Client {
+	return &http.Client{
+		Timeout:   10 * time.Second,
+		Transport: ap.transport,
+	}
+}
+
 // ValidateAndSetToken 验证并设置令牌
 //
 // 该方法结合了令牌验证和设置功能，确保只有有效的令牌被设置。
@@ -307,27 +349,76 @@ func (ap *AuthProvider) SetCLIToken(token string) {
 
 // GetToken 获取认证令牌（按优先级顺序）
 func (ap *AuthProvider) GetToken() string {
+	token, _ := ap.resolveToken()
+	return token
+}
+
+// ExplicitToken 获取显式指定的令牌（CLI参数、手动设置或环境变量）
+//
+// 不查询外部凭据来源，没有显式令牌时返回空字符串。
+func (ap *AuthProvider) ExplicitToken() string {
+	token, _ := ap.explicitToken()
+	return token
+}
+
+// CredentialToken 获取外部凭据来源中的令牌
+//
+// 依次查询specify auth login保存的令牌、gh auth token、git凭据助手和~/.netrc。
+func (ap *AuthProvider) CredentialToken() string {
+	return ap.externalCredential().Token
+}
+
+// resolveToken 按优先级确定令牌及其来源
+func (ap *AuthProvider) resolveToken() (string, TokenSource) {
+	if token, source := ap.explicitToken(); token != "" {
+		return token, source
+	}
+
+	// 5. specify auth login保存的令牌、gh auth token、git凭据助手、~/.netrc
+	credential := ap.externalCredential()
+	return credential.Token, credential.Source
+}
+
+// explicitToken 按优先级确定显式指定的令牌及其来源
+func (ap *AuthProvider) explicitToken() (string, TokenSource) {
 	// 1. CLI参数令牌（最高优先级）
 	if ap.cliToken != "" {
-		return ap.cliToken
+		return ap.cliToken, TokenSourceCLI
 	}
 
 	// 2. 手动设置的令牌
 	if ap.token != "" {
-		return ap.token
+		return ap.token, TokenSourceFile
 	}
 
 	// 3. GH_TOKEN环境变量（GitHub CLI标准）
 	if token := strings.TrimSpace(os.Getenv("GH_TOKEN")); token != "" {
-		return token
+		return token, TokenSourceGHToken
 	}
 
 	// 4. GITHUB_TOKEN环境变量（GitHub Actions标准）
 	if token := strings.TrimSpace(os.Getenv("GITHUB_TOKEN")); token != "" {
-		return token
+		return token, TokenSourceGitHubToken
 	}
 
-	return ""
+	return "", TokenSourceNone
+}
+
+// externalCredential 从外部凭据来源查找API主机的令牌
+//
+// 查询需要启动子进程，结果在实例内缓存。
+func (ap *AuthProvider) externalCredential() TokenInfo {
+	ap.credentialOnce.Do(func() {
+		ap.credential.Source = TokenSourceNone
+		host := APIHost(&types.NetworkConfig{APIBaseURL: ap.apiBaseURL})
+		for _, c := range credentialLookups {
+			if token := c.lookup(context.Background(), host); token != "" {
+				ap.credential = TokenInfo{Token: token, Source: c.source}
+				return
+			}
+		}
+	})
+	return ap.credential
 }
 
 // GetTokenInfo 获取详细的令牌信息
@@ -348,21 +439,7 @@ func (ap *AuthProvider) GetTokenInfo() *TokenInfo {
 	info := &TokenInfo{}
 
 	// 按优先级检查令牌来源
-	if ap.cliToken != "" {
-		info.Token = ap.cliToken
-		info.Source = TokenSourceCLI
-	} else if ap.token != "" {
-		info.Token = ap.token
-		info.Source = TokenSourceFile
-	} else if token := strings.TrimSpace(os.Getenv("GH_TOKEN")); token != "" {
-		info.Token = token
-		info.Source = TokenSourceGHToken
-	} else if token := strings.TrimSpace(os.Getenv("GITHUB_TOKEN")); token != "" {
-		info.Token = token
-		info.Source = TokenSourceGitHubToken
-	} else {
-		info.Source = TokenSourceNone
-	}
+	info.Token, info.Source = ap.resolveToken()
 
 	// 设置令牌有效性
 	if info.Token != "" {
@@ -429,10 +506,24 @@ func (ap *AuthProvider) ValidateToken() error {
 }
 
 // validateTokenFormat 验证令牌格式
+//
+// 识别GitHub的令牌前缀（ghp_、github_pat_、gho_、ghu_、ghs_）和旧式40位十六进制令牌；
+// 刷新令牌（ghr_）不能用于API请求。其他格式（如GitHub Enterprise或代理签发的令牌）
+// 只检查长度和字符集。
 func (ap *AuthProvider) validateTokenFormat(token string) error {
-	// GitHub Personal Access Token 格式验证
-	if len(token) < 20 {
-		return NewTokenFormatError("token too short (minimum 20 characters)")
+	tokenType := DetectTokenType(token)
+	switch tokenType {
+	case TokenTypeRefresh:
+		return NewTokenFormatError("GitHub App refresh tokens (ghr_) cannot be used for API requests")
+	case TokenTypeUnknown:
+		if len(token) < 20 {
+			return NewTokenFormatError("token too short (minimum 20 characters)")
+		}
+	case TokenTypeLegacy:
+	default:
+		if len(token) == len(tokenPrefix(tokenType)) {
+			return NewTokenFormatError(fmt.Sprintf("%s is missing the part after the prefix", tokenType))
+		}
 	}
 
 	// 检查是否包含非法字符
@@ -450,81 +541,28 @@ func (ap *AuthProvider) validateTokenFormat(token string) error {
 
 // validateTokenWithAPI 通过GitHub API验证令牌
 func (ap *AuthProvider) validateTokenWithAPI(token string) error {
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-	}
-
-	req, err := http.NewRequest("GET", "https://api.github.com/user", nil)
-	if err != nil {
-		return NewNetworkError(fmt.Errorf("failed to create validation request: %w", err))
-	}
-
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
-	req.Header.Set("User-Agent", "Specify-CLI/1.0.0")
-	req.Header.Set("Accept", "application/vnd.github.v3+json")
-
-	resp, err := client.Do(req)
-	if err != nil {
-		return NewNetworkError(fmt.Errorf("failed to validate token: %w", err))
-	}
-	defer resp.Body.Close()
-
-	switch resp.StatusCode {
-	case 200:
-		return nil
-	case 401:
-		return NewTokenInvalidError("invalid or expired token")
-	case 403:
-		return NewPermissionDeniedError("user information access")
-	default:
-		return NewAPIError(resp.StatusCode, fmt.Sprintf("token validation failed with status %d", resp.StatusCode))
-	}
+	_, err := ap.fetchTokenDetails(token)
+	return err
 }
 
 // GetTokenScopes 获取令牌权限范围
+//
+// 细粒度个人访问令牌和GitHub App令牌没有scope，返回空列表；
+// 它们的权限见GetTokenDetails。
 func (ap *AuthProvider) GetTokenScopes() ([]string, error) {
 	token := ap.GetToken()
 	if token == "" {
 		return nil, NewTokenNotFoundError(ap.GetTokenSource())
 	}
 
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-	}
-
-	req, err := http.NewRequest("GET", "https://api.github.com/user", nil)
-	if err != nil {
-		return nil, NewNetworkError(fmt.Errorf("failed to create request: %w", err))
-	}
-
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
-	req.Header.Set("User-Agent", "Specify-CLI/1.0.0")
-	req.Header.Set("Accept", "application/vnd.github.v3+json")
-
-	resp, err := client.Do(req)
+	details, err := ap.fetchTokenDetails(token)
 	if err != nil {
-		return nil, NewNetworkError(fmt.Errorf("failed to get token scopes: %w", err))
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		switch resp.StatusCode {
-		case 401:
-			return nil, NewTokenInvalidError("token is invalid or expired")
-		case 403:
-			return nil, NewPermissionDeniedError("token scope information")
-		default:
-			return nil, NewAPIError(resp.StatusCode, fmt.Sprintf("failed to get scopes with status %d", resp.StatusCode))
-		}
+		return nil, err
 	}
-
-	// 从响应头获取权限范围
-	scopes := resp.Header.Get("X-OAuth-Scopes")
-	if scopes == "" {
+	if details.Scopes == nil {
 		return []string{}, nil
 	}
-
-	return strings.Split(scopes, ", "), nil
+	return details.Scopes, nil
 }
 
 // GetAuthType 获取认证类型
@@ -590,11 +628,9 @@ func (ap *AuthProvider) GetUserInfo() (map[string]interface{}, error) {
 		return nil, NewTokenNotFoundError(ap.GetTokenSource())
 	}
 
-	client := &http.Client{
-		Timeout: 10 * time.Second,
-	}
+	client := ap.httpClient()
 
-	req, err := http.NewRequest("GET", "https://api.github.com/user", nil)
+	req, err := http.NewRequest("GET", ap.apiURL("/user"), nil)
 	if err != nil {
 		return nil, NewNetworkError(fmt.Errorf("failed to create request: %w", err))
 	}
@@ -629,14 +665,19 @@ func (ap *AuthProvider) GetUserInfo() (map[string]interface{}, error) {
 }
 
 // IsTokenExpired 检查令牌是否过期
+//
+// 优先使用GitHub-Authentication-Token-Expiration响应头中的过期时间；
+// 没有该头时，被拒绝（401）的令牌视为可能已过期。
 func (ap *AuthProvider) IsTokenExpired() (bool, error) {
 	token := ap.GetToken()
 	if token == "" {
 		return true, NewTokenNotFoundError(ap.GetTokenSource())
 	}
 
-	// 通过调用API来检查令牌是否有效
-	err := ap.validateTokenWithAPI(token)
+	details, err := ap.fetchTokenDetails(token)
+	if details != nil && details.Expired() {
+		return true, nil
+	}
 	if err != nil {
 		// 检查是否是令牌过期错误
 		if authErr, ok := err.(*AuthError); ok {
@@ -656,9 +697,16 @@ func (ap *AuthProvider) IsTokenExpired() (bool, error) {
 }
 
 // GetTokenSource 获取当前令牌来源的字符串描述
+//
+// 只确定来源，不通过API验证令牌。
 func (ap *AuthProvider) GetTokenSource() string {
-	info := ap.GetTokenInfo()
-	switch info.Source {
+	_, source := ap.resolveToken()
+	return source.String()
+}
+
+// String 返回令牌来源的描述
+func (s TokenSource) String() string {
+	switch s {
 	case TokenSourceCLI:
 		return "CLI argument"
 	case TokenSourceGHToken:
@@ -667,6 +715,14 @@ func (ap *AuthProvider) GetTokenSource() string {
 		return "GITHUB_TOKEN environment variable"
 	case TokenSourceFile:
 		return "manually set token"
+	case TokenSourceStored:
+		return "specify auth login"
+	case TokenSourceGHCLI:
+		return "GitHub CLI (gh auth token)"
+	case TokenSourceGitCredential:
+		return "git credential helper"
+	case TokenSourceNetrc:
+		return "netrc file"
 	default:
 		return "none"
 	}
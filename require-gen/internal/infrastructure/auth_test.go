@@ -0,0 +1,256 @@
+package infrastructure
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/zalando/go-keyring"
+	"specify-cli/internal/types"
+)
+
+// TestAuthProvider_EnterpriseAPI 测试令牌相关请求发送到配置的API地址
+func TestAuthProvider_EnterpriseAPI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/user" || r.Header.Get("Authorization") != "Bearer ghe_token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("X-OAuth-Scopes", "repo, read:org")
+		w.Write([]byte(`{"login":"octocat"}`))
+	}))
+	defer server.Close()
+
+	auth := NewAuthProviderWithConfig(&types.NetworkConfig{APIBaseURL: server.URL + "/api/v3"})
+	auth.SetToken("ghe_token")
+
+	userInfo, err := auth.GetUserInfo()
+	require.NoError(t, err)
+	assert.Equal(t, "octocat", userInfo["login"])
+
+	scopes, err := auth.GetTokenScopes()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"repo", "read:org"}, scopes)
+
+	expired, err := auth.IsTokenExpired()
+	require.NoError(t, err)
+	assert.False(t, expired)
+}
+
+// TestParseNetrc 测试netrc解析
+func TestParseNetrc(t *testing.T) {
+	content := `# comment
+machine example.com login user password other
+machine github.com
+  login octocat
+  password ghp_netrc
+
+macdef init
+password not-a-token
+
+default login anonymous password fallback
+`
+	assert.Equal(t, "ghp_netrc", parseNetrc(content, "github.com"))
+	assert.Equal(t, "other", parseNetrc(content, "EXAMPLE.com"))
+	assert.Equal(t, "fallback", parseNetrc(content, "ghe.example.com"))
+	assert.Equal(t, "", parseNetrc("machine github.com login octocat", "github.com"))
+}
+
+// isolateCredentials 清空测试中可见的凭据来源
+//
+// 使用模拟的密钥环，清空PATH（不会调用gh和git）和令牌环境变量，
+// netrc和配置目录指向临时位置。
+func isolateCredentials(t *testing.T) {
+	t.Helper()
+	keyring.MockInit()
+	t.Setenv("GH_TOKEN", "")
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("PATH", "")
+	t.Setenv("NETRC", filepath.Join(t.TempDir(), "netrc"))
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("APPDATA", t.TempDir())
+}
+
+// TestAuthProvider_ExternalCredentials 测试环境变量中没有令牌时使用gh和netrc中的令牌
+func TestAuthProvider_ExternalCredentials(t *testing.T) {
+	t.Setenv("GH_TOKEN", "")
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("APPDATA", t.TempDir())
+	keyring.MockInit()
+	binDir := t.TempDir()
+	t.Setenv("PATH", binDir)
+
+	netrc := filepath.Join(t.TempDir(), "netrc")
+	require.NoError(t, os.WriteFile(netrc, []byte("machine ghe.example.com login octocat password ghe_netrc_token\n"), 0600))
+	t.Setenv("NETRC", netrc)
+
+	auth := NewAuthProviderWithConfig(&types.NetworkConfig{APIBaseURL: "https://ghe.example.com/api/v3"})
+	assert.Equal(t, "ghe_netrc_token", auth.GetToken())
+	assert.Equal(t, "netrc file", auth.GetTokenSource())
+
+	// 其他主机没有凭据
+	auth = NewAuthProviderWithConfig(nil)
+	assert.Equal(t, "", auth.GetToken())
+	assert.Equal(t, "none", auth.GetTokenSource())
+
+	if runtime.GOOS == "windows" {
+		return
+	}
+
+	// gh优先于netrc
+	script := "#!/bin/sh\n[ \"$3 $4\" = \"--hostname ghe.example.com\" ] && echo gho_cli_token\n"
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, "gh"), []byte(script), 0755))
+	auth = NewAuthProviderWithConfig(&types.NetworkConfig{APIBaseURL: "https://ghe.example.com/api/v3"})
+	assert.Equal(t, "gho_cli_token", auth.GetToken())
+	assert.Equal(t, "GitHub CLI (gh auth token)", auth.GetTokenSource())
+
+	// 显式设置的令牌优先
+	auth.SetCLIToken("cli_token")
+	assert.Equal(t, "CLI argument", auth.GetTokenSource())
+}
+
+// TestTokenStore 测试auth login保存的令牌的读写和优先级
+func TestTokenStore(t *testing.T) {
+	t.Setenv("GH_TOKEN", "")
+	t.Setenv("GITHUB_TOKEN", "")
+	t.Setenv("PATH", t.TempDir())
+	t.Setenv("NETRC", filepath.Join(t.TempDir(), "missing"))
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("APPDATA", t.TempDir())
+	keyring.MockInit()
+
+	// 文件存储：仅所有者可读写
+	fileStore, err := NewTokenStore(true)
+	require.NoError(t, err)
+	require.NoError(t, fileStore.Set("ghe.example.com", "ghe_file_token"))
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(fileStore.(*fileTokenStore).path("ghe.example.com"))
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	}
+
+	auth := NewAuthProviderWithConfig(&types.NetworkConfig{APIBaseURL: "https://ghe.example.com/api/v3"})
+	assert.Equal(t, "ghe_file_token", auth.GetToken())
+	assert.Equal(t, "specify auth login", auth.GetTokenSource())
+
+	// 密钥环可用时优先使用密钥环
+	keyringStore, err := NewTokenStore(false)
+	require.NoError(t, err)
+	assert.Equal(t, "system keyring", keyringStore.Name())
+	require.NoError(t, keyringStore.Set("ghe.example.com", "ghe_keyring_token"))
+	token, store, err := StoredToken("ghe.example.com")
+	require.NoError(t, err)
+	assert.Equal(t, "ghe_keyring_token", token)
+	assert.Equal(t, "system keyring", store.Name())
+
+	// 环境变量优先于保存的令牌
+	t.Setenv("GH_TOKEN", "env_token")
+	assert.Equal(t, "env_token", NewAuthProviderWithConfig(&types.NetworkConfig{APIBaseURL: "https://ghe.example.com/api/v3"}).GetToken())
+
+	deleted, err := DeleteStoredToken("ghe.example.com")
+	require.NoError(t, err)
+	assert.Len(t, deleted, 2)
+	_, _, err = StoredToken("ghe.example.com")
+	assert.ErrorIs(t, err, ErrTokenNotStored)
+	_, err = DeleteStoredToken("ghe.example.com")
+	assert.ErrorIs(t, err, ErrTokenNotStored)
+}
+
+// TestDetectTokenType 测试令牌前缀识别和格式验证
+func TestDetectTokenType(t *testing.T) {
+	ap := &AuthProvider{}
+	tests := []struct {
+		token     string
+		tokenType TokenType
+		valid     bool
+	}{
+		{"ghp_" + strings.Repeat("a", 36), TokenTypeClassic, true},
+		{"github_pat_11ABCDEFG0_" + strings.Repeat("x", 59), TokenTypeFineGrained, true},
+		{"gho_" + strings.Repeat("b", 36), TokenTypeOAuth, true},
+		{"ghu_" + strings.Repeat("c", 36), TokenTypeUserToServer, true},
+		{"ghs_" + strings.Repeat("d", 36), TokenTypeInstallation, true},
+		{"ghr_" + strings.Repeat("e", 76), TokenTypeRefresh, false},
+		{strings.Repeat("0f", 20), TokenTypeLegacy, true},
+		{"ghs_", TokenTypeInstallation, false},
+		{"custom_enterprise_token_value", TokenTypeUnknown, true},
+		{"short", TokenTypeUnknown, false},
+		{"ghp_has-dash" + strings.Repeat("a", 30), TokenTypeClassic, false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.tokenType, DetectTokenType(tt.token), tt.token)
+		assert.Equal(t, tt.valid, ap.validateTokenFormat(tt.token) == nil, tt.token)
+	}
+}
+
+// TestAuthProvider_TokenDetails 测试细粒度令牌和安装令牌的权限与过期时间
+func TestAuthProvider_TokenDetails(t *testing.T) {
+	fineGrained := "github_pat_" + strings.Repeat("f", 40)
+	installation := "ghs_" + strings.Repeat("i", 36)
+	expired := "ghp_" + strings.Repeat("e", 36)
+	expiresAt := time.Now().Add(48 * time.Hour).UTC().Truncate(time.Second)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Header.Get("Authorization") {
+		case "Bearer " + fineGrained:
+			require.Equal(t, "/user", r.URL.Path)
+			w.Header().Set("GitHub-Authentication-Token-Expiration", expiresAt.Format("2006-01-02 15:04:05 MST"))
+			w.Write([]byte(`{"login":"octocat"}`))
+		case "Bearer " + installation:
+			require.Equal(t, "/installation/repositories", r.URL.Path)
+			w.Header().Set("GitHub-Authentication-Token-Expiration", expiresAt.Format("2006-01-02 15:04:05 -0700"))
+			w.Write([]byte(`{"total_count":2,"repositories":[` +
+				`{"permissions":{"admin":false,"push":false,"pull":true}},` +
+				`{"permissions":{"admin":false,"push":true,"pull":true}}]}`))
+		case "Bearer " + expired:
+			w.Header().Set("GitHub-Authentication-Token-Expiration", "2020-01-01 00:00:00 UTC")
+			w.Header().Set("X-OAuth-Scopes", "repo")
+			w.Write([]byte(`{"login":"octocat"}`))
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	newAuth := func(token string) types.AuthProvider {
+		auth := NewAuthProviderWithConfig(&types.NetworkConfig{APIBaseURL: server.URL})
+		auth.SetCLIToken(token)
+		return auth
+	}
+
+	details, err := newAuth(fineGrained).GetTokenDetails()
+	require.NoError(t, err)
+	assert.Equal(t, string(TokenTypeFineGrained), details.Type)
+	assert.Equal(t, "octocat", details.Login)
+	assert.Empty(t, details.Scopes)
+	require.NotNil(t, details.ExpiresAt)
+	assert.True(t, expiresAt.Equal(*details.ExpiresAt))
+	scopes, err := newAuth(fineGrained).GetTokenScopes()
+	require.NoError(t, err)
+	assert.Empty(t, scopes)
+
+	auth := newAuth(installation)
+	require.NoError(t, auth.ValidateToken())
+	details, err = auth.GetTokenDetails()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]bool{"admin": false, "push": true, "pull": true}, details.Permissions)
+	expiredNow, err := auth.IsTokenExpired()
+	require.NoError(t, err)
+	assert.False(t, expiredNow)
+
+	auth = newAuth(expired)
+	expiredNow, err = auth.IsTokenExpired()
+	require.NoError(t, err)
+	assert.True(t, expiredNow)
+	_, err = auth.GetTokenDetails()
+	var authErr *AuthError
+	require.ErrorAs(t, err, &authErr)
+	assert.Equal(t, ErrorTypeTokenExpired, authErr.Type)
+}
@@ -0,0 +1,150 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// minBandwidthBurst 限速器的最小突发字节数，避免低速率时每次读取只有几个字节
+const minBandwidthBurst = 4 * 1024
+
+// BandwidthLimiter 令牌桶下载限速器
+//
+// 同一次下载的所有连接（分段并发下载的各分段、流式下载的各分块）共享一个限速器，
+// 合计速率不超过设定值。nil限速器表示不限速。
+type BandwidthLimiter struct {
+	mu     sync.Mutex
+	rate   float64 // 每秒字节数
+	burst  int
+	tokens float64
+	last   time.Time
+}
+
+// NewBandwidthLimiter 创建每秒最多bytesPerSecond字节的限速器，bytesPerSecond不大于0时返回nil
+func NewBandwidthLimiter(bytesPerSecond int64) *BandwidthLimiter {
+	if bytesPerSecond <= 0 {
+		return nil
+	}
+
+	// 突发量为0.1秒的流量，保证速率平稳
+	burst := int(bytesPerSecond / 10)
+	if burst < minBandwidthBurst {
+		burst = minBandwidthBurst
+	}
+	return &BandwidthLimiter{
+		rate:   float64(bytesPerSecond),
+		burst:  burst,
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Rate 每秒允许的字节数
+func (bl *BandwidthLimiter) Rate() int64 {
+	if bl == nil {
+		return 0
+	}
+	return int64(bl.rate)
+}
+
+// WaitN 消耗n个令牌，令牌不足时等待补充或ctx取消
+//
+// 令牌先预留再等待，并发调用按顺序排队，不会因竞争而超出速率。
+func (bl *BandwidthLimiter) WaitN(ctx context.Context, n int) error {
+	if bl == nil || n <= 0 {
+		return nil
+	}
+
+	bl.mu.Lock()
+	now := time.Now()
+	bl.tokens += now.Sub(bl.last).Seconds() * bl.rate
+	if bl.tokens > float64(bl.burst) {
+		bl.tokens = float64(bl.burst)
+	}
+	bl.last = now
+	bl.tokens -= float64(n)
+	var wait time.Duration
+	if bl.tokens < 0 {
+		wait = time.Duration(-bl.tokens / bl.rate * float64(time.Second))
+	}
+	bl.mu.Unlock()
+
+	if wait == 0 {
+		return nil
+	}
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Reader 返回按限速器读取r的读取器，限速器为nil时直接返回r
+func (bl *BandwidthLimiter) Reader(ctx context.Context, r io.Reader) io.Reader {
+	if bl == nil {
+		return r
+	}
+	return &rateLimitedReader{ctx: ctx, reader: r, limiter: bl}
+}
+
+// rateLimitedReader 限速读取器
+type rateLimitedReader struct {
+	ctx     context.Context
+	reader  io.Reader
+	limiter *BandwidthLimiter
+}
+
+// Read 读取数据后等待令牌，单次读取不超过限速器的突发量
+func (r *rateLimitedReader) Read(p []byte) (int, error) {
+	if len(p) > r.limiter.burst {
+		p = p[:r.limiter.burst]
+	}
+	n, err := r.reader.Read(p)
+	if waitErr := r.limiter.WaitN(r.ctx, n); waitErr != nil {
+		return n, waitErr
+	}
+	return n, err
+}
+
+// ParseRate 解析下载限速，例如 "500k"、"1.5M"、"2g" 或字节数 "100000"
+//
+// 单位与curl的--limit-rate一致（k=1024字节），空字符串和0表示不限速。
+func ParseRate(value string) (int64, error) {
+	s := strings.ToLower(strings.TrimSpace(value))
+	if s == "" {
+		return 0, nil
+	}
+
+	multiplier := 1.0
+	switch s[len(s)-1] {
+	case 'k':
+		multiplier = 1024
+	case 'm':
+		multiplier = 1024 * 1024
+	case 'g':
+		multiplier = 1024 * 1024 * 1024
+	}
+	if multiplier > 1 {
+		s = s[:len(s)-1]
+	}
+
+	n, err := strconv.ParseFloat(s, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid rate %q (expected bytes per second, e.g. 500k or 2M)", value)
+	}
+	return int64(n * multiplier), nil
+}
+
+// ValidateRate 校验下载限速的格式
+func ValidateRate(value string) error {
+	_, err := ParseRate(value)
+	return err
+}
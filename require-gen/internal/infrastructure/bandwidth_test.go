@@ -0,0 +1,132 @@
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"specify-cli/internal/types"
+)
+
+// TestParseRate 测试下载限速解析
+func TestParseRate(t *testing.T) {
+	tests := []struct {
+		value string
+		want  int64
+	}{
+		{"", 0},
+		{"0", 0},
+		{"100000", 100000},
+		{"500k", 500 * 1024},
+		{"500K", 500 * 1024},
+		{"1.5M", 1536 * 1024},
+		{"2g", 2 * 1024 * 1024 * 1024},
+	}
+	for _, tt := range tests {
+		got, err := ParseRate(tt.value)
+		require.NoError(t, err, tt.value)
+		assert.Equal(t, tt.want, got, tt.value)
+	}
+
+	for _, value := range []string{"fast", "500kb", "-1k", "k"} {
+		assert.Error(t, ValidateRate(value), value)
+	}
+}
+
+// TestBandwidthLimiter 测试多个读取器共享限速器时的合计速率
+func TestBandwidthLimiter(t *testing.T) {
+	assert.Nil(t, NewBandwidthLimiter(0))
+	var nilLimiter *BandwidthLimiter
+	r := bytes.NewReader([]byte("data"))
+	assert.Same(t, r, nilLimiter.Reader(context.Background(), r))
+
+	const rate = 100 * 1024
+	limiter := NewBandwidthLimiter(rate)
+	start := time.Now()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n, err := io.Copy(io.Discard, limiter.Reader(context.Background(), bytes.NewReader(make([]byte, 20*1024))))
+			assert.NoError(t, err)
+			assert.Equal(t, int64(20*1024), n)
+		}()
+	}
+	wg.Wait()
+
+	// 60KB减去初始突发量，按100KB/s至少需要约0.5秒
+	elapsed := time.Since(start)
+	assert.Greater(t, elapsed, 400*time.Millisecond)
+	assert.Less(t, elapsed, 2*time.Second)
+
+	// 取消时立即返回
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := io.Copy(io.Discard, NewBandwidthLimiter(1024).Reader(ctx, bytes.NewReader(make([]byte, 64*1024))))
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+// TestStreamingDownloader_LimitRate 测试流式下载按LimitRate限速
+func TestStreamingDownloader_LimitRate(t *testing.T) {
+	content := bytes.Repeat([]byte("specify"), 10*1024)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "template.zip", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+
+	downloader := NewStreamingDownloader(server.Client(), 16*1024)
+	dest := filepath.Join(t.TempDir(), "template.zip")
+	start := time.Now()
+
+	require.NoError(t, downloader.DownloadWithStreaming(context.Background(), server.URL, dest, &types.DownloadOptions{LimitRate: 128 * 1024}))
+	assert.Greater(t, time.Since(start), 300*time.Millisecond)
+
+	data, err := os.ReadFile(dest)
+	require.NoError(t, err)
+	assert.Equal(t, content, data)
+}
+
+// TestTemplateProvider_SharedLimiter 测试下载和镜像重试使用Download创建的同一个限速器
+func TestTemplateProvider_SharedLimiter(t *testing.T) {
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "blocked", http.StatusForbidden)
+	}))
+	defer origin.Close()
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("template"))
+	}))
+	defer mirror.Close()
+
+	tp := NewTemplateProviderWithConfig(nil, nil).(*TemplateProvider)
+	asset := &types.Asset{Name: "template.zip", BrowserDownloadURL: origin.URL + "/o/r/releases/download/v1.0/template.zip"}
+	sources := downloadSources(asset.BrowserDownloadURL, nil, asset, []string{mirror.URL})
+
+	for _, opts := range []types.DownloadOptions{
+		{LimitRate: 64 * 1024},
+		{LimitRate: 64 * 1024, ChunkSize: 1024},
+	} {
+		// 限速器已欠下约0.5秒的配额：每次下载单独创建限速器时不会等待
+		limiter := NewBandwidthLimiter(opts.LimitRate)
+		limiter.tokens = -32 * 1024
+		dest := filepath.Join(t.TempDir(), "template.zip")
+		start := time.Now()
+
+		require.NoError(t, tp.downloadWithEnhancedProgress(context.Background(), sources, dest, 8, opts, limiter))
+		assert.Greater(t, time.Since(start), 400*time.Millisecond, "chunk size %d", opts.ChunkSize)
+
+		data, err := os.ReadFile(dest)
+		require.NoError(t, err)
+		assert.Equal(t, "template", string(data))
+	}
+}
@@ -0,0 +1,436 @@
+package infrastructure
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/ssh"
+	"specify-cli/internal/types"
+)
+
+// checksumManifestNames 发布中校验和清单的文件名（按优先级）
+var checksumManifestNames = []string{"SHA256SUMS", "SHA256SUMS.txt", "sha256sums.txt"}
+
+// 签名文件扩展名：minisign签名和ssh-keygen -Y sign生成的SSH签名
+const (
+	minisignSignatureExt = ".minisig"
+	sshSignatureExt      = ".sig"
+)
+
+// sshSignatureNamespace SSH签名的命名空间（ssh-keygen -Y sign -n file）
+const sshSignatureNamespace = "file"
+
+// ParseChecksum 解析 --checksum 的值（sha256:<十六进制摘要>）
+func ParseChecksum(spec string) (algorithm, sum string, err error) {
+	algorithm, sum, ok := strings.Cut(strings.TrimSpace(spec), ":")
+	if !ok {
+		return "", "", fmt.Errorf("invalid checksum '%s': expected sha256:<hex digest>", spec)
+	}
+	algorithm = strings.ToLower(algorithm)
+	if algorithm != "sha256" {
+		return "", "", fmt.Errorf("unsupported checksum algorithm '%s': only sha256 is supported", algorithm)
+	}
+	sum = strings.ToLower(sum)
+	if decoded, err := hex.DecodeString(sum); err != nil || len(decoded) != sha256.Size {
+		return "", "", fmt.Errorf("invalid sha256 checksum '%s': expected %d hex characters", sum, sha256.Size*2)
+	}
+	return algorithm, sum, nil
+}
+
+// ValidateChecksum 校验 --checksum 的格式
+func ValidateChecksum(spec string) error {
+	_, _, err := ParseChecksum(spec)
+	return err
+}
+
+// parseChecksumManifest 解析sha256sum格式的清单（"<摘要>  <文件名>"或"<摘要> *<文件名>"）
+func parseChecksumManifest(data []byte) map[string]string {
+	sums := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		name := strings.TrimPrefix(strings.TrimPrefix(fields[1], "*"), "./")
+		sums[name] = strings.ToLower(fields[0])
+	}
+	return sums
+}
+
+// fileSHA256 计算文件的SHA-256摘要
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// LoadSigningKey 读取签名公钥
+//
+// value可以是公钥本身（minisign公钥或"ssh-ed25519 AAAA..."格式的SSH公钥），
+// 也可以是包含公钥的文件路径（minisign.pub或id_ed25519.pub）。
+func LoadSigningKey(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return "", nil
+	}
+	if data, err := os.ReadFile(value); err == nil {
+		value = strings.TrimSpace(string(data))
+	}
+
+	if isSSHKey(value) {
+		if _, _, _, _, err := ssh.ParseAuthorizedKey([]byte(value)); err != nil {
+			return "", fmt.Errorf("invalid SSH public key: %w", err)
+		}
+	} else if _, err := parseMinisignPublicKey(value); err != nil {
+		return "", err
+	}
+	return value, nil
+}
+
+// ValidateSigningKey 校验签名公钥（或公钥文件）
+func ValidateSigningKey(value string) error {
+	_, err := LoadSigningKey(value)
+	return err
+}
+
+// isSSHKey 判断公钥是否为SSH格式
+func isSSHKey(key string) bool {
+	return strings.HasPrefix(key, "ssh-") || strings.HasPrefix(key, "ecdsa-") || strings.HasPrefix(key, "sk-")
+}
+
+// signatureExt 公钥对应的签名文件扩展名
+func signatureExt(key string) string {
+	if isSSHKey(key) {
+		return sshSignatureExt
+	}
+	return minisignSignatureExt
+}
+
+// verifySignature 使用公钥验证签名
+func verifySignature(key string, message, signature []byte) error {
+	if isSSHKey(key) {
+		return verifySSHSignature(key, message, signature)
+	}
+	return verifyMinisign(key, message, signature)
+}
+
+// minisignPublicKey minisign公钥
+type minisignPublicKey struct {
+	keyID [8]byte
+	key   ed25519.PublicKey
+}
+
+// parseMinisignPublicKey 解析minisign公钥（忽略"untrusted comment:"行）
+func parseMinisignPublicKey(text string) (*minisignPublicKey, error) {
+	var encoded string
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "untrusted comment:") {
+			encoded = line
+			break
+		}
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil || len(raw) != 2+8+ed25519.PublicKeySize || string(raw[:2]) != "Ed" {
+		return nil, fmt.Errorf("invalid signing key: expected a minisign public key or an SSH public key")
+	}
+
+	pk := &minisignPublicKey{key: ed25519.PublicKey(raw[10:])}
+	copy(pk.keyID[:], raw[2:10])
+	return pk, nil
+}
+
+// verifyMinisign 验证minisign签名
+//
+// 支持旧版直接签名（Ed）和默认的预哈希签名（ED，BLAKE2b-512），
+// 同时验证覆盖可信注释的全局签名。
+func verifyMinisign(key string, message, signature []byte) error {
+	pk, err := parseMinisignPublicKey(key)
+	if err != nil {
+		return err
+	}
+
+	lines := strings.Split(strings.ReplaceAll(string(signature), "\r\n", "\n"), "\n")
+	if len(lines) < 4 || !strings.HasPrefix(lines[2], "trusted comment: ") {
+		return fmt.Errorf("invalid minisign signature format")
+	}
+
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[1]))
+	if err != nil || len(sig) != 2+8+ed25519.SignatureSize {
+		return fmt.Errorf("invalid minisign signature encoding")
+	}
+	if !bytes.Equal(sig[2:10], pk.keyID[:]) {
+		return fmt.Errorf("signature was made with a different key (key ID %X, expected %X)", sig[2:10], pk.keyID[:])
+	}
+
+	switch string(sig[:2]) {
+	case "Ed":
+	case "ED":
+		digest := blake2b.Sum512(message)
+		message = digest[:]
+	default:
+		return fmt.Errorf("unsupported minisign signature algorithm %q", sig[:2])
+	}
+	if !ed25519.Verify(pk.key, message, sig[10:]) {
+		return fmt.Errorf("minisign signature verification failed")
+	}
+
+	trustedComment := strings.TrimPrefix(lines[2], "trusted comment: ")
+	globalSig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[3]))
+	if err != nil || len(globalSig) != ed25519.SignatureSize {
+		return fmt.Errorf("invalid minisign global signature encoding")
+	}
+	globalMessage := append(append([]byte{}, sig[10:]...), trustedComment...)
+	if !ed25519.Verify(pk.key, globalMessage, globalSig) {
+		return fmt.Errorf("minisign trusted comment verification failed")
+	}
+	return nil
+}
+
+// sshSignature SSHSIG签名结构（见OpenSSH PROTOCOL.sshsig）
+type sshSignature struct {
+	Version       uint32
+	PublicKey     []byte
+	Namespace     string
+	Reserved      string
+	HashAlgorithm string
+	Signature     []byte
+}
+
+// verifySSHSignature 验证ssh-keygen -Y sign生成的签名
+func verifySSHSignature(key string, message, signature []byte) error {
+	publicKey, _, _, _, err := ssh.ParseAuthorizedKey([]byte(key))
+	if err != nil {
+		return fmt.Errorf("invalid SSH public key: %w", err)
+	}
+
+	block, _ := pem.Decode(signature)
+	if block == nil || block.Type != "SSH SIGNATURE" {
+		return fmt.Errorf("invalid SSH signature: missing SSH SIGNATURE block")
+	}
+	const magic = "SSHSIG"
+	if !bytes.HasPrefix(block.Bytes, []byte(magic)) {
+		return fmt.Errorf("invalid SSH signature: bad magic")
+	}
+
+	var sig sshSignature
+	if err := ssh.Unmarshal(block.Bytes[len(magic):], &sig); err != nil {
+		return fmt.Errorf("invalid SSH signature: %w", err)
+	}
+	if sig.Version != 1 {
+		return fmt.Errorf("unsupported SSH signature version %d", sig.Version)
+	}
+	if sig.Namespace != sshSignatureNamespace {
+		return fmt.Errorf("SSH signature namespace is %q, expected %q", sig.Namespace, sshSignatureNamespace)
+	}
+
+	signer, err := ssh.ParsePublicKey(sig.PublicKey)
+	if err != nil {
+		return fmt.Errorf("invalid SSH signature public key: %w", err)
+	}
+	if !bytes.Equal(signer.Marshal(), publicKey.Marshal()) {
+		return fmt.Errorf("signature was made with a different key (%s)", ssh.FingerprintSHA256(signer))
+	}
+
+	var digest []byte
+	switch sig.HashAlgorithm {
+	case "sha256":
+		sum := sha256.Sum256(message)
+		digest = sum[:]
+	case "sha512":
+		sum := sha512.Sum512(message)
+		digest = sum[:]
+	default:
+		return fmt.Errorf("unsupported SSH signature hash algorithm %q", sig.HashAlgorithm)
+	}
+
+	signedData := append([]byte(magic), ssh.Marshal(struct {
+		Namespace     string
+		Reserved      string
+		HashAlgorithm string
+		Hash          []byte
+	}{sig.Namespace, sig.Reserved, sig.HashAlgorithm, digest})...)
+
+	var blob ssh.Signature
+	if err := ssh.Unmarshal(sig.Signature, &blob); err != nil {
+		return fmt.Errorf("invalid SSH signature blob: %w", err)
+	}
+	if err := publicKey.Verify(signedData, &blob); err != nil {
+		return fmt.Errorf("SSH signature verification failed: %w", err)
+	}
+	return nil
+}
+
+// findReleaseAsset 按名称查找发布资源
+func findReleaseAsset(release *types.GitHubRelease, names ...string) *types.Asset {
+	for _, name := range names {
+		for i := range release.Assets {
+			if release.Assets[i].Name == name {
+				return &release.Assets[i]
+			}
+		}
+	}
+	return nil
+}
+
+// resolveChecksum 确定模板压缩包的预期SHA-256摘要并写入下载选项
+//
+// 发布中包含SHA256SUMS时自动下载并查找资源对应的摘要；配置了签名公钥时
+// 还要求清单带有对应格式的签名（.minisig或.sig）并验证通过。
+// 使用--checksum固定的摘要必须与清单一致。下载器据此在写入完成后校验文件，
+// 不匹配时拒绝使用下载结果。
+func (tp *TemplateProvider) resolveChecksum(ctx context.Context, release *types.GitHubRelease, asset *types.Asset, opts *types.DownloadOptions) error {
+	expected := ""
+	if opts.Checksum != "" {
+		algorithm := opts.ChecksumType
+		if algorithm == "" {
+			algorithm = "sha256"
+		}
+		algorithm, sum, err := ParseChecksum(algorithm + ":" + opts.Checksum)
+		if err != nil {
+			return err
+		}
+		opts.ChecksumType, expected = algorithm, sum
+	}
+
+	manifestAsset := findReleaseAsset(release, checksumManifestNames...)
+	if manifestAsset == nil {
+		if opts.SigningKey != "" {
+			return fmt.Errorf("release %s does not publish SHA256SUMS but a signing key is configured", release.TagName)
+		}
+		tp.getLogger().Debug("release has no checksum manifest", "tag", release.TagName)
+	} else {
+		manifest, err := tp.fetchAsset(ctx, manifestAsset, *opts)
+		if err != nil {
+			return fmt.Errorf("failed to download %s: %w", manifestAsset.Name, err)
+		}
+
+		if err := tp.verifyManifestSignature(ctx, release, manifestAsset, manifest, *opts); err != nil {
+			return err
+		}
+
+		sum, ok := parseChecksumManifest(manifest)[asset.Name]
+		if !ok {
+			return fmt.Errorf("%s does not list %s", manifestAsset.Name, asset.Name)
+		}
+		if expected != "" && expected != sum {
+			return fmt.Errorf("pinned checksum sha256:%s does not match %s (sha256:%s)", expected, manifestAsset.Name, sum)
+		}
+		expected = sum
+	}
+
+	if expected != "" {
+		opts.VerifyChecksum = true
+		opts.ChecksumType = "sha256"
+		opts.Checksum = expected
+		tp.getLogger().Info("checksum resolved", "asset", asset.Name, "sha256", expected)
+	}
+	return nil
+}
+
+// verifyManifestSignature 验证校验和清单的签名
+func (tp *TemplateProvider) verifyManifestSignature(ctx context.Context, release *types.GitHubRelease, manifestAsset *types.Asset, manifest []byte, opts types.DownloadOptions) error {
+	if opts.SigningKey == "" {
+		if findReleaseAsset(release, manifestAsset.Name+minisignSignatureExt, manifestAsset.Name+sshSignatureExt) != nil {
+			tp.getLogger().Warn("checksum manifest is signed but no signing key is configured; signature not verified",
+				"manifest", manifestAsset.Name)
+		}
+		return nil
+	}
+
+	key, err := LoadSigningKey(opts.SigningKey)
+	if err != nil {
+		return err
+	}
+	sigName := manifestAsset.Name + signatureExt(key)
+	sigAsset := findReleaseAsset(release, sigName)
+	if sigAsset == nil {
+		return fmt.Errorf("release %s does not publish %s required by the configured signing key", release.TagName, sigName)
+	}
+
+	signature, err := tp.fetchAsset(ctx, sigAsset, opts)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", sigName, err)
+	}
+	if err := verifySignature(key, manifest, signature); err != nil {
+		return fmt.Errorf("%s: %w", sigName, err)
+	}
+
+	tp.getLogger().Info("checksum manifest signature verified", "manifest", manifestAsset.Name, "signature", sigName)
+	return nil
+}
+
+// fetchAsset 下载较小的发布资源（校验和清单、签名）到内存
+func (tp *TemplateProvider) fetchAsset(ctx context.Context, asset *types.Asset, opts types.DownloadOptions) ([]byte, error) {
+	src, err := tp.templateSource(opts.TemplateRepo)
+	if err != nil {
+		return nil, err
+	}
+	url, headers := src.AssetRequest(asset, opts.GitHubToken)
+
+	if strings.HasPrefix(url, "file://") {
+		u, err := neturl.Parse(url)
+		if err != nil {
+			return nil, fmt.Errorf("invalid asset URL: %w", err)
+		}
+		return os.ReadFile(fileURLPath(u))
+	}
+
+	// 与模板资源一样，原始地址失败时依次尝试配置的镜像
+	var lastErr error
+	for _, source := range downloadSources(url, headers, asset, opts.Mirrors) {
+		resp, err := tp.client.R().SetContext(ctx).SetHeaders(source.Headers).Get(source.URL)
+		if err == nil && resp.StatusCode() == http.StatusOK {
+			return resp.Body(), nil
+		}
+		if err == nil {
+			err = fmt.Errorf("HTTP %d", resp.StatusCode())
+		}
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		tp.getLogger().Debug("failed to fetch asset", "url", source.URL, "error", err)
+		lastErr = err
+	}
+	return nil, lastErr
+}
+
+// verifyFileChecksum 校验文件的SHA-256摘要
+func verifyFileChecksum(path, expected string) error {
+	actual, err := fileSHA256(path)
+	if err != nil {
+		return fmt.Errorf("failed to compute checksum: %w", err)
+	}
+	if actual != strings.ToLower(expected) {
+		return fmt.Errorf("checksum verification failed: expected sha256:%s, got sha256:%s", expected, actual)
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/blake2b"
+	"specify-cli/internal/types"
+)
+
+// TestParseChecksum 测试 --checksum 格式
+func TestParseChecksum(t *testing.T) {
+	sum := strings.Repeat("ab", 32)
+	algorithm, parsed, err := ParseChecksum("SHA256:" + strings.ToUpper(sum))
+	require.NoError(t, err)
+	assert.Equal(t, "sha256", algorithm)
+	assert.Equal(t, sum, parsed)
+
+	for _, invalid := range []string{sum, "md5:" + sum, "sha256:abc", "sha256:" + strings.Repeat("zz", 32)} {
+		assert.Error(t, ValidateChecksum(invalid), invalid)
+	}
+}
+
+// minisignSign 按minisign格式（预哈希ED）生成测试用公钥和签名
+func minisignSign(t *testing.T, message []byte) (publicKey string, signature []byte) {
+	t.Helper()
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	keyID := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+
+	publicKey = "untrusted comment: minisign public key\n" +
+		base64.StdEncoding.EncodeToString(append(append([]byte("Ed"), keyID...), pub...))
+
+	digest := blake2b.Sum512(message)
+	sig := ed25519.Sign(priv, digest[:])
+	trustedComment := "timestamp:1700000000\tfile:SHA256SUMS"
+	globalSig := ed25519.Sign(priv, append(append([]byte{}, sig...), trustedComment...))
+
+	signature = []byte(fmt.Sprintf("untrusted comment: signature\n%s\ntrusted comment: %s\n%s\n",
+		base64.StdEncoding.EncodeToString(append(append([]byte("ED"), keyID...), sig...)),
+		trustedComment,
+		base64.StdEncoding.EncodeToString(globalSig)))
+	return publicKey, signature
+}
+
+// TestVerifyMinisign 测试minisign签名验证
+func TestVerifyMinisign(t *testing.T) {
+	message := []byte("abc  template.zip\n")
+	key, signature := minisignSign(t, message)
+
+	require.NoError(t, ValidateSigningKey(key))
+	assert.NoError(t, verifySignature(key, message, signature))
+	assert.ErrorContains(t, verifySignature(key, []byte("tampered"), signature), "verification failed")
+
+	otherKey, _ := minisignSign(t, message)
+	assert.Error(t, verifySignature(otherKey, message, signature))
+}
+
+// TestVerifySSHSignature 测试ssh-keygen -Y sign生成的签名
+func TestVerifySSHSignature(t *testing.T) {
+	if _, err := exec.LookPath("ssh-keygen"); err != nil {
+		t.Skip("ssh-keygen not available")
+	}
+
+	dir := t.TempDir()
+	keyPath := filepath.Join(dir, "id_ed25519")
+	out, err := exec.Command("ssh-keygen", "-q", "-t", "ed25519", "-N", "", "-f", keyPath).CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	message := []byte("abc  template.zip\n")
+	manifest := filepath.Join(dir, "SHA256SUMS")
+	require.NoError(t, os.WriteFile(manifest, message, 0644))
+	out, err = exec.Command("ssh-keygen", "-Y", "sign", "-f", keyPath, "-n", "file", manifest).CombinedOutput()
+	require.NoError(t, err, string(out))
+
+	signature, err := os.ReadFile(manifest + ".sig")
+	require.NoError(t, err)
+	key, err := LoadSigningKey(keyPath + ".pub")
+	require.NoError(t, err)
+
+	assert.NoError(t, verifySignature(key, message, signature))
+	assert.Error(t, verifySignature(key, []byte("tampered"), signature))
+}
+
+// TestTemplateProvider_ChecksumManifest 测试下载时按SHA256SUMS和固定校验和拒绝不匹配的压缩包
+func TestTemplateProvider_ChecksumManifest(t *testing.T) {
+	isolateCredentials(t)
+	repoDir := t.TempDir()
+	archive := filepath.Join(repoDir, "claude-sh.zip")
+	require.NoError(t, createTestZipFile(archive, map[string]string{
+		".specify/templates/spec-template.md": "# Spec",
+	}))
+	sum, err := fileSHA256(archive)
+	require.NoError(t, err)
+
+	const assetName = "spec-kit-template-claude-sh-v1.0.0.zip"
+	manifest := []byte(sum + "  " + assetName + "\n")
+	key, signature := minisignSign(t, manifest)
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "SHA256SUMS"), manifest, 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "SHA256SUMS.minisig"), signature, 0644))
+
+	index := `{"version":"v1.0.0","assets":[{"name":"` + assetName + `","url":"claude-sh.zip"},` +
+		`{"name":"SHA256SUMS","url":"SHA256SUMS"},{"name":"SHA256SUMS.minisig","url":"SHA256SUMS.minisig"}]}`
+	indexPath := filepath.Join(repoDir, "index.json")
+	require.NoError(t, os.WriteFile(indexPath, []byte(index), 0644))
+
+	download := func(opts types.DownloadOptions) (string, error) {
+		opts.AIAssistant = "claude"
+		opts.ScriptType = "sh"
+		opts.DownloadDir = t.TempDir()
+		opts.TemplateRepo = "file://" + filepath.ToSlash(indexPath)
+		return NewTemplateProvider().Download(context.Background(), opts)
+	}
+
+	targetDir, err := download(types.DownloadOptions{SigningKey: key, Checksum: sum, ChecksumType: "sha256"})
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(targetDir, ".specify", "templates", "spec-template.md"))
+
+	// 固定的校验和与清单不一致
+	_, err = download(types.DownloadOptions{Checksum: strings.Repeat("0", 64), ChecksumType: "sha256"})
+	assert.ErrorContains(t, err, "does not match SHA256SUMS")
+
+	// 签名公钥不匹配
+	otherKey, _ := minisignSign(t, manifest)
+	_, err = download(types.DownloadOptions{SigningKey: otherKey})
+	assert.ErrorContains(t, err, "SHA256SUMS.minisig")
+
+	// 压缩包被替换后清单校验失败，且不会解压
+	require.NoError(t, createTestZipFile(archive, map[string]string{
+		".specify/templates/spec-template.md": "# Tampered",
+	}))
+	_, err = download(types.DownloadOptions{})
+	assert.ErrorContains(t, err, "checksum verification failed")
+}
+
+// TestStreamingDownloader_ResumeChecksum 测试续传和已完成的文件同样按整个文件校验
+func TestStreamingDownloader_ResumeChecksum(t *testing.T) {
+	content := bytes.Repeat([]byte("spec-kit template "), 1024)
+	sum := sha256.Sum256(content)
+	checksum := hex.EncodeToString(sum[:])
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "template.zip", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+
+	opts := &types.DownloadOptions{EnableResume: true, VerifyChecksum: true, Checksum: checksum, ChecksumType: "sha256"}
+	tests := []struct {
+		name    string
+		partial []byte
+		wantErr bool
+	}{
+		{name: "resume", partial: content[:5000]},
+		{name: "already complete", partial: content},
+		{name: "corrupt prefix", partial: append([]byte("corrupt"), content[7:5000]...), wantErr: true},
+		{name: "corrupt complete file", partial: append([]byte("corrupt"), content[7:]...), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dest := filepath.Join(t.TempDir(), "template.zip")
+			require.NoError(t, os.WriteFile(dest, tt.partial, 0644))
+
+			err := NewStreamingDownloader(server.Client(), 4096).DownloadWithStreaming(context.Background(), server.URL, dest, opts)
+			if tt.wantErr {
+				assert.ErrorContains(t, err, "checksum verification failed")
+				return
+			}
+			require.NoError(t, err)
+			data, err := os.ReadFile(dest)
+			require.NoError(t, err)
+			assert.Equal(t, content, data)
+		})
+	}
+}
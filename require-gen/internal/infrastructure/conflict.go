@@ -0,0 +1,269 @@
+package infrastructure
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"specify-cli/internal/types"
+	"specify-cli/internal/ui"
+)
+
+const (
+	// maxDiffLines 单个文件参与差异计算的最大行数，超过后仅显示摘要
+	maxDiffLines = 2000
+	// diffContextLines 差异块前后保留的上下文行数
+	diffContextLines = 3
+)
+
+// applyConflictPolicy 根据--on-conflict策略配置提取选项
+//
+// 未指定策略时保持原有行为（直接覆盖现有文件）；指定策略后启用智能合并，
+// prompt策略会为每个冲突文件显示差异并交互式询问处理方式。
+func applyConflictPolicy(extractOpts *ExtractOptions, onConflict string, verbose bool) {
+	if onConflict == "" {
+		return
+	}
+
+	extractOpts.SmartMerge = true
+	extractOpts.OverwriteExisting = onConflict == "overwrite"
+	extractOpts.ConflictResolution = onConflict
+	if onConflict == "prompt" {
+		extractOpts.OnConflict = NewConflictPrompter().Resolve
+	}
+	extractOpts.OnMerge = func(targetPath string, result *StructuredMergeResult, err error) {
+		if err != nil {
+			ui.ShowWarning(fmt.Sprintf("Kept %s unchanged (merge failed: %v)", targetPath, err))
+			return
+		}
+		if result.Changed() || verbose {
+			ui.ShowInfo(fmt.Sprintf("Merged %s: %s", targetPath, result.Summary()))
+		}
+	}
+}
+
+// ConflictPrompter 交互式文件冲突处理器
+//
+// ConflictPrompter 在模板提取过程中遇到已存在的文件时，
+// 先使用ui.Syntax渲染现有文件与模板文件之间的差异，
+// 再让用户选择跳过、覆盖、重命名或合并。
+//
+// 选择"全部跳过"或"全部覆盖"后，后续冲突不再提示，
+// 直接沿用该决定，避免大型模板逐个确认。
+//
+// 当终端不支持交互（提示失败）时，默认跳过以保护用户文件。
+type ConflictPrompter struct {
+	sticky *ConflictAction // 已记住的"全部"决定
+}
+
+// NewConflictPrompter 创建交互式冲突处理器
+func NewConflictPrompter() *ConflictPrompter {
+	return &ConflictPrompter{}
+}
+
+// Resolve 实现ExtractOptions.OnConflict回调
+func (cp *ConflictPrompter) Resolve(info *FileConflictInfo) ConflictAction {
+	if cp.sticky != nil {
+		return *cp.sticky
+	}
+
+	fmt.Println()
+	ui.ShowWarning(fmt.Sprintf("File already exists: %s", info.TargetPath))
+	if diff := cp.renderDiff(info); diff != "" {
+		fmt.Println(diff)
+	}
+
+	options := []types.AgentOption{
+		{Key: "skip", Name: "Keep existing file"},
+		{Key: "overwrite", Name: "Replace with template version"},
+		{Key: "rename", Name: "Write template version next to it"},
+		{Key: "merge", Name: "Merge (add missing config keys, otherwise keep newer file)"},
+		{Key: "skip-all", Name: "Keep all remaining existing files"},
+		{Key: "overwrite-all", Name: "Replace all remaining existing files"},
+	}
+
+	choice, err := ui.SelectWithArrowsOrdered(options, "Resolve conflict", "skip")
+	if err != nil {
+		ui.ShowWarning(fmt.Sprintf("Conflict prompt unavailable, keeping %s", info.TargetPath))
+		return ConflictSkip
+	}
+
+	switch choice {
+	case "overwrite":
+		return ConflictOverwrite
+	case "rename":
+		return ConflictRename
+	case "merge":
+		return ConflictMerge
+	case "skip-all":
+		action := ConflictSkip
+		cp.sticky = &action
+		return action
+	case "overwrite-all":
+		action := ConflictOverwrite
+		cp.sticky = &action
+		return action
+	default:
+		return ConflictSkip
+	}
+}
+
+// renderDiff 生成并高亮现有文件与模板文件之间的差异
+func (cp *ConflictPrompter) renderDiff(info *FileConflictInfo) string {
+	if info.ReadSource == nil {
+		return ""
+	}
+
+	source, err := info.ReadSource()
+	if err != nil {
+		return ""
+	}
+
+	existing, err := NewSystemOperations().ReadFile(info.TargetPath)
+	if err != nil {
+		return ""
+	}
+
+	if bytes.Equal(existing, source) {
+		return "  (files are identical)"
+	}
+
+	if bytes.IndexByte(existing, 0) >= 0 || bytes.IndexByte(source, 0) >= 0 {
+		return fmt.Sprintf("  (binary files differ: existing %d bytes, template %d bytes)", len(existing), len(source))
+	}
+
+	diff := UnifiedDiff(string(existing), string(source), info.TargetPath+" (existing)", info.SourcePath+" (template)")
+	return ui.NewSyntax(diff, "diff").Render()
+}
+
+// UnifiedDiff 生成两段文本之间的统一差异格式（unified diff）
+//
+// 基于行级最长公共子序列（LCS）计算差异，输出带有
+// diffContextLines行上下文的差异块。超过maxDiffLines行的文本
+// 只返回行数摘要，避免在交互提示中输出过多内容。
+func UnifiedDiff(oldText, newText, oldName, newName string) string {
+	oldLines := splitLines(oldText)
+	newLines := splitLines(newText)
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("--- %s\n+++ %s\n", oldName, newName))
+
+	if len(oldLines) > maxDiffLines || len(newLines) > maxDiffLines {
+		sb.WriteString(fmt.Sprintf("@@ files too large to diff (%d vs %d lines) @@", len(oldLines), len(newLines)))
+		return sb.String()
+	}
+
+	ops := diffLines(oldLines, newLines)
+
+	// 按上下文将差异操作分组为差异块
+	for start := 0; start < len(ops); {
+		for start < len(ops) && ops[start].kind == ' ' {
+			start++
+		}
+		if start >= len(ops) {
+			break
+		}
+
+		hunkStart := start - diffContextLines
+		if hunkStart < 0 {
+			hunkStart = 0
+		}
+		end := start
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			// 连续未变化的行超过两倍上下文时结束当前差异块
+			run := end
+			for run < len(ops) && ops[run].kind == ' ' {
+				run++
+			}
+			if run == len(ops) || run-end > 2*diffContextLines {
+				break
+			}
+			end = run
+		}
+		hunkEnd := end + diffContextLines
+		if hunkEnd > len(ops) {
+			hunkEnd = len(ops)
+		}
+
+		oldStart, newStart := ops[hunkStart].oldLine, ops[hunkStart].newLine
+		oldCount, newCount := 0, 0
+		for _, op := range ops[hunkStart:hunkEnd] {
+			if op.kind != '+' {
+				oldCount++
+			}
+			if op.kind != '-' {
+				newCount++
+			}
+		}
+		sb.WriteString(fmt.Sprintf("@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount))
+		for _, op := range ops[hunkStart:hunkEnd] {
+			sb.WriteByte(op.kind)
+			sb.WriteString(op.text)
+			sb.WriteByte('\n')
+		}
+
+		start = hunkEnd
+	}
+
+	return strings.TrimRight(sb.String(), "\n")
+}
+
+// diffOp 单行差异操作
+type diffOp struct {
+	kind    byte // ' ' 未变化, '-' 删除, '+' 新增
+	text    string
+	oldLine int // 在旧文本中的行号（从1开始）
+	newLine int // 在新文本中的行号（从1开始）
+}
+
+// diffLines 基于LCS计算行级差异
+func diffLines(a, b []string) []diffOp {
+	n, m := len(a), len(b)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	ops := make([]diffOp, 0, n+m)
+	i, j := 0, 0
+	for i < n || j < m {
+		switch {
+		case i < n && j < m && a[i] == b[j]:
+			ops = append(ops, diffOp{kind: ' ', text: a[i], oldLine: i + 1, newLine: j + 1})
+			i++
+			j++
+		case j < m && (i >= n || lcs[i][j+1] >= lcs[i+1][j]):
+			ops = append(ops, diffOp{kind: '+', text: b[j], oldLine: i + 1, newLine: j + 1})
+			j++
+		default:
+			ops = append(ops, diffOp{kind: '-', text: a[i], oldLine: i + 1, newLine: j + 1})
+			i++
+		}
+	}
+	return ops
+}
+
+// splitLines 按行拆分文本，忽略末尾换行
+func splitLines(text string) []string {
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	text = strings.TrimSuffix(text, "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
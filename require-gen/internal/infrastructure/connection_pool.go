@@ -142,6 +142,7 @@ func (cp *ConnectionPool) createTransport(tlsConfig *types.TLSConfig) *http.Tran
 	}
 
 	transport := &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
 		DialContext:           dialer.DialContext,
 		MaxIdleConns:          cp.config.MaxIdleConns,
 		MaxIdleConnsPerHost:   cp.config.MaxIdleConnsPerHost,
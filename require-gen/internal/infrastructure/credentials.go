@@ -0,0 +1,178 @@
+package infrastructure
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	neturl "net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"specify-cli/internal/types"
+)
+
+// credentialTimeout 调用gh和git凭据助手的超时时间
+const credentialTimeout = 5 * time.Second
+
+// credentialLookup 外部凭据来源
+type credentialLookup struct {
+	source TokenSource
+	lookup func(ctx context.Context, host string) string
+}
+
+// credentialLookups 按优先级排列的外部凭据来源
+//
+// 环境变量中没有令牌时依次尝试：specify auth login保存的令牌、
+// GitHub CLI（gh auth token）、git凭据助手（git credential fill）和~/.netrc。
+var credentialLookups = []credentialLookup{
+	{TokenSourceStored, storedTokenLookup},
+	{TokenSourceGHCLI, ghCLIToken},
+	{TokenSourceGitCredential, gitCredentialToken},
+	{TokenSourceNetrc, func(ctx context.Context, host string) string { return netrcToken(host) }},
+}
+
+// APIHost 根据API地址确定凭据对应的主机名
+//
+// api.github.com 对应 github.com；GitHub Enterprise Server 的
+// https://<host>/api/v3 对应 <host>。
+func APIHost(networkConfig *types.NetworkConfig) string {
+	u, err := neturl.Parse(apiBaseURL(networkConfig))
+	if err != nil || u.Hostname() == "" {
+		return "github.com"
+	}
+	return strings.TrimPrefix(u.Hostname(), "api.")
+}
+
+// ghCLIToken 读取GitHub CLI为主机保存的令牌
+func ghCLIToken(ctx context.Context, host string) string {
+	if _, err := exec.LookPath("gh"); err != nil {
+		return ""
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, credentialTimeout)
+	defer cancel()
+
+	out, err := exec.CommandContext(ctx, "gh", "auth", "token", "--hostname", host).Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// gitCredentialToken 通过git凭据助手获取主机的密码（令牌）
+//
+// 禁止终端提示和Git Credential Manager的交互式窗口，
+// 没有保存的凭据时直接返回空字符串而不是等待用户输入。
+func gitCredentialToken(ctx context.Context, host string) string {
+	if _, err := exec.LookPath("git"); err != nil {
+		return ""
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, credentialTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "git", "credential", "fill")
+	cmd.Stdin = strings.NewReader("protocol=https\nhost=" + host + "\n\n")
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0", "GCM_INTERACTIVE=never", "GIT_ASKPASS=", "SSH_ASKPASS=")
+	out, err := cmd.Output()
+	if err != nil {
+		return ""
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		if password, ok := strings.CutPrefix(scanner.Text(), "password="); ok {
+			return strings.TrimSpace(password)
+		}
+	}
+	return ""
+}
+
+// netrcPath netrc文件位置
+//
+// 优先使用NETRC环境变量，否则为~/.netrc（Windows上为~/_netrc）。
+func netrcPath() string {
+	if path := os.Getenv("NETRC"); path != "" {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	if runtime.GOOS == "windows" {
+		return filepath.Join(home, "_netrc")
+	}
+	return filepath.Join(home, ".netrc")
+}
+
+// netrcToken 读取netrc中主机对应的password
+func netrcToken(host string) string {
+	path := netrcPath()
+	if path == "" {
+		return ""
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return ""
+	}
+	return parseNetrc(string(data), host)
+}
+
+// parseNetrc 解析netrc内容，返回machine匹配（或default）条目的password
+func parseNetrc(content, host string) string {
+	var (
+		defaultPassword string
+		machine         string
+		inDefault       bool
+		inMacro         bool
+	)
+
+	for _, line := range strings.Split(content, "\n") {
+		// macdef定义的宏以空行结束
+		if inMacro {
+			if strings.TrimSpace(line) == "" {
+				inMacro = false
+			}
+			continue
+		}
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		for i := 0; i < len(fields); i++ {
+			switch fields[i] {
+			case "machine":
+				if i+1 < len(fields) {
+					i++
+					machine = fields[i]
+				}
+				inDefault = false
+			case "default":
+				machine = ""
+				inDefault = true
+			case "password":
+				if i+1 >= len(fields) {
+					continue
+				}
+				i++
+				if strings.EqualFold(machine, host) {
+					return fields[i]
+				}
+				if inDefault && defaultPassword == "" {
+					defaultPassword = fields[i]
+				}
+			case "macdef":
+				inMacro = true
+				i = len(fields)
+			case "login", "account":
+				i++
+			}
+		}
+	}
+	return defaultPassword
+}
@@ -0,0 +1,182 @@
+package infrastructure
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// downloadStateSaveInterval 下载过程中保存状态文件的最短间隔
+const downloadStateSaveInterval = 500 * time.Millisecond
+
+// remoteFile HEAD请求得到的远程文件信息
+type remoteFile struct {
+	Size         int64
+	ETag         string
+	LastModified string
+}
+
+// downloadState 可恢复下载的状态
+//
+// 保存在 <dest>.part.json，与写入中的 <dest>.part 配对。进程被终止后再次下载
+// 同一文件时，只要URL、大小和校验值（ETag或Last-Modified）都与远程文件一致，
+// 就只请求尚未完成的字节范围，并通过If-Range确保服务器上的文件没有变化。
+type downloadState struct {
+	URL          string     `json:"url"`
+	ETag         string     `json:"etag,omitempty"`
+	LastModified string     `json:"last_modified,omitempty"`
+	Size         int64      `json:"size"`
+	Completed    [][2]int64 `json:"completed"` // 已写入的字节范围[start, end]，按起点排序且互不重叠
+
+	path     string
+	mu       sync.Mutex
+	saveMu   sync.Mutex // 串行化状态文件的写入
+	lastSave time.Time
+}
+
+// partFilePath 下载中的文件
+func partFilePath(dest string) string {
+	return dest + ".part"
+}
+
+// downloadStatePath 下载状态文件
+func downloadStatePath(dest string) string {
+	return dest + ".part.json"
+}
+
+// newDownloadState 为远程文件创建空的下载状态
+func newDownloadState(dest, url string, remote *remoteFile) *downloadState {
+	return &downloadState{
+		URL:          url,
+		ETag:         remote.ETag,
+		LastModified: remote.LastModified,
+		Size:         remote.Size,
+		path:         downloadStatePath(dest),
+	}
+}
+
+// loadDownloadState 读取dest的下载状态，不存在或无法解析时返回nil
+func loadDownloadState(dest string) *downloadState {
+	data, err := os.ReadFile(downloadStatePath(dest))
+	if err != nil {
+		return nil
+	}
+	var state downloadState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil
+	}
+	state.path = downloadStatePath(dest)
+	return &state
+}
+
+// validator If-Range使用的校验值
+//
+// 弱ETag（W/前缀）不能用于If-Range，此时使用Last-Modified。
+func (s *downloadState) validator() string {
+	if s.ETag != "" && !strings.HasPrefix(s.ETag, "W/") {
+		return s.ETag
+	}
+	return s.LastModified
+}
+
+// matches 已保存的部分下载是否属于当前的远程文件
+func (s *downloadState) matches(url string, remote *remoteFile) bool {
+	if s.URL != url || s.Size != remote.Size || s.validator() == "" {
+		return false
+	}
+	return s.ETag == remote.ETag && s.LastModified == remote.LastModified
+}
+
+// markCompleted 记录[start, end]已写入
+func (s *downloadState) markCompleted(start, end int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ranges := append(s.Completed, [2]int64{start, end})
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i][0] < ranges[j][0] })
+
+	merged := ranges[:1]
+	for _, r := range ranges[1:] {
+		last := &merged[len(merged)-1]
+		if r[0] <= last[1]+1 {
+			if r[1] > last[1] {
+				last[1] = r[1]
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	s.Completed = merged
+}
+
+// missing 尚未下载的字节范围
+func (s *downloadState) missing() []byteRange {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var ranges []byteRange
+	var next int64
+	for _, r := range s.Completed {
+		if r[0] > next {
+			ranges = append(ranges, byteRange{Start: next, End: r[0] - 1})
+		}
+		next = r[1] + 1
+	}
+	if next < s.Size {
+		ranges = append(ranges, byteRange{Start: next, End: s.Size - 1})
+	}
+	for i := range ranges {
+		ranges[i].Index = i
+	}
+	return ranges
+}
+
+// completedBytes 已下载的字节数
+func (s *downloadState) completedBytes() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var n int64
+	for _, r := range s.Completed {
+		n += r[1] - r[0] + 1
+	}
+	return n
+}
+
+// save 写入状态文件
+//
+// force为false时距上次保存不足downloadStateSaveInterval则跳过，
+// 避免每次写入数据块都重写状态文件。
+func (s *downloadState) save(force bool) error {
+	s.mu.Lock()
+	if !force && time.Since(s.lastSave) < downloadStateSaveInterval {
+		s.mu.Unlock()
+		return nil
+	}
+	s.lastSave = time.Now()
+	data, err := json.Marshal(s)
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	s.saveMu.Lock()
+	defer s.saveMu.Unlock()
+
+	// 先写临时文件再重命名，进程被终止时不会留下损坏的状态文件
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to save download state: %w", err)
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// discardPartialDownload 删除dest的部分下载和状态文件
+func discardPartialDownload(dest string) {
+	os.Remove(partFilePath(dest))
+	os.Remove(downloadStatePath(dest))
+}
@@ -3,10 +3,13 @@ package infrastructure
 import (
 	"context"
 	"fmt"
+	"io"
 	"net/http"
+	neturl "net/url"
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"time"
 
 	"specify-cli/internal/types"
@@ -20,6 +23,8 @@ type EnhancedDownloader struct {
 	errorHandler *NetworkErrorHandler
 	retryManager *RetryManager
 	config       *DownloadConfig
+	headers      map[string]string
+	limiter      *BandwidthLimiter
 }
 
 // DownloadConfig 下载配置
@@ -45,6 +50,9 @@ type DownloadConfig struct {
 	// 恢复配置
 	EnableResume    bool  // 启用断点续传
 	ResumeThreshold int64 // 续传阈值
+
+	// 限速配置
+	Limiter *BandwidthLimiter // 下载限速器，nil表示不限速
 }
 
 // DefaultDownloadConfig 默认下载配置
@@ -85,6 +93,18 @@ func NewEnhancedDownloader(client *resty.Client, errorHandler *NetworkErrorHandl
 	}
 }
 
+// SetHeaders 设置每个下载请求附加的HTTP头
+func (ed *EnhancedDownloader) SetHeaders(headers map[string]string) {
+	ed.headers = headers
+}
+
+// SetBandwidthLimiter 设置下载共享的限速器
+//
+// 未设置时每次下载按DownloadOptions.LimitRate单独限速。
+func (ed *EnhancedDownloader) SetBandwidthLimiter(limiter *BandwidthLimiter) {
+	ed.limiter = limiter
+}
+
 // Download 下载文件
 func (ed *EnhancedDownloader) Download(ctx context.Context, url, dest string, opts *types.DownloadOptions) error {
 	// 转换选项
@@ -102,17 +122,29 @@ func (ed *EnhancedDownloader) Download(ctx context.Context, url, dest string, op
 	}
 
 	// 执行下载
-	return ed.executeDownload(ctx, url, dest, opts)
+	if err := ed.executeDownload(ctx, url, dest, opts); err != nil {
+		return err
+	}
+
+	if config.VerifyChecksum && config.ExpectedSum != "" {
+		return ed.verifyChecksum(dest, config.ChecksumType, config.ExpectedSum)
+	}
+	return nil
 }
 
 // convertOptions 转换下载选项
 func (ed *EnhancedDownloader) convertOptions(opts *types.DownloadOptions) *DownloadConfig {
 	config := DefaultDownloadConfig()
+	config.Limiter = ed.limiter
 
 	if opts == nil {
 		return config
 	}
 
+	if config.Limiter == nil {
+		config.Limiter = NewBandwidthLimiter(opts.LimitRate)
+	}
+
 	if opts.ChunkSize > 0 {
 		config.ChunkSize = opts.ChunkSize
 	}
@@ -169,9 +201,20 @@ func (ed *EnhancedDownloader) checkResumeSupport(ctx context.Context, url, dest
 
 // checkRangeSupport 检查Range请求支持
 func (ed *EnhancedDownloader) checkRangeSupport(ctx context.Context, url string) (bool, int64, error) {
+	remote, err := ed.headRemote(ctx, url)
+	if err != nil {
+		return false, 0, err
+	}
+	return true, remote.Size, nil
+}
+
+// headRemote 通过HEAD请求获取支持Range请求的远程文件的大小和校验值
+func (ed *EnhancedDownloader) headRemote(ctx context.Context, url string) (*remoteFile, error) {
+	var remote *remoteFile
 	operation := func(ctx context.Context, attempt int) error {
 		resp, err := ed.client.R().
 			SetContext(ctx).
+			SetHeaders(ed.headers).
 			Head(url)
 
 		if err != nil {
@@ -194,10 +237,16 @@ func (ed *EnhancedDownloader) checkRangeSupport(ctx context.Context, url string)
 			return fmt.Errorf("server did not provide content length")
 		}
 
-		if _, err = strconv.ParseInt(contentLength, 10, 64); err != nil {
+		size, err := strconv.ParseInt(contentLength, 10, 64)
+		if err != nil {
 			return fmt.Errorf("invalid content length: %w", err)
 		}
 
+		remote = &remoteFile{
+			Size:         size,
+			ETag:         resp.Header().Get("ETag"),
+			LastModified: resp.Header().Get("Last-Modified"),
+		}
 		return nil
 	}
 
@@ -208,26 +257,21 @@ func (ed *EnhancedDownloader) checkRangeSupport(ctx context.Context, url string)
 	})
 
 	if !result.Success {
-		return false, 0, result.LastError
-	}
-
-	// 重新获取文件大小（这里简化处理）
-	resp, err := ed.client.R().SetContext(ctx).Head(url)
-	if err != nil {
-		return false, 0, err
+		return nil, result.LastError
 	}
-
-	contentLength := resp.Header().Get("Content-Length")
-	size, _ := strconv.ParseInt(contentLength, 10, 64)
-
-	return true, size, nil
+	return remote, nil
 }
 
 // executeDownload 执行下载
 func (ed *EnhancedDownloader) executeDownload(ctx context.Context, url, dest string, opts *types.DownloadOptions) error {
+	if limiter := ed.convertOptions(opts).Limiter; limiter != nil {
+		return ed.executeLimitedDownload(ctx, url, dest, limiter)
+	}
+
 	// 简化实现，直接使用resty下载
 	resp, err := ed.client.R().
 		SetContext(ctx).
+		SetHeaders(ed.headers).
 		SetOutput(dest).
 		Get(url)
 
@@ -242,6 +286,35 @@ func (ed *EnhancedDownloader) executeDownload(ctx context.Context, url, dest str
 	return nil
 }
 
+// executeLimitedDownload 按限速器读取响应体并写入dest
+func (ed *EnhancedDownloader) executeLimitedDownload(ctx context.Context, url, dest string, limiter *BandwidthLimiter) error {
+	resp, err := ed.client.R().
+		SetContext(ctx).
+		SetHeaders(ed.headers).
+		SetDoNotParseResponse(true).
+		Get(url)
+	if err != nil {
+		return err
+	}
+	body := resp.RawBody()
+	defer body.Close()
+
+	if resp.StatusCode() != 200 {
+		return fmt.Errorf("download failed with status: %d", resp.StatusCode())
+	}
+
+	file, err := os.Create(dest)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := io.Copy(file, limiter.Reader(ctx, body)); err != nil {
+		return CreateNetworkError(fmt.Errorf("failed to copy data: %w", err), url, 0)
+	}
+	return file.Close()
+}
+
 // openFile 打开文件
 func (ed *EnhancedDownloader) openFile(dest string, resume bool) (*os.File, error) {
 	if resume {
@@ -259,21 +332,25 @@ func (ed *EnhancedDownloader) downloadWithRetry(ctx context.Context, url string,
 
 // verifyChecksum 验证校验和
 func (ed *EnhancedDownloader) verifyChecksum(filePath, checksumType, expectedSum string) error {
-	// 这里可以实现具体的校验和验证逻辑
-	// 为了简化，暂时返回nil
-	return nil
+	if checksumType != "" && !strings.EqualFold(checksumType, "sha256") {
+		return fmt.Errorf("unsupported checksum type: %s", checksumType)
+	}
+	return verifyFileChecksum(filePath, expectedSum)
 }
 
-// extractHost 提取主机名
-func extractHost(url string) string {
-	// 简化实现，实际应该解析URL
-	return url
+// extractHost 提取主机名，作为重试统计和熔断器的键
+func extractHost(rawURL string) string {
+	u, err := neturl.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+	return u.Host
 }
 
 // DownloadWithProgress 带进度的下载
 func (ed *EnhancedDownloader) DownloadWithProgress(ctx context.Context, url, dest string, opts *types.DownloadOptions, progressCallback func(downloaded, total int64)) error {
 	// 获取文件大小
-	resp, err := ed.client.R().Head(url)
+	resp, err := ed.client.R().SetHeaders(ed.headers).Head(url)
 	if err != nil {
 		return err
 	}
@@ -287,7 +364,7 @@ func (ed *EnhancedDownloader) DownloadWithProgress(ctx context.Context, url, des
 
 // getFileSize 获取文件大小
 func (ed *EnhancedDownloader) getFileSize(ctx context.Context, url string) (int64, error) {
-	resp, err := ed.client.R().SetContext(ctx).Head(url)
+	resp, err := ed.client.R().SetContext(ctx).SetHeaders(ed.headers).Head(url)
 	if err != nil {
 		return 0, err
 	}
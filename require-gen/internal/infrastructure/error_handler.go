@@ -18,7 +18,7 @@ type NetworkErrorHandler struct {
 	config          *ErrorHandlerConfig
 	retryStrategies map[types.NetworkErrorType]RetryStrategy
 	errorStats      *ErrorStatistics
-	circuitBreaker  *CircuitBreaker
+	circuitBreakers map[string]*CircuitBreaker // 按主机的熔断器，一个主机不可用不影响其他主机（如镜像）
 }
 
 // ErrorHandlerConfig 错误处理器配置
@@ -162,24 +162,58 @@ func NewNetworkErrorHandler(config *ErrorHandlerConfig) *NetworkErrorHandler {
 			ErrorsByHost: make(map[string]int64),
 			StartTime:    time.Now(),
 		},
+		circuitBreakers: make(map[string]*CircuitBreaker),
 	}
 
 	// 初始化重试策略
 	handler.initRetryStrategies()
 
-	// 初始化熔断器
-	if config.EnableCircuitBreaker {
-		handler.circuitBreaker = &CircuitBreaker{
+	return handler
+}
+
+// circuitBreaker 获取主机的熔断器，首次使用时创建；未启用熔断器时返回nil
+func (neh *NetworkErrorHandler) circuitBreaker(host string) *CircuitBreaker {
+	if !neh.config.EnableCircuitBreaker {
+		return nil
+	}
+
+	neh.mu.Lock()
+	defer neh.mu.Unlock()
+
+	cb, ok := neh.circuitBreakers[host]
+	if !ok {
+		cb = &CircuitBreaker{
 			state: CircuitClosed,
 			config: &CircuitBreakerConfig{
-				FailureThreshold: config.FailureThreshold,
-				RecoveryTimeout:  config.RecoveryTimeout,
+				FailureThreshold: neh.config.FailureThreshold,
+				RecoveryTimeout:  neh.config.RecoveryTimeout,
 				MaxRequests:      10,
 			},
 		}
+		neh.circuitBreakers[host] = cb
 	}
+	return cb
+}
 
-	return handler
+// AllowRequest 主机的熔断器是否允许请求
+func (neh *NetworkErrorHandler) AllowRequest(host string) bool {
+	cb := neh.circuitBreaker(host)
+	return cb == nil || cb.AllowRequest()
+}
+
+// RecordSuccess 记录主机的请求成功，关闭半开的熔断器
+func (neh *NetworkErrorHandler) RecordSuccess(host string) {
+	if cb := neh.circuitBreaker(host); cb != nil {
+		cb.RecordSuccess()
+	}
+}
+
+// GetCircuitState 获取主机的熔断器状态
+func (neh *NetworkErrorHandler) GetCircuitState(host string) CircuitState {
+	if cb := neh.circuitBreaker(host); cb != nil {
+		return cb.GetState()
+	}
+	return CircuitClosed
 }
 
 // initRetryStrategies 初始化重试策略
@@ -231,10 +265,11 @@ func (neh *NetworkErrorHandler) HandleError(ctx context.Context, err error, host
 	// 记录错误统计
 	neh.recordError(networkErr, host)
 
-	// 检查熔断器状态
-	if neh.circuitBreaker != nil && !neh.circuitBreaker.AllowRequest() {
+	// 检查熔断器状态，主机已熔断时不再重试
+	if cb := neh.circuitBreaker(host); cb != nil && !cb.AllowRequest() {
 		networkErr.Type = types.NetworkErrorCircuitOpen
 		networkErr.Message = "Circuit breaker is open"
+		networkErr.Retryable = false
 		return networkErr
 	}
 
@@ -450,8 +485,8 @@ func (neh *NetworkErrorHandler) recordError(networkErr *types.NetworkError, host
 	}
 
 	// 更新熔断器
-	if neh.circuitBreaker != nil {
-		neh.circuitBreaker.RecordFailure()
+	if cb := neh.circuitBreaker(host); cb != nil {
+		cb.RecordFailure()
 	}
 }
 
@@ -477,9 +512,9 @@ func (neh *NetworkErrorHandler) Reset() {
 	neh.errorStats.ErrorHistory = nil
 	neh.errorStats.StartTime = time.Now()
 
-	if neh.circuitBreaker != nil {
-		neh.circuitBreaker.Reset()
-	}
+	neh.mu.Lock()
+	neh.circuitBreakers = make(map[string]*CircuitBreaker)
+	neh.mu.Unlock()
 }
 
 // CircuitBreaker methods
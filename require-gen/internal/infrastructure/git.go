@@ -1,41 +1,69 @@
 package infrastructure
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"specify-cli/internal/types"
 )
 
 // GitOperations Git操作实现
-type GitOperations struct{}
+type GitOperations struct {
+	logger *slog.Logger
+}
 
 // NewGitOperations 创建新的Git操作实例
 func NewGitOperations() types.GitOperations {
-	return &GitOperations{}
+	return &GitOperations{logger: DefaultLogger()}
+}
+
+// SetLogger 设置日志器
+func (g *GitOperations) SetLogger(logger *slog.Logger) {
+	if logger != nil {
+		g.logger = logger
+	}
+}
+
+// getLogger 获取当前日志器，未设置时使用进程级默认日志器
+func (g *GitOperations) getLogger() *slog.Logger {
+	if g.logger == nil {
+		return DefaultLogger()
+	}
+	return g.logger
+}
+
+// command 创建在dir中执行的git命令，并以Debug级别记录命令行
+func (g *GitOperations) command(ctx context.Context, dir string, args ...string) *exec.Cmd {
+	g.getLogger().Debug("git command", "args", args, "dir", dir)
+
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	return cmd
 }
 
 // IsRepo 检查指定路径是否为Git仓库
-func (g *GitOperations) IsRepo(path string) bool {
+func (g *GitOperations) IsRepo(ctx context.Context, path string) bool {
 	gitDir := filepath.Join(path, ".git")
 	if stat, err := os.Stat(gitDir); err == nil {
 		return stat.IsDir()
 	}
 	
 	// 检查是否在Git工作树中
-	cmd := exec.Command("git", "rev-parse", "--git-dir")
-	cmd.Dir = path
+	cmd := g.command(ctx, path, "rev-parse", "--git-dir")
 	err := cmd.Run()
 	return err == nil
 }
 
 // InitRepo 初始化Git仓库
-func (g *GitOperations) InitRepo(path string, quiet bool) (bool, error) {
+func (g *GitOperations) InitRepo(ctx context.Context, path string, quiet bool) (bool, error) {
 	// 检查是否已经是Git仓库
-	if g.IsRepo(path) {
+	if g.IsRepo(ctx, path) {
 		return false, nil
 	}
 
@@ -45,8 +73,7 @@ func (g *GitOperations) InitRepo(path string, quiet bool) (bool, error) {
 		args = append(args, "--quiet")
 	}
 
-	cmd := exec.Command("git", args...)
-	cmd.Dir = path
+	cmd := g.command(ctx, path, args...)
 	
 	// 执行命令
 	output, err := cmd.CombinedOutput()
@@ -58,22 +85,20 @@ func (g *GitOperations) InitRepo(path string, quiet bool) (bool, error) {
 }
 
 // AddAndCommit 添加文件并提交
-func (g *GitOperations) AddAndCommit(path string, message string) error {
+func (g *GitOperations) AddAndCommit(ctx context.Context, path string, message string) error {
 	// 检查是否为Git仓库
-	if !g.IsRepo(path) {
+	if !g.IsRepo(ctx, path) {
 		return fmt.Errorf("not a git repository: %s", path)
 	}
 
 	// 添加所有文件
-	addCmd := exec.Command("git", "add", ".")
-	addCmd.Dir = path
+	addCmd := g.command(ctx, path, "add", ".")
 	if output, err := addCmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("git add failed: %w, output: %s", err, string(output))
 	}
 
 	// 检查是否有文件需要提交
-	statusCmd := exec.Command("git", "status", "--porcelain")
-	statusCmd.Dir = path
+	statusCmd := g.command(ctx, path, "status", "--porcelain")
 	statusOutput, err := statusCmd.Output()
 	if err != nil {
 		return fmt.Errorf("git status failed: %w", err)
@@ -85,8 +110,7 @@ func (g *GitOperations) AddAndCommit(path string, message string) error {
 	}
 
 	// 提交变更
-	commitCmd := exec.Command("git", "commit", "-m", message)
-	commitCmd.Dir = path
+	commitCmd := g.command(ctx, path, "commit", "-m", message)
 	if output, err := commitCmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("git commit failed: %w, output: %s", err, string(output))
 	}
@@ -95,13 +119,12 @@ func (g *GitOperations) AddAndCommit(path string, message string) error {
 }
 
 // GetStatus 获取Git状态
-func (g *GitOperations) GetStatus(path string) (string, error) {
-	if !g.IsRepo(path) {
+func (g *GitOperations) GetStatus(ctx context.Context, path string) (string, error) {
+	if !g.IsRepo(ctx, path) {
 		return "", fmt.Errorf("not a git repository: %s", path)
 	}
 
-	cmd := exec.Command("git", "status", "--porcelain")
-	cmd.Dir = path
+	cmd := g.command(ctx, path, "status", "--porcelain")
 	output, err := cmd.Output()
 	if err != nil {
 		return "", fmt.Errorf("git status failed: %w", err)
@@ -111,13 +134,12 @@ func (g *GitOperations) GetStatus(path string) (string, error) {
 }
 
 // GetBranch 获取当前分支
-func (g *GitOperations) GetBranch(path string) (string, error) {
-	if !g.IsRepo(path) {
+func (g *GitOperations) GetBranch(ctx context.Context, path string) (string, error) {
+	if !g.IsRepo(ctx, path) {
 		return "", fmt.Errorf("not a git repository: %s", path)
 	}
 
-	cmd := exec.Command("git", "branch", "--show-current")
-	cmd.Dir = path
+	cmd := g.command(ctx, path, "branch", "--show-current")
 	output, err := cmd.Output()
 	if err != nil {
 		return "", fmt.Errorf("git branch failed: %w", err)
@@ -127,13 +149,12 @@ func (g *GitOperations) GetBranch(path string) (string, error) {
 }
 
 // CreateBranch 创建新分支
-func (g *GitOperations) CreateBranch(path, branchName string) error {
-	if !g.IsRepo(path) {
+func (g *GitOperations) CreateBranch(ctx context.Context, path, branchName string) error {
+	if !g.IsRepo(ctx, path) {
 		return fmt.Errorf("not a git repository: %s", path)
 	}
 
-	cmd := exec.Command("git", "checkout", "-b", branchName)
-	cmd.Dir = path
+	cmd := g.command(ctx, path, "checkout", "-b", branchName)
 	if output, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("git checkout -b failed: %w, output: %s", err, string(output))
 	}
@@ -142,13 +163,12 @@ func (g *GitOperations) CreateBranch(path, branchName string) error {
 }
 
 // SwitchBranch 切换分支
-func (g *GitOperations) SwitchBranch(path, branchName string) error {
-	if !g.IsRepo(path) {
+func (g *GitOperations) SwitchBranch(ctx context.Context, path, branchName string) error {
+	if !g.IsRepo(ctx, path) {
 		return fmt.Errorf("not a git repository: %s", path)
 	}
 
-	cmd := exec.Command("git", "checkout", branchName)
-	cmd.Dir = path
+	cmd := g.command(ctx, path, "checkout", branchName)
 	if output, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("git checkout failed: %w, output: %s", err, string(output))
 	}
@@ -157,13 +177,12 @@ func (g *GitOperations) SwitchBranch(path, branchName string) error {
 }
 
 // AddRemote 添加远程仓库
-func (g *GitOperations) AddRemote(path, name, url string) error {
-	if !g.IsRepo(path) {
+func (g *GitOperations) AddRemote(ctx context.Context, path, name, url string) error {
+	if !g.IsRepo(ctx, path) {
 		return fmt.Errorf("not a git repository: %s", path)
 	}
 
-	cmd := exec.Command("git", "remote", "add", name, url)
-	cmd.Dir = path
+	cmd := g.command(ctx, path, "remote", "add", name, url)
 	if output, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("git remote add failed: %w, output: %s", err, string(output))
 	}
@@ -172,13 +191,12 @@ func (g *GitOperations) AddRemote(path, name, url string) error {
 }
 
 // Push 推送到远程仓库
-func (g *GitOperations) Push(path, remote, branch string) error {
-	if !g.IsRepo(path) {
+func (g *GitOperations) Push(ctx context.Context, path, remote, branch string) error {
+	if !g.IsRepo(ctx, path) {
 		return fmt.Errorf("not a git repository: %s", path)
 	}
 
-	cmd := exec.Command("git", "push", remote, branch)
-	cmd.Dir = path
+	cmd := g.command(ctx, path, "push", remote, branch)
 	if output, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("git push failed: %w, output: %s", err, string(output))
 	}
@@ -187,13 +205,12 @@ func (g *GitOperations) Push(path, remote, branch string) error {
 }
 
 // Pull 从远程仓库拉取
-func (g *GitOperations) Pull(path, remote, branch string) error {
-	if !g.IsRepo(path) {
+func (g *GitOperations) Pull(ctx context.Context, path, remote, branch string) error {
+	if !g.IsRepo(ctx, path) {
 		return fmt.Errorf("not a git repository: %s", path)
 	}
 
-	cmd := exec.Command("git", "pull", remote, branch)
-	cmd.Dir = path
+	cmd := g.command(ctx, path, "pull", remote, branch)
 	if output, err := cmd.CombinedOutput(); err != nil {
 		return fmt.Errorf("git pull failed: %w, output: %s", err, string(output))
 	}
@@ -202,23 +219,56 @@ func (g *GitOperations) Pull(path, remote, branch string) error {
 }
 
 // Clone 克隆仓库
-func (g *GitOperations) Clone(url, targetPath string) error {
-	cmd := exec.Command("git", "clone", url, targetPath)
-	if output, err := cmd.CombinedOutput(); err != nil {
-		return fmt.Errorf("git clone failed: %w, output: %s", err, string(output))
+//
+// opts为nil时完整克隆默认分支。opts.Depth大于0时进行浅克隆；
+// 指定opts.Ref时先初始化空仓库再按引用获取并检出，
+// 这样分支、标签和提交哈希都可以用同一种方式浅克隆。
+func (g *GitOperations) Clone(ctx context.Context, url, targetPath string, opts *types.CloneOptions) error {
+	if opts == nil {
+		opts = &types.CloneOptions{}
+	}
+
+	var depthArgs []string
+	if opts.Depth > 0 {
+		depthArgs = []string{"--depth", strconv.Itoa(opts.Depth)}
+	}
+
+	if opts.Ref == "" {
+		args := append([]string{"clone", "--quiet"}, depthArgs...)
+		cmd := g.command(ctx, "", append(args, url, targetPath)...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("git clone failed: %w, output: %s", err, string(output))
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(targetPath, 0755); err != nil {
+		return fmt.Errorf("failed to create clone directory: %w", err)
+	}
+
+	steps := [][]string{
+		{"init", "--quiet"},
+		{"remote", "add", "origin", url},
+		append(append([]string{"fetch", "--quiet"}, depthArgs...), "origin", opts.Ref),
+		{"checkout", "--quiet", "FETCH_HEAD"},
+	}
+	for _, args := range steps {
+		cmd := g.command(ctx, targetPath, args...)
+		if output, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("git %s failed: %w, output: %s", args[0], err, string(output))
+		}
 	}
 
 	return nil
 }
 
 // GetCommitHash 获取当前提交哈希
-func (g *GitOperations) GetCommitHash(path string) (string, error) {
-	if !g.IsRepo(path) {
+func (g *GitOperations) GetCommitHash(ctx context.Context, path string) (string, error) {
+	if !g.IsRepo(ctx, path) {
 		return "", fmt.Errorf("not a git repository: %s", path)
 	}
 
-	cmd := exec.Command("git", "rev-parse", "HEAD")
-	cmd.Dir = path
+	cmd := g.command(ctx, path, "rev-parse", "HEAD")
 	output, err := cmd.Output()
 	if err != nil {
 		return "", fmt.Errorf("git rev-parse failed: %w", err)
@@ -228,13 +278,12 @@ func (g *GitOperations) GetCommitHash(path string) (string, error) {
 }
 
 // GetRemoteURL 获取远程仓库URL
-func (g *GitOperations) GetRemoteURL(path, remote string) (string, error) {
-	if !g.IsRepo(path) {
+func (g *GitOperations) GetRemoteURL(ctx context.Context, path, remote string) (string, error) {
+	if !g.IsRepo(ctx, path) {
 		return "", fmt.Errorf("not a git repository: %s", path)
 	}
 
-	cmd := exec.Command("git", "remote", "get-url", remote)
-	cmd.Dir = path
+	cmd := g.command(ctx, path, "remote", "get-url", remote)
 	output, err := cmd.Output()
 	if err != nil {
 		return "", fmt.Errorf("git remote get-url failed: %w", err)
@@ -244,8 +293,8 @@ func (g *GitOperations) GetRemoteURL(path, remote string) (string, error) {
 }
 
 // IsClean 检查工作目录是否干净
-func (g *GitOperations) IsClean(path string) (bool, error) {
-	status, err := g.GetStatus(path)
+func (g *GitOperations) IsClean(ctx context.Context, path string) (bool, error) {
+	status, err := g.GetStatus(ctx, path)
 	if err != nil {
 		return false, err
 	}
@@ -254,7 +303,7 @@ func (g *GitOperations) IsClean(path string) (bool, error) {
 }
 
 // HasUncommittedChanges 检查是否有未提交的变更
-func (g *GitOperations) HasUncommittedChanges(path string) (bool, error) {
-	clean, err := g.IsClean(path)
+func (g *GitOperations) HasUncommittedChanges(ctx context.Context, path string) (bool, error) {
+	clean, err := g.IsClean(ctx, path)
 	return !clean, err
 }
\ No newline at end of file
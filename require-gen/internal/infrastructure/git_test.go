@@ -1,6 +1,7 @@
 package infrastructure
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -18,7 +19,7 @@ func TestGitOperations_IsRepo(t *testing.T) {
 		defer os.RemoveAll(tempDir)
 
 		// 测试非Git目录
-		isRepo := git.IsRepo(tempDir)
+		isRepo := git.IsRepo(context.Background(), tempDir)
 		if isRepo {
 			t.Error("期望非Git目录返回false，但返回了true")
 		}
@@ -33,7 +34,7 @@ func TestGitOperations_IsRepo(t *testing.T) {
 		defer os.RemoveAll(tempDir)
 
 		// 初始化Git仓库
-		success, err := git.InitRepo(tempDir, true)
+		success, err := git.InitRepo(context.Background(), tempDir, true)
 		if err != nil {
 			t.Fatalf("初始化Git仓库失败: %v", err)
 		}
@@ -42,7 +43,7 @@ func TestGitOperations_IsRepo(t *testing.T) {
 		}
 
 		// 测试Git目录
-		isRepo := git.IsRepo(tempDir)
+		isRepo := git.IsRepo(context.Background(), tempDir)
 		if !isRepo {
 			t.Error("期望Git目录返回true，但返回了false")
 		}
@@ -50,7 +51,7 @@ func TestGitOperations_IsRepo(t *testing.T) {
 
 	t.Run("检测不存在的目录", func(t *testing.T) {
 		nonExistentPath := filepath.Join(os.TempDir(), "non_existent_dir_12345")
-		isRepo := git.IsRepo(nonExistentPath)
+		isRepo := git.IsRepo(context.Background(), nonExistentPath)
 		if isRepo {
 			t.Error("期望不存在的目录返回false，但返回了true")
 		}
@@ -69,7 +70,7 @@ func TestGitOperations_InitRepo(t *testing.T) {
 		defer os.RemoveAll(tempDir)
 
 		// 初始化Git仓库
-		success, err := git.InitRepo(tempDir, false)
+		success, err := git.InitRepo(context.Background(), tempDir, false)
 		if err != nil {
 			t.Fatalf("初始化Git仓库失败: %v", err)
 		}
@@ -84,7 +85,7 @@ func TestGitOperations_InitRepo(t *testing.T) {
 		}
 
 		// 验证IsRepo返回true
-		if !git.IsRepo(tempDir) {
+		if !git.IsRepo(context.Background(), tempDir) {
 			t.Error("初始化后IsRepo应该返回true")
 		}
 	})
@@ -98,7 +99,7 @@ func TestGitOperations_InitRepo(t *testing.T) {
 		defer os.RemoveAll(tempDir)
 
 		// 第一次初始化
-		success, err := git.InitRepo(tempDir, true)
+		success, err := git.InitRepo(context.Background(), tempDir, true)
 		if err != nil {
 			t.Fatalf("第一次初始化失败: %v", err)
 		}
@@ -107,7 +108,7 @@ func TestGitOperations_InitRepo(t *testing.T) {
 		}
 
 		// 第二次初始化（应该返回false，因为已经是Git仓库）
-		success, err = git.InitRepo(tempDir, true)
+		success, err = git.InitRepo(context.Background(), tempDir, true)
 		if err != nil {
 			t.Fatalf("第二次初始化出错: %v", err)
 		}
@@ -125,7 +126,7 @@ func TestGitOperations_InitRepo(t *testing.T) {
 		defer os.RemoveAll(tempDir)
 
 		// 使用quiet模式初始化
-		success, err := git.InitRepo(tempDir, true)
+		success, err := git.InitRepo(context.Background(), tempDir, true)
 		if err != nil {
 			t.Fatalf("quiet模式初始化失败: %v", err)
 		}
@@ -134,7 +135,7 @@ func TestGitOperations_InitRepo(t *testing.T) {
 		}
 
 		// 验证仓库确实被创建
-		if !git.IsRepo(tempDir) {
+		if !git.IsRepo(context.Background(), tempDir) {
 			t.Error("quiet模式初始化后IsRepo应该返回true")
 		}
 	})
@@ -152,7 +153,7 @@ func TestGitOperations_AddAndCommit(t *testing.T) {
 		defer os.RemoveAll(tempDir)
 
 		// 初始化Git仓库
-		success, err := git.InitRepo(tempDir, true)
+		success, err := git.InitRepo(context.Background(), tempDir, true)
 		if err != nil {
 			t.Fatalf("初始化Git仓库失败: %v", err)
 		}
@@ -168,13 +169,13 @@ func TestGitOperations_AddAndCommit(t *testing.T) {
 		}
 
 		// 添加并提交文件
-		err = git.AddAndCommit(tempDir, "Initial commit with test file")
+		err = git.AddAndCommit(context.Background(), tempDir, "Initial commit with test file")
 		if err != nil {
 			t.Fatalf("添加和提交文件失败: %v", err)
 		}
 
 		// 验证提交成功（通过检查状态）
-		status, err := git.GetStatus(tempDir)
+		status, err := git.GetStatus(context.Background(), tempDir)
 		if err != nil {
 			t.Fatalf("获取Git状态失败: %v", err)
 		}
@@ -194,7 +195,7 @@ func TestGitOperations_AddAndCommit(t *testing.T) {
 		defer os.RemoveAll(tempDir)
 
 		// 尝试在非Git目录中提交
-		err = git.AddAndCommit(tempDir, "This should fail")
+		err = git.AddAndCommit(context.Background(), tempDir, "This should fail")
 		if err == nil {
 			t.Error("期望在非Git目录中提交失败，但成功了")
 		}
@@ -215,7 +216,7 @@ func TestGitOperations_AddAndCommit(t *testing.T) {
 		defer os.RemoveAll(tempDir)
 
 		// 初始化Git仓库
-		success, err := git.InitRepo(tempDir, true)
+		success, err := git.InitRepo(context.Background(), tempDir, true)
 		if err != nil {
 			t.Fatalf("初始化Git仓库失败: %v", err)
 		}
@@ -224,7 +225,7 @@ func TestGitOperations_AddAndCommit(t *testing.T) {
 		}
 
 		// 尝试提交（没有文件变更）
-		err = git.AddAndCommit(tempDir, "Empty commit")
+		err = git.AddAndCommit(context.Background(), tempDir, "Empty commit")
 		if err != nil {
 			t.Fatalf("空提交失败: %v", err)
 		}
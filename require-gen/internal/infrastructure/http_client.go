@@ -4,7 +4,9 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"net/http/httptrace"
 	"strings"
 	"sync"
 	"time"
@@ -22,6 +24,7 @@ type HTTPClientManager struct {
 	connPool      *ConnectionPoolManager
 	errorHandler  *NetworkErrorHandler
 	retryManager  *RetryManager
+	logger        *slog.Logger
 }
 
 // NewHTTPClientManager 创建HTTP客户端管理器
@@ -38,6 +41,7 @@ func NewHTTPClientManager(defaultConfig *types.HTTPClientConfig) *HTTPClientMana
 		connPool: NewConnectionPoolManager(),
 		errorHandler: errorHandler,
 		retryManager: NewRetryManager(DefaultRetryManagerConfig(), errorHandler),
+		logger:       DefaultLogger(),
 	}
 	
 	// 创建默认客户端
@@ -65,6 +69,26 @@ func DefaultHTTPClientConfig() *types.HTTPClientConfig {
 	}
 }
 
+// SetLogger 设置日志器
+//
+// 已创建客户端的中间件在每次请求时读取日志器，因此替换立即生效。
+func (hcm *HTTPClientManager) SetLogger(logger *slog.Logger) {
+	if logger == nil {
+		return
+	}
+	hcm.mu.Lock()
+	hcm.logger = logger
+	hcm.mu.Unlock()
+	hcm.retryManager.SetLogger(logger)
+}
+
+// getLogger 获取当前日志器
+func (hcm *HTTPClientManager) getLogger() *slog.Logger {
+	hcm.mu.RLock()
+	defer hcm.mu.RUnlock()
+	return hcm.logger
+}
+
 // GetDefaultClient 获取默认客户端
 func (hcm *HTTPClientManager) GetDefaultClient() *resty.Client {
 	hcm.mu.RLock()
@@ -175,6 +199,7 @@ func (hcm *HTTPClientManager) createTransport(config *types.HTTPClientConfig) *h
 	
 	// 创建默认传输层
 	transport := &http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
 		MaxIdleConns:        config.MaxIdleConns,
 		MaxIdleConnsPerHost: config.MaxConnsPerHost,
 		IdleConnTimeout:     config.IdleConnTimeout,
@@ -275,6 +300,12 @@ func (hcm *HTTPClientManager) createRetryCondition() resty.RetryConditionFunc {
 		
 		// HTTP状态码重试条件
 		statusCode := r.StatusCode()
+
+		// 速率限制通常要到整点才重置，立即重试只会继续消耗配额，
+		// 由调用方根据RateLimitError决定等待或使用缓存
+		if isRateLimited(statusCode, ParseRateLimit(r.Header())) {
+			return false
+		}
 		
 		// 5xx服务器错误重试
 		if statusCode >= 500 && statusCode < 600 {
@@ -294,28 +325,236 @@ func (hcm *HTTPClientManager) createRetryCondition() resty.RetryConditionFunc {
 }
 
 // setupMiddleware 设置中间件
+//
+// 日志器启用Debug级别（--debug）时，每个请求都会开启httptrace跟踪，
+// 响应日志包含状态码、耗时分解（DNS、连接、TLS、首字节）以及
+// 请求和响应头。Authorization等敏感头在记录前会被脱敏。
+// 重试和剩余API配额不足在Info级别记录，失败在Warn级别记录。
 func (hcm *HTTPClientManager) setupMiddleware(client *resty.Client) {
 	// 请求中间件
 	client.OnBeforeRequest(func(c *resty.Client, req *resty.Request) error {
 		// 添加请求ID
-		req.SetHeader("X-Request-ID", generateRequestID())
+		requestID := generateRequestID()
+		req.SetHeader("X-Request-ID", requestID)
+
+		logger := hcm.getLogger()
+		if logger.Enabled(req.Context(), slog.LevelDebug) {
+			// resty基于net/http/httptrace收集各阶段耗时
+			req.EnableTrace()
+			logger.Debug("http request",
+				"method", req.Method, "url", req.URL, "request_id", requestID)
+		}
 		return nil
 	})
 	
 	// 响应中间件
 	client.OnAfterResponse(func(c *resty.Client, resp *resty.Response) error {
+		logger := hcm.getLogger()
+
+		// 剩余配额不足10%时提示，便于在被限制前发现问题
+		if rl := ParseRateLimit(resp.Header()); rl != nil && rl.Limit > 0 && rl.Remaining*10 < rl.Limit {
+			logger.Info("API rate limit running low", "url", resp.Request.URL,
+				"remaining", rl.Remaining, "limit", rl.Limit, "reset", rl.Reset.Format(time.RFC3339))
+		}
+
 		// 记录响应日志
-		fmt.Printf("Response: %d %s\n", resp.StatusCode(), resp.Status())
+		if !logger.Enabled(resp.Request.Context(), slog.LevelDebug) {
+			return nil
+		}
+
+		attrs := []interface{}{
+			"method", resp.Request.Method,
+			"url", resp.Request.URL,
+			"status", resp.StatusCode(),
+			"request_id", resp.Request.Header.Get("X-Request-ID"),
+			traceAttrs(resp.Request.TraceInfo()),
+		}
+		if resp.Request.RawRequest != nil {
+			attrs = append(attrs, "request_headers", redactHeaders(resp.Request.RawRequest.Header))
+		}
+		attrs = append(attrs, "response_headers", redactHeaders(resp.Header()))
+
+		logger.Debug("http response", attrs...)
 		return nil
 	})
+
+	// 重试钩子
+	client.AddRetryHook(func(resp *resty.Response, err error) {
+		if resp == nil || resp.Request == nil {
+			return
+		}
+		attrs := []interface{}{"method", resp.Request.Method, "url", resp.Request.URL,
+			"attempt", resp.Request.Attempt}
+		if err != nil {
+			attrs = append(attrs, "error", err)
+		} else {
+			attrs = append(attrs, "status", resp.StatusCode())
+		}
+		hcm.getLogger().Info("retrying http request", attrs...)
+	})
 	
 	// 错误中间件
 	client.OnError(func(req *resty.Request, err error) {
 		// 记录错误日志
-		fmt.Printf("Error: %s %s - %v\n", req.Method, req.URL, err)
+		logger := hcm.getLogger()
+		attrs := []interface{}{"method", req.Method, "url", req.URL, "attempt", req.Attempt, "error", err}
+		if logger.Enabled(req.Context(), slog.LevelDebug) {
+			attrs = append(attrs, traceAttrs(req.TraceInfo()))
+		}
+		logger.Warn("http request failed", attrs...)
 	})
 }
 
+// sensitiveHeaders 记录日志前需要脱敏的HTTP头
+var sensitiveHeaders = map[string]bool{
+	"Authorization":       true,
+	"Proxy-Authorization": true,
+	"Private-Token":       true,
+	"Cookie":              true,
+	"Set-Cookie":          true,
+}
+
+// redactHeaders 复制HTTP头并对敏感值脱敏
+//
+// Authorization类头保留认证方案（如 "Bearer"、"token"），
+// 只隐藏凭据本身，便于确认请求是否携带了认证信息。
+func redactHeaders(header http.Header) map[string]string {
+	redacted := make(map[string]string, len(header))
+	for name, values := range header {
+		value := strings.Join(values, ", ")
+		if sensitiveHeaders[http.CanonicalHeaderKey(name)] {
+			value = redactCredential(value)
+		}
+		redacted[name] = value
+	}
+	return redacted
+}
+
+// redactCredential 隐藏凭据，保留认证方案
+func redactCredential(value string) string {
+	if scheme, _, found := strings.Cut(value, " "); found {
+		return scheme + " [REDACTED]"
+	}
+	return "[REDACTED]"
+}
+
+// traceAttrs 将请求跟踪信息转换为日志属性组
+func traceAttrs(ti resty.TraceInfo) slog.Attr {
+	return slog.Group("timing",
+		"dns", ti.DNSLookup,
+		"connect", ti.TCPConnTime,
+		"tls", ti.TLSHandshake,
+		"first_byte", ti.ServerTime,
+		"transfer", ti.ResponseTime,
+		"total", ti.TotalTime,
+		"conn_reused", ti.IsConnReused,
+	)
+}
+
+// loggingTransport 为不经过resty的请求（如流式下载）记录与setupMiddleware相同的日志
+//
+// Debug级别下记录请求、响应状态、耗时分解和脱敏后的请求头；
+// 响应体由调用方流式读取，因此耗时只统计到收到响应头为止。
+type loggingTransport struct {
+	base   http.RoundTripper
+	logger func() *slog.Logger
+}
+
+// newLoggingTransport 包装传输层，logger在每次请求时获取，以便使用最新的日志器
+func newLoggingTransport(base http.RoundTripper, logger func() *slog.Logger) http.RoundTripper {
+	return &loggingTransport{base: base, logger: logger}
+}
+
+// RoundTrip 实现http.RoundTripper接口
+func (t *loggingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	logger := t.logger()
+	requestID := generateRequestID()
+	req = req.Clone(req.Context())
+	req.Header.Set("X-Request-ID", requestID)
+
+	debug := logger.Enabled(req.Context(), slog.LevelDebug)
+	var timing *requestTiming
+	if debug {
+		timing = &requestTiming{start: time.Now()}
+		req = req.WithContext(httptrace.WithClientTrace(req.Context(), timing.clientTrace()))
+		logger.Debug("http request", "method", req.Method, "url", req.URL.String(), "request_id", requestID)
+	}
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		attrs := []interface{}{"method", req.Method, "url", req.URL.String(), "error", err}
+		if debug {
+			attrs = append(attrs, traceAttrs(timing.traceInfo()))
+		}
+		logger.Warn("http request failed", attrs...)
+		return nil, err
+	}
+
+	if debug {
+		logger.Debug("http response",
+			"method", req.Method,
+			"url", req.URL.String(),
+			"status", resp.StatusCode,
+			"request_id", requestID,
+			traceAttrs(timing.traceInfo()),
+			"request_headers", redactHeaders(req.Header),
+			"response_headers", redactHeaders(resp.Header))
+	}
+	return resp, nil
+}
+
+// requestTiming 通过httptrace收集的请求各阶段耗时
+type requestTiming struct {
+	mu                                    sync.Mutex
+	start, dnsStart, connStart, tlsStart  time.Time
+	gotConn                               time.Time
+	dns, connect, tlsHandshake, firstByte time.Duration
+	reused                                bool
+}
+
+// clientTrace 创建记录各阶段耗时的httptrace回调
+func (rt *requestTiming) clientTrace() *httptrace.ClientTrace {
+	record := func(f func()) {
+		rt.mu.Lock()
+		defer rt.mu.Unlock()
+		f()
+	}
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) { record(func() { rt.dnsStart = time.Now() }) },
+		DNSDone:  func(httptrace.DNSDoneInfo) { record(func() { rt.dns = time.Since(rt.dnsStart) }) },
+		ConnectStart: func(string, string) {
+			record(func() { rt.connStart = time.Now() })
+		},
+		ConnectDone: func(string, string, error) {
+			record(func() { rt.connect = time.Since(rt.connStart) })
+		},
+		TLSHandshakeStart: func() { record(func() { rt.tlsStart = time.Now() }) },
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			record(func() { rt.tlsHandshake = time.Since(rt.tlsStart) })
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			record(func() { rt.gotConn, rt.reused = time.Now(), info.Reused })
+		},
+		GotFirstResponseByte: func() {
+			record(func() { rt.firstByte = time.Since(rt.gotConn) })
+		},
+	}
+}
+
+// traceInfo 转换为resty的跟踪信息，与resty请求使用相同的日志字段
+func (rt *requestTiming) traceInfo() resty.TraceInfo {
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	return resty.TraceInfo{
+		DNSLookup:    rt.dns,
+		TCPConnTime:  rt.connect,
+		TLSHandshake: rt.tlsHandshake,
+		ServerTime:   rt.firstByte,
+		TotalTime:    time.Since(rt.start),
+		IsConnReused: rt.reused,
+	}
+}
+
 // HTTPClientPool HTTP客户端池
 type HTTPClientPool struct {
 	manager   *HTTPClientManager
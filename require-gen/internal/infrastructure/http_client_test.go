@@ -0,0 +1,79 @@
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"specify-cli/internal/types"
+)
+
+// TestRedactHeaders 测试敏感HTTP头脱敏
+func TestRedactHeaders(t *testing.T) {
+	header := http.Header{}
+	header.Set("Authorization", "Bearer ghp_secret")
+	header.Set("Proxy-Authorization", "secret")
+	header.Set("Accept", "application/json")
+
+	redacted := redactHeaders(header)
+
+	assert.Equal(t, "Bearer [REDACTED]", redacted["Authorization"])
+	assert.Equal(t, "[REDACTED]", redacted["Proxy-Authorization"])
+	assert.Equal(t, "application/json", redacted["Accept"])
+}
+
+// TestHTTPClientManager_DebugTrace 测试Debug级别下记录请求跟踪且不泄露令牌
+func TestHTTPClientManager_DebugTrace(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-RateLimit-Remaining", "59")
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	manager := NewHTTPClientManager(nil)
+	manager.SetLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	resp, err := manager.GetDefaultClient().R().
+		SetHeader("Authorization", "token ghp_secret").
+		Get(server.URL)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode())
+
+	logs := buf.String()
+	assert.Contains(t, logs, "http response")
+	assert.Contains(t, logs, "timing.first_byte=")
+	assert.Contains(t, logs, "X-Ratelimit-Remaining:59")
+	assert.Contains(t, logs, "token [REDACTED]")
+	assert.NotContains(t, logs, "ghp_secret")
+}
+
+// TestTemplateProvider_StreamingDebugTrace 测试流式下载同样记录请求跟踪且不泄露令牌
+func TestTemplateProvider_StreamingDebugTrace(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.NotEmpty(t, r.Header.Get("X-Request-ID"))
+		w.Write([]byte("template"))
+	}))
+	defer server.Close()
+
+	var buf bytes.Buffer
+	tp := NewTemplateProviderWithConfig(nil, nil).(*TemplateProvider)
+	tp.SetLogger(slog.New(slog.NewTextHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))
+
+	dest := filepath.Join(t.TempDir(), "template.zip")
+	headers := map[string]string{"Authorization": "token ghp_secret"}
+	require.NoError(t, tp.downloadFromSource(context.Background(), server.URL, headers, dest, 8, types.DownloadOptions{ChunkSize: 1024}, nil))
+
+	logs := buf.String()
+	assert.Contains(t, logs, "using streaming downloader")
+	assert.Contains(t, logs, "http response")
+	assert.Contains(t, logs, "timing.first_byte=")
+	assert.Contains(t, logs, "token [REDACTED]")
+	assert.NotContains(t, logs, "ghp_secret")
+}
@@ -0,0 +1,290 @@
+package infrastructure
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"specify-cli/internal/types"
+)
+
+// StagedInstaller 暂存目录安装器
+//
+// StagedInstaller 负责把暂存目录中已经完整生成的项目文件移动到目标目录，
+// 并记录安装过程中的每一处变更，以便在后续步骤失败或用户中断时回滚。
+//
+// 安装策略：
+//   - 目标目录不存在：直接整体重命名暂存目录（同一文件系统上为原子操作），
+//     跨文件系统时先复制到目标目录旁的临时目录，再重命名到位
+//   - 目标目录已存在（--here 或 --force）：逐个文件安装，
+//     对已存在的文件按 --on-conflict 策略处理，覆盖或合并前先备份
+//
+// 回滚策略：
+//   - 删除本次安装新建的文件和目录（按创建的逆序）
+//   - 从备份目录恢复被覆盖或合并的文件
+//   - 目标目录由本次安装创建时直接整体删除
+//
+// 使用示例：
+//
+//	installer := NewStagedInstaller(backupDir)
+//	if err := installer.Install(stagingDir, targetDir, "", false); err != nil {
+//	    installer.Rollback()
+//	}
+type StagedInstaller struct {
+	sysOps        types.SystemOperations
+	resolver      *ZipProcessorImpl // 复用提取时的冲突处理逻辑
+	backupDir     string            // 被覆盖文件的备份目录
+	targetDir     string            // 安装目标目录
+	createdTarget bool              // 目标目录是否由本次安装创建
+	created       []string          // 新建的文件和目录（按创建顺序）
+	backups       map[string]string // 目标文件路径 -> 备份文件路径
+	mu            sync.Mutex
+}
+
+// NewStagedInstaller 创建暂存目录安装器
+func NewStagedInstaller(backupDir string) *StagedInstaller {
+	sysOps := NewSystemOperations()
+	return &StagedInstaller{
+		sysOps:    sysOps,
+		resolver:  &ZipProcessorImpl{sysOps: sysOps},
+		backupDir: backupDir,
+		backups:   make(map[string]string),
+	}
+}
+
+// CreatedTarget 目标目录是否由本次安装创建
+func (si *StagedInstaller) CreatedTarget() bool {
+	si.mu.Lock()
+	defer si.mu.Unlock()
+	return si.createdTarget
+}
+
+// Install 将暂存目录安装到目标目录
+//
+// 参数：
+//
+//	stagingDir - 暂存目录（安装后可能被整体移动而不再存在）
+//	targetDir - 目标目录
+//	onConflict - 冲突策略：skip, overwrite, rename, prompt, merge，空字符串表示覆盖
+//	verbose - 是否输出详细信息
+func (si *StagedInstaller) Install(stagingDir, targetDir string, onConflict string, verbose bool) error {
+	si.mu.Lock()
+	defer si.mu.Unlock()
+
+	si.targetDir = targetDir
+
+	if _, err := os.Stat(targetDir); os.IsNotExist(err) {
+		return si.installNewDirectory(stagingDir, targetDir)
+	}
+
+	opts := &ExtractOptions{
+		OverwriteExisting: true,
+		Verbose:           verbose,
+	}
+	applyConflictPolicy(opts, onConflict, verbose)
+
+	return filepath.WalkDir(stagingDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(stagingDir, path)
+		if err != nil {
+			return err
+		}
+		if rel == "." {
+			return nil
+		}
+		dest := filepath.Join(targetDir, rel)
+
+		if d.IsDir() {
+			if !si.sysOps.DirectoryExists(dest) {
+				if err := os.Mkdir(dest, 0755); err != nil {
+					return fmt.Errorf("failed to create directory %s: %w", rel, err)
+				}
+				si.created = append(si.created, dest)
+			}
+			return nil
+		}
+
+		return si.installFile(path, dest, rel, opts)
+	})
+}
+
+// installNewDirectory 目标目录不存在时整体移动暂存目录
+func (si *StagedInstaller) installNewDirectory(stagingDir, targetDir string) error {
+	parent := filepath.Dir(targetDir)
+	if err := os.MkdirAll(parent, 0755); err != nil {
+		return fmt.Errorf("failed to create parent directory: %w", err)
+	}
+
+	if err := os.Rename(stagingDir, targetDir); err == nil {
+		si.createdTarget = true
+		return chmodProjectDir(targetDir)
+	}
+
+	// 跨文件系统无法直接重命名：先复制到目标目录旁，再原子重命名
+	sibling, err := os.MkdirTemp(parent, "."+filepath.Base(targetDir)+"-*")
+	if err != nil {
+		return fmt.Errorf("failed to create staging directory next to target: %w", err)
+	}
+	if err := copyTree(si.sysOps, stagingDir, sibling); err != nil {
+		os.RemoveAll(sibling)
+		return fmt.Errorf("failed to copy staged files: %w", err)
+	}
+	if err := os.Rename(sibling, targetDir); err != nil {
+		os.RemoveAll(sibling)
+		return fmt.Errorf("failed to move project into place: %w", err)
+	}
+
+	si.createdTarget = true
+	return chmodProjectDir(targetDir)
+}
+
+// chmodProjectDir 将移动到位的项目目录权限设置为0755
+//
+// 暂存目录由os.MkdirTemp创建，权限为0700，重命名后需要恢复为普通目录的权限。
+func chmodProjectDir(targetDir string) error {
+	if err := os.Chmod(targetDir, 0755); err != nil {
+		return fmt.Errorf("failed to set project directory permissions: %w", err)
+	}
+	return nil
+}
+
+// installFile 安装单个文件，处理与现有文件的冲突
+func (si *StagedInstaller) installFile(src, dest, rel string, opts *ExtractOptions) error {
+	if !si.sysOps.FileExists(dest) {
+		if err := si.placeFile(src, dest); err != nil {
+			return fmt.Errorf("failed to install %s: %w", rel, err)
+		}
+		si.created = append(si.created, dest)
+		return nil
+	}
+
+	stat, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	conflictInfo := &FileConflictInfo{
+		SourcePath: rel,
+		TargetPath: dest,
+		Exists:     true,
+		Size:       stat.Size(),
+		ModTime:    stat.ModTime().Unix(),
+		ReadSource: func() ([]byte, error) { return os.ReadFile(src) },
+	}
+
+	finalPath, action, err := si.resolver.handleFileConflict(conflictInfo, opts)
+	if err != nil {
+		return fmt.Errorf("failed to handle file conflict: %w", err)
+	}
+
+	switch action {
+	case ConflictSkip:
+		if opts.Verbose {
+			fmt.Printf("Skipping existing file: %s\n", dest)
+		}
+		return nil
+	case ConflictRename:
+		if err := si.placeFile(src, finalPath); err != nil {
+			return fmt.Errorf("failed to install %s: %w", rel, err)
+		}
+		si.created = append(si.created, finalPath)
+		return nil
+	case ConflictMerge:
+		if err := si.backup(dest, rel); err != nil {
+			return err
+		}
+		return mergeConflictFile(conflictInfo, opts)
+	default:
+		if err := si.backup(dest, rel); err != nil {
+			return err
+		}
+		if err := si.placeFile(src, dest); err != nil {
+			return fmt.Errorf("failed to install %s: %w", rel, err)
+		}
+		return nil
+	}
+}
+
+// placeFile 将暂存文件移动到目标位置，跨文件系统时退化为复制
+func (si *StagedInstaller) placeFile(src, dest string) error {
+	if err := si.sysOps.MoveFile(src, dest); err == nil {
+		return nil
+	}
+	return si.sysOps.CopyFile(src, dest)
+}
+
+// backup 在覆盖前备份现有文件
+func (si *StagedInstaller) backup(dest, rel string) error {
+	if _, exists := si.backups[dest]; exists {
+		return nil
+	}
+	backupPath := filepath.Join(si.backupDir, rel)
+	if err := si.sysOps.CopyFile(dest, backupPath); err != nil {
+		return fmt.Errorf("failed to back up %s: %w", rel, err)
+	}
+	si.backups[dest] = backupPath
+	return nil
+}
+
+// Rollback 撤销安装产生的所有变更
+func (si *StagedInstaller) Rollback() error {
+	si.mu.Lock()
+	defer si.mu.Unlock()
+
+	if si.createdTarget {
+		si.createdTarget = false
+		return os.RemoveAll(si.targetDir)
+	}
+
+	var errs []error
+
+	// 恢复被覆盖的文件
+	restored := make([]string, 0, len(si.backups))
+	for dest := range si.backups {
+		restored = append(restored, dest)
+	}
+	sort.Strings(restored)
+	for _, dest := range restored {
+		if err := si.sysOps.CopyFile(si.backups[dest], dest); err != nil {
+			errs = append(errs, fmt.Errorf("failed to restore %s: %w", dest, err))
+		}
+	}
+
+	// 按创建的逆序删除新建的文件和目录
+	for i := len(si.created) - 1; i >= 0; i-- {
+		if err := os.RemoveAll(si.created[i]); err != nil {
+			errs = append(errs, fmt.Errorf("failed to remove %s: %w", si.created[i], err))
+		}
+	}
+
+	si.created = nil
+	si.backups = make(map[string]string)
+
+	if len(errs) > 0 {
+		return fmt.Errorf("rollback incomplete: %v", errs)
+	}
+	return nil
+}
+
+// copyTree 递归复制目录树
+func copyTree(sysOps types.SystemOperations, src, dest string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dest, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		return sysOps.CopyFile(path, target)
+	})
+}
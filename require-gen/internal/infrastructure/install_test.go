@@ -0,0 +1,94 @@
+package infrastructure
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestStagedInstaller_NewDirectory 测试安装到新目录及回滚
+func TestStagedInstaller_NewDirectory(t *testing.T) {
+	testDir := t.TempDir()
+
+	// 与init一样使用os.MkdirTemp创建暂存目录（权限0700）
+	stagingDir, err := os.MkdirTemp(testDir, "staging-*")
+	require.NoError(t, err)
+	require.NoError(t, os.MkdirAll(filepath.Join(stagingDir, "sub"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(stagingDir, "a.txt"), []byte("a"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(stagingDir, "sub", "b.txt"), []byte("b"), 0644))
+
+	targetDir := filepath.Join(testDir, "project")
+	installer := NewStagedInstaller(filepath.Join(testDir, "backup"))
+
+	require.NoError(t, installer.Install(stagingDir, targetDir, "", false))
+	assert.True(t, installer.CreatedTarget())
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(targetDir)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0755), info.Mode().Perm())
+	}
+	assert.FileExists(t, filepath.Join(targetDir, "a.txt"))
+	assert.FileExists(t, filepath.Join(targetDir, "sub", "b.txt"))
+
+	require.NoError(t, installer.Rollback())
+	assert.NoDirExists(t, targetDir)
+}
+
+// TestStagedInstaller_ExistingDirectory 测试安装到已有目录时的备份与回滚
+func TestStagedInstaller_ExistingDirectory(t *testing.T) {
+	testDir := t.TempDir()
+
+	targetDir := filepath.Join(testDir, "project")
+	require.NoError(t, os.MkdirAll(targetDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(targetDir, "keep.txt"), []byte("original"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(targetDir, "user.txt"), []byte("user"), 0644))
+
+	stagingDir := filepath.Join(testDir, "staging")
+	require.NoError(t, os.MkdirAll(filepath.Join(stagingDir, "sub"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(stagingDir, "keep.txt"), []byte("template"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(stagingDir, "sub", "new.txt"), []byte("new"), 0644))
+
+	backupDir := filepath.Join(testDir, "backup")
+	require.NoError(t, os.MkdirAll(backupDir, 0755))
+	installer := NewStagedInstaller(backupDir)
+
+	require.NoError(t, installer.Install(stagingDir, targetDir, "overwrite", false))
+	assert.False(t, installer.CreatedTarget())
+
+	content, err := os.ReadFile(filepath.Join(targetDir, "keep.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "template", string(content))
+	assert.FileExists(t, filepath.Join(targetDir, "sub", "new.txt"))
+
+	require.NoError(t, installer.Rollback())
+
+	content, err = os.ReadFile(filepath.Join(targetDir, "keep.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "original", string(content))
+	assert.FileExists(t, filepath.Join(targetDir, "user.txt"))
+	assert.NoDirExists(t, filepath.Join(targetDir, "sub"))
+}
+
+// TestStagedInstaller_SkipExisting 测试skip策略保留现有文件
+func TestStagedInstaller_SkipExisting(t *testing.T) {
+	testDir := t.TempDir()
+
+	targetDir := filepath.Join(testDir, "project")
+	require.NoError(t, os.MkdirAll(targetDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(targetDir, "keep.txt"), []byte("original"), 0644))
+
+	stagingDir := filepath.Join(testDir, "staging")
+	require.NoError(t, os.MkdirAll(stagingDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(stagingDir, "keep.txt"), []byte("template"), 0644))
+
+	installer := NewStagedInstaller(filepath.Join(testDir, "backup"))
+	require.NoError(t, installer.Install(stagingDir, targetDir, "skip", false))
+
+	content, err := os.ReadFile(filepath.Join(targetDir, "keep.txt"))
+	require.NoError(t, err)
+	assert.Equal(t, "original", string(content))
+}
@@ -2,6 +2,7 @@ package infrastructure
 
 import (
 	"archive/zip"
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -83,7 +84,7 @@ func TestFullIntegration(t *testing.T) {
 
 	// 第一次提取
 	start := time.Now()
-	err = zipProcessor.ExtractWithProgress(zipPath, extractDir, opts, func(current, total int64) {
+	err = zipProcessor.ExtractWithProgress(context.Background(), zipPath, extractDir, opts, func(current, total int64) {
 		// 额外的进度回调
 	})
 	firstExtractionDuration := time.Since(start)
@@ -121,7 +122,7 @@ func TestFullIntegration(t *testing.T) {
 	conflictEvents = nil
 
 	start = time.Now()
-	err = zipProcessor.ExtractWithProgress(zipPath, extractDir, opts, func(current, total int64) {
+	err = zipProcessor.ExtractWithProgress(context.Background(), zipPath, extractDir, opts, func(current, total int64) {
 		// 额外的进度回调
 	})
 	secondExtractionDuration := time.Since(start)
@@ -212,7 +213,7 @@ func TestLargeFileHandling(t *testing.T) {
 
 	// 执行提取
 	start := time.Now()
-	err = zipProcessor.ExtractWithProgress(zipPath, extractDir, opts, func(current, total int64) {
+	err = zipProcessor.ExtractWithProgress(context.Background(), zipPath, extractDir, opts, func(current, total int64) {
 		// 额外的进度回调
 	})
 	duration := time.Since(start)
@@ -289,7 +290,7 @@ func TestErrorRecovery(t *testing.T) {
 	}
 
 	// 执行提取
-	err = zipProcessor.ExtractWithProgress(zipPath, extractDir, opts, func(current, total int64) {
+	err = zipProcessor.ExtractWithProgress(context.Background(), zipPath, extractDir, opts, func(current, total int64) {
 		// 额外的进度回调
 	})
 	
@@ -348,7 +349,7 @@ func TestConcurrentOperations(t *testing.T) {
 			extractDir := filepath.Join(testDir, fmt.Sprintf("extracted_%d", index))
 			
 			start := time.Now()
-			err := zipProcessor.ExtractWithProgress(path, extractDir, &ExtractOptions{
+			err := zipProcessor.ExtractWithProgress(context.Background(), path, extractDir, &ExtractOptions{
 				Verbose: false,
 			}, func(current, total int64) {
 				// 额外的进度回调
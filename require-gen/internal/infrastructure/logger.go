@@ -0,0 +1,121 @@
+package infrastructure
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+)
+
+// 日志格式
+const (
+	LogFormatText = "text"
+	LogFormatJSON = "json"
+)
+
+// LoggerConfig 日志配置
+//
+// 日志级别由全局标志决定：
+//   - --debug: Debug及以上，包含HTTP请求、git命令、重试等诊断信息
+//   - --verbose: Info及以上
+//   - 默认: 仅Warn及以上
+//
+// File为空时写入标准错误；指定File时以追加方式写入该文件，
+// 便于在提交问题时附上完整日志（建议同时使用 --debug）。
+type LoggerConfig struct {
+	Level  slog.Level // 最低输出级别
+	Format string     // 输出格式：text 或 json
+	File   string     // 日志文件路径，空表示标准错误
+}
+
+// 进程级默认日志器
+var (
+	defaultLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+	loggerMutex   sync.RWMutex
+)
+
+// LogLevelFromFlags 根据 --verbose/--debug 标志确定日志级别
+func LogLevelFromFlags(verbose, debug bool) slog.Level {
+	switch {
+	case debug:
+		return slog.LevelDebug
+	case verbose:
+		return slog.LevelInfo
+	default:
+		return slog.LevelWarn
+	}
+}
+
+// ValidateLogFormat 验证日志格式
+func ValidateLogFormat(format string) error {
+	switch strings.ToLower(format) {
+	case "", LogFormatText, LogFormatJSON:
+		return nil
+	default:
+		return fmt.Errorf("invalid log format '%s' (valid: text, json)", format)
+	}
+}
+
+// NewLogger 根据配置创建日志器
+//
+// 返回的io.Closer用于在命令结束时关闭日志文件；写入标准错误时为空操作。
+func NewLogger(config *LoggerConfig) (*slog.Logger, io.Closer, error) {
+	if config == nil {
+		config = &LoggerConfig{Level: slog.LevelWarn}
+	}
+	if err := ValidateLogFormat(config.Format); err != nil {
+		return nil, nil, err
+	}
+
+	var out io.Writer = os.Stderr
+	var closer io.Closer = nopCloser{}
+	if config.File != "" {
+		file, err := os.OpenFile(config.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to open log file: %w", err)
+		}
+		out = file
+		closer = file
+	}
+
+	handlerOpts := &slog.HandlerOptions{Level: config.Level}
+	var handler slog.Handler
+	if strings.ToLower(config.Format) == LogFormatJSON {
+		handler = slog.NewJSONHandler(out, handlerOpts)
+	} else {
+		handler = slog.NewTextHandler(out, handlerOpts)
+	}
+
+	return slog.New(handler), closer, nil
+}
+
+// SetDefaultLogger 设置进程级默认日志器
+//
+// 之后创建的TemplateProvider、HTTPClientManager、RetryManager、
+// GitOperations和SystemOperations都会使用该日志器；
+// 已创建的实例可以通过各自的SetLogger方法替换。
+func SetDefaultLogger(logger *slog.Logger) {
+	if logger == nil {
+		return
+	}
+	loggerMutex.Lock()
+	defer loggerMutex.Unlock()
+	defaultLogger = logger
+}
+
+// DefaultLogger 获取进程级默认日志器
+//
+// 未配置时返回丢弃所有输出的日志器，调用方无需判空。
+func DefaultLogger() *slog.Logger {
+	loggerMutex.RLock()
+	defer loggerMutex.RUnlock()
+	return defaultLogger
+}
+
+// nopCloser 无需关闭的输出目标
+type nopCloser struct{}
+
+// Close 实现io.Closer接口
+func (nopCloser) Close() error { return nil }
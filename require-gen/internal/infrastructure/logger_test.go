@@ -0,0 +1,54 @@
+package infrastructure
+
+import (
+	"encoding/json"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestNewLogger_JSONFile 测试JSON格式日志写入文件并按级别过滤
+func TestNewLogger_JSONFile(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "specify.log")
+
+	logger, closer, err := NewLogger(&LoggerConfig{
+		Level:  slog.LevelInfo,
+		Format: LogFormatJSON,
+		File:   logPath,
+	})
+	require.NoError(t, err)
+
+	logger.Debug("hidden")
+	logger.Info("visible", "asset", "template.zip")
+	require.NoError(t, closer.Close())
+
+	content, err := os.ReadFile(logPath)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+	require.Len(t, lines, 1)
+
+	var record map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &record))
+	assert.Equal(t, "INFO", record["level"])
+	assert.Equal(t, "visible", record["msg"])
+	assert.Equal(t, "template.zip", record["asset"])
+}
+
+// TestNewLogger_InvalidFormat 测试无效的日志格式
+func TestNewLogger_InvalidFormat(t *testing.T) {
+	_, _, err := NewLogger(&LoggerConfig{Format: "xml"})
+	assert.Error(t, err)
+}
+
+// TestLogLevelFromFlags 测试标志到日志级别的映射
+func TestLogLevelFromFlags(t *testing.T) {
+	assert.Equal(t, slog.LevelWarn, LogLevelFromFlags(false, false))
+	assert.Equal(t, slog.LevelInfo, LogLevelFromFlags(true, false))
+	assert.Equal(t, slog.LevelDebug, LogLevelFromFlags(true, true))
+}
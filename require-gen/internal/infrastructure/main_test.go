@@ -0,0 +1,22 @@
+package infrastructure
+
+import (
+	"fmt"
+	"os"
+	"testing"
+)
+
+// TestMain 将测试中创建的模板提供者的发布信息缓存指向临时目录，
+// 避免在开发者的用户缓存目录中留下文件
+func TestMain(m *testing.M) {
+	cacheDir, err := os.MkdirTemp("", "specify-test-cache-")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to create test cache directory: %v\n", err)
+		os.Exit(1)
+	}
+	defaultReleaseCacheDir = func() string { return cacheDir }
+
+	code := m.Run()
+	os.RemoveAll(cacheDir)
+	os.Exit(code)
+}
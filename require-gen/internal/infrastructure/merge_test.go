@@ -496,4 +496,220 @@ func TestZipProcessor_ConflictResolutionStrategies(t *testing.T) {
 		assert.Equal(t, ConflictSkip, action)
 		assert.Equal(t, "", finalPath)
 	})
-}
\ No newline at end of file
+}
+// TestZipProcessor_MergeStrategy 测试merge冲突策略
+func TestZipProcessor_MergeStrategy(t *testing.T) {
+	sysOps := NewSystemOperations()
+	zipProcessorImpl := NewZipProcessor(sysOps).(*ZipProcessorImpl)
+
+	testDir, err := sysOps.CreateTempDirectory("test_merge_strategy")
+	require.NoError(t, err)
+	defer sysOps.RemoveDirectory(testDir)
+
+	conflictFile := filepath.Join(testDir, "merge_test.txt")
+	require.NoError(t, sysOps.WriteFile(conflictFile, []byte("original")))
+
+	conflictInfo := &FileConflictInfo{
+		SourcePath: "source/merge_test.txt",
+		TargetPath: conflictFile,
+		Exists:     true,
+		ModTime:    time.Now().Add(-1 * time.Hour).Unix(),
+	}
+
+	opts := &ExtractOptions{
+		SmartMerge:         true,
+		ConflictResolution: "merge",
+	}
+	_, action, err := zipProcessorImpl.handleFileConflict(conflictInfo, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, ConflictSkip, action)
+
+	// 回调返回ConflictMerge时使用相同的合并逻辑
+	conflictInfo.ModTime = time.Now().Add(1 * time.Hour).Unix()
+	opts.OnConflict = func(info *FileConflictInfo) ConflictAction {
+		return ConflictMerge
+	}
+	finalPath, action, err := zipProcessorImpl.handleFileConflict(conflictInfo, opts)
+	assert.NoError(t, err)
+	assert.Equal(t, ConflictOverwrite, action)
+	assert.Equal(t, conflictFile, finalPath)
+}
+
+// TestValidateConflictResolution 测试冲突策略验证
+func TestValidateConflictResolution(t *testing.T) {
+	for _, strategy := range append([]string{""}, ConflictStrategies...) {
+		assert.NoError(t, ValidateConflictResolution(strategy))
+	}
+	assert.Error(t, ValidateConflictResolution("replace"))
+}
+
+// TestUnifiedDiff 测试差异生成
+func TestUnifiedDiff(t *testing.T) {
+	oldText := "a\nb\nc\n"
+	newText := "a\nB\nc\nd\n"
+
+	diff := UnifiedDiff(oldText, newText, "old", "new")
+	assert.Contains(t, diff, "--- old")
+	assert.Contains(t, diff, "+++ new")
+	assert.Contains(t, diff, "@@ -1,3 +1,4 @@")
+	assert.Contains(t, diff, "-b")
+	assert.Contains(t, diff, "+B")
+	assert.Contains(t, diff, "+d")
+	assert.Contains(t, diff, " a")
+}
+
+// TestMergeStructuredFile_JSON 测试JSON结构化合并
+func TestMergeStructuredFile_JSON(t *testing.T) {
+	existing := []byte(`{
+    "editor.fontSize": 14,
+    "files.exclude": {
+        "**/.git": true
+    },
+    "chat.promptFiles": false
+}
+`)
+	incoming := []byte(`{
+  "chat.promptFiles": true,
+  "files.exclude": {
+    "**/.git": false,
+    "**/.specify": true
+  },
+  "chat.promptFilesRecommendations": {
+    "speckit.plan": true
+  }
+}`)
+
+	merged, result, err := MergeStructuredFile(existing, incoming, "json")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"files.exclude.**/.specify", "chat.promptFilesRecommendations"}, result.Added)
+	assert.ElementsMatch(t, []string{"chat.promptFiles", "files.exclude.**/.git"}, result.Preserved)
+
+	expected := `{
+    "editor.fontSize": 14,
+    "files.exclude": {
+        "**/.git": true,
+        "**/.specify": true
+    },
+    "chat.promptFiles": false,
+    "chat.promptFilesRecommendations": {
+        "speckit.plan": true
+    }
+}
+`
+	assert.Equal(t, expected, string(merged))
+}
+
+// TestMergeStructuredFile_JSONC 测试带注释和末尾逗号的VS Code配置合并
+func TestMergeStructuredFile_JSONC(t *testing.T) {
+	existing := []byte(`{
+    // 编辑器设置
+    "editor.fontSize": 14,
+    /* 排除的文件
+       "node_modules": true, */
+    "files.exclude": {
+        "**/.git": true, // 隐藏.git
+    },
+    "http.proxy": "http://proxy.internal:8080/*not-a-comment*/",
+}
+`)
+	incoming := []byte(`{
+  "chat.promptFilesRecommendations": {
+    "speckit.plan": true
+  }
+}`)
+
+	merged, result, err := MergeStructuredFile(existing, incoming, "json")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"chat.promptFilesRecommendations"}, result.Added)
+
+	expected := `{
+    "editor.fontSize": 14,
+    "files.exclude": {
+        "**/.git": true
+    },
+    "http.proxy": "http://proxy.internal:8080/*not-a-comment*/",
+    "chat.promptFilesRecommendations": {
+        "speckit.plan": true
+    }
+}
+`
+	assert.Equal(t, expected, string(merged))
+
+	// 非法JSON仍然报错
+	_, _, err = MergeStructuredFile([]byte(`{"a": 1 // 缺少右括号`), incoming, "json")
+	assert.Error(t, err)
+}
+
+// TestMergeStructuredFile_YAML 测试YAML结构化合并
+func TestMergeStructuredFile_YAML(t *testing.T) {
+	existing := []byte("# team settings\nname: mine\nnested:\n  a: 1\n")
+	incoming := []byte("name: template\nnested:\n  a: 2\n  b: 3\nextra: true\n")
+
+	merged, result, err := MergeStructuredFile(existing, incoming, "yaml")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"nested.b", "extra"}, result.Added)
+	assert.Equal(t, []string{"name", "nested.a"}, result.Preserved)
+	assert.Contains(t, string(merged), "# team settings")
+	assert.Contains(t, string(merged), "name: mine")
+	assert.Contains(t, string(merged), "b: 3")
+}
+
+// TestMergeStructuredFile_TOML 测试TOML结构化合并
+func TestMergeStructuredFile_TOML(t *testing.T) {
+	existing := []byte("title = \"mine\"\n\n[server]\nport = 8080\n")
+	incoming := []byte("title = \"template\"\n\n[server]\nport = 80\nhost = \"localhost\"\n")
+
+	merged, result, err := MergeStructuredFile(existing, incoming, "toml")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"server.host"}, result.Added)
+	assert.Contains(t, string(merged), `title = "mine"`)
+	assert.Contains(t, string(merged), "port = 8080")
+	assert.Contains(t, string(merged), `host = "localhost"`)
+}
+
+// TestZipProcessor_MergeStructuredConflict 测试提取时对配置文件的结构化合并
+func TestZipProcessor_MergeStructuredConflict(t *testing.T) {
+	sysOps := NewSystemOperations()
+	zipProcessorImpl := NewZipProcessor(sysOps).(*ZipProcessorImpl)
+
+	testDir, err := sysOps.CreateTempDirectory("test_structured_merge")
+	require.NoError(t, err)
+	defer sysOps.RemoveDirectory(testDir)
+
+	settings := filepath.Join(testDir, "settings.json")
+	require.NoError(t, os.WriteFile(settings, []byte("{\n  \"a\": 1\n}\n"), 0644))
+	invalid := filepath.Join(testDir, "invalid.json")
+	require.NoError(t, os.WriteFile(invalid, []byte("{\n  \"a\":\n}"), 0644))
+
+	var reported []string
+	opts := &ExtractOptions{
+		SmartMerge:         true,
+		ConflictResolution: "merge",
+		OnMerge: func(targetPath string, result *StructuredMergeResult, err error) {
+			reported = append(reported, filepath.Base(targetPath))
+		},
+	}
+
+	for _, target := range []string{settings, invalid} {
+		conflictInfo := &FileConflictInfo{
+			SourcePath: filepath.Base(target),
+			TargetPath: target,
+			Exists:     true,
+			ReadSource: func() ([]byte, error) { return []byte(`{"a": 2, "b": true}`), nil },
+		}
+		_, action, err := zipProcessorImpl.handleFileConflict(conflictInfo, opts)
+		require.NoError(t, err)
+		require.Equal(t, ConflictMerge, action)
+		require.NoError(t, mergeConflictFile(conflictInfo, opts))
+	}
+
+	content, err := os.ReadFile(settings)
+	require.NoError(t, err)
+	assert.Equal(t, "{\n  \"a\": 1,\n  \"b\": true\n}\n", string(content))
+
+	// 无法解析的文件保持不变
+	content, err = os.ReadFile(invalid)
+	require.NoError(t, err)
+	assert.Equal(t, "{\n  \"a\":\n}", string(content))
+	assert.Equal(t, []string{"settings.json", "invalid.json"}, reported)
+}
@@ -0,0 +1,83 @@
+package infrastructure
+
+import (
+	"fmt"
+	neturl "net/url"
+	"strings"
+
+	"specify-cli/internal/types"
+)
+
+// downloadSource 资源的一个下载来源（原始地址或镜像）
+type downloadSource struct {
+	URL     string
+	Headers map[string]string
+	Mirror  bool
+}
+
+// ParseMirrors 解析逗号分隔的镜像地址列表
+//
+// 每个镜像是代理发布资源的基础URL，例如Artifactory中代理github.com的远程仓库
+// https://artifactory.example.com/artifactory/github。
+func ParseMirrors(value string) ([]string, error) {
+	var mirrors []string
+	for _, mirror := range strings.Split(value, ",") {
+		mirror = strings.TrimSpace(mirror)
+		if mirror == "" {
+			continue
+		}
+		u, err := neturl.Parse(mirror)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("invalid mirror %q: expected an http(s) URL", mirror)
+		}
+		mirrors = append(mirrors, strings.TrimRight(mirror, "/"))
+	}
+	return mirrors, nil
+}
+
+// ValidateMirrors 校验镜像地址列表的格式
+func ValidateMirrors(value string) error {
+	_, err := ParseMirrors(value)
+	return err
+}
+
+// mirrorURL 资源在镜像上的地址
+//
+// 保留资源地址的路径和查询参数，将协议和主机替换为镜像地址，例如
+// https://github.com/owner/repo/releases/download/v1.0/a.zip 在镜像
+// https://artifactory.example.com/artifactory/github 上为
+// https://artifactory.example.com/artifactory/github/owner/repo/releases/download/v1.0/a.zip。
+func mirrorURL(mirror, assetURL string) (string, error) {
+	u, err := neturl.Parse(assetURL)
+	if err != nil {
+		return "", err
+	}
+	mirrored := strings.TrimRight(mirror, "/") + u.EscapedPath()
+	if u.RawQuery != "" {
+		mirrored += "?" + u.RawQuery
+	}
+	return mirrored, nil
+}
+
+// downloadSources 资源的下载来源：先原始地址，再按配置顺序的镜像
+//
+// 镜像地址根据资源的浏览器下载地址（GitHub的releases/download路径）生成，
+// 请求镜像时不附加原始地址的请求头，避免将GitHub令牌发送给镜像。
+func downloadSources(url string, headers map[string]string, asset *types.Asset, mirrors []string) []downloadSource {
+	sources := []downloadSource{{URL: url, Headers: headers}}
+
+	assetURL := asset.BrowserDownloadURL
+	if assetURL == "" {
+		assetURL = url
+	}
+	seen := map[string]bool{url: true}
+	for _, mirror := range mirrors {
+		mirrored, err := mirrorURL(mirror, assetURL)
+		if err != nil || seen[mirrored] {
+			continue
+		}
+		seen[mirrored] = true
+		sources = append(sources, downloadSource{URL: mirrored, Mirror: true})
+	}
+	return sources
+}
@@ -0,0 +1,182 @@
+package infrastructure
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"specify-cli/internal/types"
+)
+
+// TestParseMirrors 测试镜像列表解析
+func TestParseMirrors(t *testing.T) {
+	mirrors, err := ParseMirrors(" https://a.example.com/github/ , http://b.example.com,,")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"https://a.example.com/github", "http://b.example.com"}, mirrors)
+
+	mirrors, err = ParseMirrors("")
+	require.NoError(t, err)
+	assert.Empty(t, mirrors)
+
+	assert.Error(t, ValidateMirrors("ftp://a.example.com"))
+	assert.Error(t, ValidateMirrors("a.example.com/github"))
+}
+
+// TestDownloadSources 测试镜像地址生成，镜像请求不附加原始请求头
+func TestDownloadSources(t *testing.T) {
+	asset := &types.Asset{
+		URL:                "https://api.github.com/repos/o/r/releases/assets/1",
+		BrowserDownloadURL: "https://github.com/o/r/releases/download/v1.0/spec-kit-template.zip",
+	}
+	headers := map[string]string{"Authorization": "token secret"}
+
+	sources := downloadSources(asset.URL, headers, asset, []string{
+		"https://artifactory.example.com/artifactory/github/",
+		"https://artifactory.example.com/artifactory/github",
+		"https://backup.example.com",
+	})
+	require.Len(t, sources, 3)
+	assert.Equal(t, downloadSource{URL: asset.URL, Headers: headers}, sources[0])
+	assert.Equal(t, "https://artifactory.example.com/artifactory/github/o/r/releases/download/v1.0/spec-kit-template.zip", sources[1].URL)
+	assert.Equal(t, "https://backup.example.com/o/r/releases/download/v1.0/spec-kit-template.zip", sources[2].URL)
+	for _, src := range sources[1:] {
+		assert.True(t, src.Mirror)
+		assert.Nil(t, src.Headers)
+	}
+}
+
+// TestTemplateProvider_DownloadMirrors 测试原始地址失败时使用镜像，以及跳过已熔断的主机
+func TestTemplateProvider_DownloadMirrors(t *testing.T) {
+	var originRequests, mirrorRequests int32
+	origin := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&originRequests, 1)
+		http.Error(w, "blocked", http.StatusForbidden)
+	}))
+	defer origin.Close()
+
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&mirrorRequests, 1)
+		assert.Empty(t, r.Header.Get("Authorization"), "token is not sent to mirrors")
+		assert.Equal(t, "/o/r/releases/download/v1.0/template.zip", r.URL.Path)
+		w.Write([]byte("template"))
+	}))
+	defer mirror.Close()
+
+	tp := NewTemplateProviderWithConfig(nil, nil).(*TemplateProvider)
+	asset := &types.Asset{Name: "template.zip", BrowserDownloadURL: origin.URL + "/o/r/releases/download/v1.0/template.zip"}
+	sources := downloadSources(asset.BrowserDownloadURL, map[string]string{"Authorization": "token secret"}, asset, []string{mirror.URL})
+	dest := filepath.Join(t.TempDir(), "template.zip")
+
+	require.NoError(t, tp.downloadWithEnhancedProgress(context.Background(), sources, dest, 8, types.DownloadOptions{}, nil))
+	data, err := os.ReadFile(dest)
+	require.NoError(t, err)
+	assert.Equal(t, "template", string(data))
+	assert.NotZero(t, atomic.LoadInt32(&originRequests))
+	assert.Equal(t, int32(1), atomic.LoadInt32(&mirrorRequests))
+
+	// 原始主机连续失败后熔断，之后的下载直接使用镜像
+	originHost := extractHost(origin.URL)
+	for tp.errorHandler.GetCircuitState(originHost) != CircuitOpen {
+		tp.errorHandler.HandleError(context.Background(), errors.New("connection reset"), originHost)
+	}
+	atomic.StoreInt32(&originRequests, 0)
+
+	require.NoError(t, tp.downloadWithEnhancedProgress(context.Background(), sources, dest, 8, types.DownloadOptions{}, nil))
+	assert.Zero(t, atomic.LoadInt32(&originRequests), "open circuit skips the host")
+	assert.Equal(t, int32(2), atomic.LoadInt32(&mirrorRequests))
+	assert.Equal(t, CircuitClosed, tp.errorHandler.GetCircuitState(extractHost(mirror.URL)))
+
+	// 所有来源都失败
+	err = tp.downloadWithEnhancedProgress(context.Background(), sources[:1], dest, 8, types.DownloadOptions{}, nil)
+	assert.ErrorContains(t, err, "unavailable")
+}
+
+// TestTemplateProvider_DownloadUnreachableAPI 测试GitHub不可达时使用缓存的发布信息并从镜像下载
+func TestTemplateProvider_DownloadUnreachableAPI(t *testing.T) {
+	isolateCredentials(t)
+
+	archive := filepath.Join(t.TempDir(), "template.zip")
+	require.NoError(t, createTestZipFile(archive, map[string]string{
+		".specify/templates/spec-template.md": "# Spec",
+	}))
+	content, err := os.ReadFile(archive)
+	require.NoError(t, err)
+	sum := sha256.Sum256(content)
+	const assetName = "spec-kit-template-claude-sh-v1.0.0.zip"
+
+	mirror := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/o/r/releases/download/v1.0.0/" + assetName:
+			w.Write(content)
+		case "/o/r/releases/download/v1.0.0/SHA256SUMS":
+			fmt.Fprintf(w, "%s  %s\n", hex.EncodeToString(sum[:]), assetName)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer mirror.Close()
+
+	// 原始下载地址和API都不可达
+	origin := httptest.NewServer(http.NotFoundHandler())
+	origin.Close()
+	release := fmt.Sprintf(`{"tag_name":"v1.0.0","assets":[`+
+		`{"name":"%[2]s","size":%[3]d,"browser_download_url":"%[1]s/o/r/releases/download/v1.0.0/%[2]s"},`+
+		`{"name":"SHA256SUMS","browser_download_url":"%[1]s/o/r/releases/download/v1.0.0/SHA256SUMS"}]}`,
+		origin.URL, assetName, len(content))
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(release))
+	}))
+
+	tp := NewTemplateProviderWithConfig(&types.NetworkConfig{APIBaseURL: api.URL}, nil).(*TemplateProvider)
+	tp.client.SetRetryCount(0)
+	tp.SetReleaseCacheDir(t.TempDir())
+	_, err = tp.getLatestRelease(context.Background(), "o/r", "")
+	require.NoError(t, err)
+	api.Close()
+
+	opts := types.DownloadOptions{
+		AIAssistant:  "claude",
+		ScriptType:   "sh",
+		DownloadDir:  t.TempDir(),
+		TemplateRepo: "o/r",
+		Mirrors:      []string{mirror.URL},
+	}
+	targetDir, err := tp.Download(context.Background(), opts)
+	require.NoError(t, err)
+	assert.FileExists(t, filepath.Join(targetDir, ".specify", "templates", "spec-template.md"))
+
+	// 没有缓存时报告原始错误
+	tp.SetReleaseCacheDir(t.TempDir())
+	_, err = tp.Download(context.Background(), opts)
+	assert.ErrorContains(t, err, "failed to get latest release")
+}
+
+// TestTemplateProvider_MirrorDiscardsPartial 测试切换下载来源时丢弃上一个来源的部分下载
+func TestTemplateProvider_MirrorDiscardsPartial(t *testing.T) {
+	mirror := httptest.NewServer(http.NotFoundHandler())
+	defer mirror.Close()
+	origin := httptest.NewServer(http.NotFoundHandler())
+	origin.Close()
+
+	tp := NewTemplateProviderWithConfig(nil, nil).(*TemplateProvider)
+	tp.client.SetRetryCount(0)
+	asset := &types.Asset{Name: "template.zip", BrowserDownloadURL: origin.URL + "/o/r/releases/download/v1.0/template.zip"}
+	sources := downloadSources(asset.BrowserDownloadURL, nil, asset, []string{mirror.URL})
+	dest := filepath.Join(t.TempDir(), "template.zip")
+	seedPartial(t, dest, sources[0].URL, `"v1"`, []byte("template"), 4)
+
+	err := tp.downloadWithEnhancedProgress(context.Background(), sources, dest, 8, types.DownloadOptions{EnableResume: true}, nil)
+	require.Error(t, err)
+	assert.NoFileExists(t, partFilePath(dest))
+	assert.NoFileExists(t, downloadStatePath(dest))
+}
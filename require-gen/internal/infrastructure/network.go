@@ -0,0 +1,173 @@
+package infrastructure
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"strings"
+
+	"golang.org/x/net/http/httpproxy"
+	"specify-cli/internal/types"
+)
+
+// ProxyFunc 根据代理地址创建http.Transport使用的代理选择函数
+//
+// proxyURL为空时使用环境变量（HTTPS_PROXY/HTTP_PROXY/NO_PROXY）；
+// 显式指定代理时所有请求都经过该代理，但仍然遵循NO_PROXY中的例外主机，
+// 以便内网镜像等地址可以直连。
+func ProxyFunc(proxyURL string) (func(*http.Request) (*neturl.URL, error), error) {
+	if proxyURL == "" {
+		return http.ProxyFromEnvironment, nil
+	}
+
+	u, err := neturl.Parse(proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL '%s': expected a URL such as http://proxy:8080", proxyURL)
+	}
+
+	noProxy := os.Getenv("NO_PROXY")
+	if noProxy == "" {
+		noProxy = os.Getenv("no_proxy")
+	}
+	proxyConfig := &httpproxy.Config{
+		HTTPProxy:  proxyURL,
+		HTTPSProxy: proxyURL,
+		NoProxy:    noProxy,
+	}
+	proxyForURL := proxyConfig.ProxyFunc()
+
+	return func(req *http.Request) (*neturl.URL, error) {
+		return proxyForURL(req.URL)
+	}, nil
+}
+
+// BuildTLSConfig 根据TLS配置构建tls.Config
+//
+// CAFile中的证书追加到系统根证书之后，因此在使用企业MITM代理时
+// 仍可正常访问未经代理的站点。客户端证书和私钥必须同时指定。
+func BuildTLSConfig(tlsConf *types.TLSConfig) (*tls.Config, error) {
+	config := &tls.Config{
+		InsecureSkipVerify: tlsConf.InsecureSkipVerify,
+		ServerName:         tlsConf.ServerName,
+	}
+
+	// 加载客户端证书
+	if (tlsConf.CertFile == "") != (tlsConf.KeyFile == "") {
+		return nil, fmt.Errorf("client certificate and key must be specified together")
+	}
+	if tlsConf.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(tlsConf.CertFile, tlsConf.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	// 加载CA证书
+	if tlsConf.CAFile != "" {
+		caCert, err := os.ReadFile(tlsConf.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+		}
+
+		caCertPool, err := x509.SystemCertPool()
+		if err != nil || caCertPool == nil {
+			caCertPool = x509.NewCertPool()
+		}
+		if !caCertPool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("failed to parse CA certificate %s: no PEM certificates found", tlsConf.CAFile)
+		}
+		config.RootCAs = caCertPool
+	}
+
+	return config, nil
+}
+
+// defaultMaxRedirects 未配置时的最大重定向次数（与net/http一致）
+const defaultMaxRedirects = 10
+
+// credentialHeaders 模板源请求可能携带的凭据头
+//
+// net/http在跨主机重定向时只移除Authorization和Cookie，
+// GitLab的PRIVATE-TOKEN等自定义头需要由redirectPolicy移除。
+var credentialHeaders = []string{"Authorization", "Private-Token"}
+
+// redirectPolicy 创建重定向检查函数
+//
+// 重定向到其他主机（例如从资源API或GitLab软件包下载跳转到对象存储的
+// 签名URL）时移除所有凭据头：存储服务使用URL中的签名认证，收到令牌会
+// 拒绝请求，令牌也不应发送给API以外的主机。
+func redirectPolicy(maxRedirects int) func(req *http.Request, via []*http.Request) error {
+	if maxRedirects <= 0 {
+		maxRedirects = defaultMaxRedirects
+	}
+	return func(req *http.Request, via []*http.Request) error {
+		if len(via) >= maxRedirects {
+			return fmt.Errorf("stopped after %d redirects", maxRedirects)
+		}
+		if req.URL.Host != via[0].URL.Host {
+			for _, name := range credentialHeaders {
+				req.Header.Del(name)
+			}
+		}
+		return nil
+	}
+}
+
+// newNetworkTransport 根据网络配置创建应用了代理和TLS设置的传输层
+//
+// 用于不经过HTTPClientManager的直接请求（流式下载、令牌验证等）。
+func newNetworkTransport(networkConfig *types.NetworkConfig) (*http.Transport, error) {
+	transport := &http.Transport{Proxy: http.ProxyFromEnvironment}
+	if networkConfig == nil {
+		return transport, nil
+	}
+
+	proxy, err := ProxyFunc(networkConfig.ProxyURL)
+	if err != nil {
+		return nil, err
+	}
+	transport.Proxy = proxy
+
+	if networkConfig.TLS != nil {
+		tlsConfig, err := BuildTLSConfig(networkConfig.TLS)
+		if err != nil {
+			return nil, err
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+	return transport, nil
+}
+
+// apiBaseURL 获取GitHub API基础URL（不含末尾斜杠）
+func apiBaseURL(networkConfig *types.NetworkConfig) string {
+	if networkConfig == nil || networkConfig.APIBaseURL == "" {
+		return types.DefaultGitHubAPIURL
+	}
+	return strings.TrimRight(networkConfig.APIBaseURL, "/")
+}
+
+// ValidateNetworkConfig 检查代理地址和TLS文件是否可用
+//
+// NewTemplateProviderWithConfig不返回错误，命令行在创建处理器之前
+// 调用该函数，使错误的证书路径在发起任何请求前就被报告。
+func ValidateNetworkConfig(networkConfig *types.NetworkConfig) error {
+	if networkConfig == nil {
+		return nil
+	}
+	if _, err := ProxyFunc(networkConfig.ProxyURL); err != nil {
+		return err
+	}
+	if networkConfig.TLS != nil {
+		if _, err := BuildTLSConfig(networkConfig.TLS); err != nil {
+			return err
+		}
+	}
+	return nil
+}
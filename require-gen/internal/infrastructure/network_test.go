@@ -0,0 +1,73 @@
+package infrastructure
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"specify-cli/internal/types"
+)
+
+// TestProxyFunc 测试显式代理和NO_PROXY例外
+func TestProxyFunc(t *testing.T) {
+	t.Setenv("NO_PROXY", "mirror.internal")
+
+	proxy, err := ProxyFunc("http://proxy.example.com:8080")
+	require.NoError(t, err)
+
+	req, _ := http.NewRequest("GET", "https://api.github.com/repos", nil)
+	proxyURL, err := proxy(req)
+	require.NoError(t, err)
+	require.NotNil(t, proxyURL)
+	assert.Equal(t, "proxy.example.com:8080", proxyURL.Host)
+
+	req, _ = http.NewRequest("GET", "https://mirror.internal/templates.zip", nil)
+	proxyURL, err = proxy(req)
+	require.NoError(t, err)
+	assert.Nil(t, proxyURL)
+
+	_, err = ProxyFunc("proxy.example.com")
+	assert.Error(t, err)
+}
+
+// TestBuildTLSConfig_Errors 测试TLS配置错误
+func TestBuildTLSConfig_Errors(t *testing.T) {
+	_, err := BuildTLSConfig(&types.TLSConfig{CertFile: "client.pem"})
+	assert.ErrorContains(t, err, "specified together")
+
+	_, err = BuildTLSConfig(&types.TLSConfig{CAFile: filepath.Join(t.TempDir(), "missing.pem")})
+	assert.ErrorContains(t, err, "failed to read CA certificate")
+
+	notPEM := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(notPEM, []byte("not a certificate"), 0644))
+	assert.Error(t, ValidateNetworkConfig(&types.NetworkConfig{TLS: &types.TLSConfig{CAFile: notPEM}}))
+}
+
+// TestTemplateProvider_CustomCA 测试使用自定义CA访问TLS服务器而无需跳过证书校验
+func TestTemplateProvider_CustomCA(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	require.NoError(t, os.WriteFile(caFile, caPEM, 0644))
+
+	// 默认配置不信任测试服务器的证书
+	tp := NewTemplateProvider().(*TemplateProvider)
+	_, err := tp.client.SetRetryCount(0).R().Get(server.URL)
+	assert.Error(t, err)
+
+	tp = NewTemplateProviderWithConfig(&types.NetworkConfig{
+		TLS: &types.TLSConfig{CAFile: caFile},
+	}, nil).(*TemplateProvider)
+	resp, err := tp.client.R().Get(server.URL)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode())
+}
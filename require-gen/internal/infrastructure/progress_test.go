@@ -2,6 +2,7 @@ package infrastructure
 
 import (
 	"archive/zip"
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -53,7 +54,7 @@ func TestProgressFeedback(t *testing.T) {
 	}
 
 	// 执行提取
-	err = zipProcessor.ExtractWithProgress(zipPath, extractDir, opts, progressCallback)
+	err = zipProcessor.ExtractWithProgress(context.Background(), zipPath, extractDir, opts, progressCallback)
 	assert.NoError(t, err)
 
 	// 验证进度回调被调用
@@ -118,7 +119,7 @@ func TestErrorHandling(t *testing.T) {
 	}
 
 	// 执行提取（应该遇到错误但继续）
-	err = zipProcessor.ExtractZip(zipPath, extractDir, opts)
+	err = zipProcessor.ExtractZip(context.Background(), zipPath, extractDir, opts)
 	
 	// 在某些系统上可能不会产生错误，所以我们检查是否有错误回调被调用
 	if len(errorCalls) > 0 {
@@ -151,7 +152,7 @@ func TestErrorHandling(t *testing.T) {
 	}
 
 	// 这次应该在遇到错误时停止
-	err = zipProcessor.ExtractZip(zipPath, extractDir2, opts)
+	err = zipProcessor.ExtractZip(context.Background(), zipPath, extractDir2, opts)
 	// 可能会有错误，也可能没有，取决于系统行为
 	t.Logf("Extraction result with stop-on-error: %v", err)
 }
@@ -197,7 +198,7 @@ func TestProgressAndErrorIntegration(t *testing.T) {
 	}
 
 	// 执行提取
-	err = zipProcessor.ExtractWithProgress(zipPath, extractDir, opts, progressCallback)
+	err = zipProcessor.ExtractWithProgress(context.Background(), zipPath, extractDir, opts, progressCallback)
 	assert.NoError(t, err)
 
 	// 验证进度和错误处理的协调工作
@@ -261,7 +262,7 @@ func TestProgressBarIntegration(t *testing.T) {
 
 	// 执行提取
 	start := time.Now()
-	err = zipProcessor.ExtractWithProgress(zipPath, extractDir, opts, progressCallback)
+	err = zipProcessor.ExtractWithProgress(context.Background(), zipPath, extractDir, opts, progressCallback)
 	duration := time.Since(start)
 	
 	assert.NoError(t, err)
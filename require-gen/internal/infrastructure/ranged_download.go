@@ -0,0 +1,310 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"specify-cli/internal/types"
+)
+
+// minRangedDownloadSize 启用分段并发下载的最小文件大小
+//
+// 较小的文件用单个连接下载更快，分段带来的额外请求得不偿失。
+const minRangedDownloadSize = 8 * 1024 * 1024
+
+// byteRange 分段下载中的一个字节范围（End包含在内）
+type byteRange struct {
+	Index int
+	Start int64
+	End   int64
+}
+
+// Len 范围的字节数
+func (r byteRange) Len() int64 {
+	return r.End - r.Start + 1
+}
+
+// splitRanges 将size字节的文件分成最多parts段，每段至少minPart字节
+func splitRanges(size int64, parts int, minPart int64) []byteRange {
+	if parts < 1 {
+		parts = 1
+	}
+	if minPart > 0 && size/minPart < int64(parts) {
+		parts = int(size / minPart)
+		if parts < 1 {
+			parts = 1
+		}
+	}
+
+	partSize := size / int64(parts)
+	ranges := make([]byteRange, parts)
+	for i := range ranges {
+		ranges[i] = byteRange{Index: i, Start: int64(i) * partSize, End: int64(i+1)*partSize - 1}
+	}
+	// 余下的字节归入最后一段
+	ranges[parts-1].End = size - 1
+	return ranges
+}
+
+// splitMissing 将尚未下载的字节范围划分为并发下载的分段
+//
+// 按剩余字节数平均分给workers个连接，每段至少minPart字节；
+// 首次下载时结果与splitRanges(size, workers, minPart)相同。
+func splitMissing(missing []byteRange, workers int, minPart int64) []byteRange {
+	if workers < 1 {
+		workers = 1
+	}
+	var total int64
+	for _, r := range missing {
+		total += r.Len()
+	}
+	if total == 0 {
+		return nil
+	}
+
+	target := (total + int64(workers) - 1) / int64(workers)
+	if target < minPart {
+		target = minPart
+	}
+
+	var ranges []byteRange
+	for _, m := range missing {
+		parts := int((m.Len() + target - 1) / target)
+		for _, r := range splitRanges(m.Len(), parts, 0) {
+			ranges = append(ranges, byteRange{Index: len(ranges), Start: m.Start + r.Start, End: m.Start + r.End})
+		}
+	}
+	return ranges
+}
+
+// errStalePartial 服务器上的文件已变化（If-Range不匹配），已下载的部分失效
+var errStalePartial = errors.New("remote file changed since the partial download")
+
+// DownloadRanged 分段并发下载单个文件，支持中断后继续
+//
+// 服务器支持Range请求且文件不小于minRangedDownloadSize（或启用了断点续传）时，
+// 将文件分成最多MaxConcurrent段，通过下载器的HTTP客户端（共享连接池）并发写入
+// <dest>.part，已完成的字节范围记录在<dest>.part.json。下载失败或进程被终止后，
+// 再次下载同一文件只请求缺少的范围，并通过If-Range确保远程文件没有变化；
+// URL、大小或ETag不一致时丢弃旧的部分下载重新开始。其他情况退回到Download的单连接下载。
+func (ed *EnhancedDownloader) DownloadRanged(ctx context.Context, url, dest string, opts *types.DownloadOptions) error {
+	config := ed.convertOptions(opts)
+	logger := DefaultLogger()
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	remote, err := ed.headRemote(ctx, url)
+	if err != nil || (remote.Size < minRangedDownloadSize && !config.EnableResume) {
+		logger.Debug("using single connection download", "url", url, "range_error", err)
+		return ed.Download(ctx, url, dest, opts)
+	}
+
+	workers := config.MaxConcurrent
+	if remote.Size < minRangedDownloadSize {
+		workers = 1
+	}
+
+	err = ed.downloadResumable(ctx, url, dest, remote, workers, config)
+	if errors.Is(err, errStalePartial) {
+		// 远程文件在HEAD请求之后发生了变化，重新获取文件信息后从头下载
+		logger.Info("remote file changed, restarting download", "url", url)
+		discardPartialDownload(dest)
+		if remote, err = ed.headRemote(ctx, url); err == nil {
+			err = ed.downloadResumable(ctx, url, dest, remote, workers, config)
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if config.VerifyChecksum && config.ExpectedSum != "" {
+		return ed.verifyChecksum(dest, config.ChecksumType, config.ExpectedSum)
+	}
+	return nil
+}
+
+// downloadResumable 下载<dest>.part中缺少的字节范围，完成后重命名为dest
+func (ed *EnhancedDownloader) downloadResumable(ctx context.Context, url, dest string, remote *remoteFile, workers int, config *DownloadConfig) error {
+	logger := DefaultLogger()
+
+	state := loadDownloadState(dest)
+	if state != nil && state.matches(url, remote) {
+		if _, err := os.Stat(partFilePath(dest)); err != nil {
+			state = nil
+		}
+	} else {
+		state = nil
+	}
+	if state == nil {
+		// 没有可用的部分下载，或属于已变化的远程文件
+		discardPartialDownload(dest)
+		state = newDownloadState(dest, url, remote)
+	} else {
+		logger.Info("resuming download", "url", url, "completed", state.completedBytes(), "size", remote.Size)
+	}
+
+	file, err := os.OpenFile(partFilePath(dest), os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open part file: %w", err)
+	}
+	if err := file.Truncate(remote.Size); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to allocate part file: %w", err)
+	}
+
+	ranges := splitMissing(state.missing(), workers, config.ChunkSize)
+	logger.Debug("using ranged download", "url", url, "size", remote.Size, "parts", len(ranges))
+
+	err = ed.downloadRanges(ctx, url, file, state, ranges, workers, config)
+	closeErr := file.Close()
+	if err != nil {
+		// 保留部分下载，再次执行同一命令时从已完成的位置继续
+		if saveErr := state.save(true); saveErr != nil {
+			logger.Warn("failed to save download state", "path", downloadStatePath(dest), "error", saveErr)
+		}
+		return err
+	}
+	if closeErr != nil {
+		return fmt.Errorf("failed to write file: %w", closeErr)
+	}
+
+	if err := os.Rename(partFilePath(dest), dest); err != nil {
+		return fmt.Errorf("failed to move download into place: %w", err)
+	}
+	os.Remove(downloadStatePath(dest))
+	return nil
+}
+
+// downloadRanges 以最多workers个连接下载所有分段，任一分段失败时取消其余分段
+func (ed *EnhancedDownloader) downloadRanges(ctx context.Context, url string, file *os.File, state *downloadState, ranges []byteRange, workers int, config *DownloadConfig) error {
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	if workers < 1 {
+		workers = 1
+	}
+	sem := make(chan struct{}, workers)
+
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+
+	for _, r := range ranges {
+		wg.Add(1)
+		go func(r byteRange) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				return
+			}
+			if err := ed.downloadPart(ctx, url, file, state, r, config); err != nil {
+				once.Do(func() {
+					firstErr = fmt.Errorf("failed to download bytes %d-%d: %w", r.Start, r.End, err)
+					cancel()
+				})
+			}
+		}(r)
+	}
+	wg.Wait()
+
+	if firstErr == nil && parent.Err() != nil {
+		return parent.Err()
+	}
+	return firstErr
+}
+
+// downloadPart 下载一个分段并写入文件的对应位置
+//
+// 写入的每个数据块都记录到下载状态中，重试时从已写入的位置继续。
+func (ed *EnhancedDownloader) downloadPart(ctx context.Context, url string, file *os.File, state *downloadState, r byteRange, config *DownloadConfig) error {
+	pos := r.Start
+	stale := false
+	validator := state.validator()
+
+	operation := func(ctx context.Context, attempt int) error {
+		if pos > r.End {
+			return nil
+		}
+
+		req := ed.client.R().
+			SetContext(ctx).
+			SetHeaders(ed.headers).
+			SetHeader("Range", fmt.Sprintf("bytes=%d-%d", pos, r.End)).
+			SetDoNotParseResponse(true)
+		if validator != "" {
+			req.SetHeader("If-Range", validator)
+		}
+		resp, err := req.Get(url)
+		if err != nil {
+			return err
+		}
+		body := resp.RawBody()
+		defer body.Close()
+
+		switch resp.StatusCode() {
+		case http.StatusPartialContent:
+		case http.StatusOK:
+			if validator != "" {
+				// If-Range不匹配时服务器返回整个文件，无需重试
+				stale = true
+				return nil
+			}
+			fallthrough
+		default:
+			return CreateNetworkError(fmt.Errorf("range request failed with status: %d", resp.StatusCode()), url, resp.StatusCode())
+		}
+
+		// 所有分段共享同一个限速器
+		reader := config.Limiter.Reader(ctx, io.LimitReader(body, r.End-pos+1))
+		buf := make([]byte, 32*1024)
+		for {
+			n, err := reader.Read(buf)
+			if n > 0 {
+				if _, werr := file.WriteAt(buf[:n], pos); werr != nil {
+					return fmt.Errorf("failed to write file: %w", werr)
+				}
+				state.markCompleted(pos, pos+int64(n)-1)
+				pos += int64(n)
+				// 保存失败时继续下载，结束时会再次保存
+				_ = state.save(false)
+			}
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return CreateNetworkError(fmt.Errorf("failed to copy data: %w", err), url, 0)
+			}
+		}
+		if pos <= r.End {
+			return CreateNetworkError(fmt.Errorf("incomplete range: got %d of %d bytes", pos-r.Start, r.Len()), url, 0)
+		}
+		return nil
+	}
+
+	result := ed.retryManager.ExecuteWithRetry(ctx, operation, &RetryOptions{
+		StrategyName: "default",
+		Operation:    "download_range",
+		Host:         extractHost(url),
+	})
+	if stale {
+		return errStalePartial
+	}
+	if !result.Success {
+		if result.LastError == nil {
+			return fmt.Errorf("range request failed after %d attempts", result.AttemptCount)
+		}
+		return result.LastError
+	}
+	return nil
+}
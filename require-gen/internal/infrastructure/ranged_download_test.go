@@ -0,0 +1,271 @@
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"specify-cli/internal/types"
+)
+
+// TestSplitRanges 测试字节范围划分
+func TestSplitRanges(t *testing.T) {
+	ranges := splitRanges(10, 3, 0)
+	require.Len(t, ranges, 3)
+	assert.Equal(t, byteRange{Index: 0, Start: 0, End: 2}, ranges[0])
+	assert.Equal(t, byteRange{Index: 2, Start: 6, End: 9}, ranges[2])
+
+	// 每段至少minPart字节
+	assert.Len(t, splitRanges(10, 4, 5), 2)
+	assert.Len(t, splitRanges(3, 4, 5), 1)
+}
+
+// TestSplitMissing 测试按未完成的字节范围划分分段
+func TestSplitMissing(t *testing.T) {
+	// 首次下载与splitRanges一致
+	assert.Equal(t, splitRanges(100, 4, 10), splitMissing([]byteRange{{Start: 0, End: 99}}, 4, 10))
+
+	ranges := splitMissing([]byteRange{{Start: 10, End: 19}, {Start: 50, End: 89}}, 5, 0)
+	require.Len(t, ranges, 5)
+	assert.Equal(t, byteRange{Index: 0, Start: 10, End: 19}, ranges[0])
+	assert.Equal(t, byteRange{Index: 4, Start: 80, End: 89}, ranges[4])
+
+	assert.Nil(t, splitMissing(nil, 4, 0))
+}
+
+// TestDownloadState 测试已完成范围的合并、缺少范围的计算和状态文件读写
+func TestDownloadState(t *testing.T) {
+	dest := filepath.Join(t.TempDir(), "template.zip")
+	remote := &remoteFile{Size: 100, ETag: `"v1"`}
+	state := newDownloadState(dest, "https://example.com/t.zip", remote)
+
+	state.markCompleted(20, 29)
+	state.markCompleted(0, 9)
+	state.markCompleted(10, 14)
+	assert.Equal(t, [][2]int64{{0, 14}, {20, 29}}, state.Completed)
+	assert.Equal(t, []byteRange{{Index: 0, Start: 15, End: 19}, {Index: 1, Start: 30, End: 99}}, state.missing())
+	assert.Equal(t, int64(25), state.completedBytes())
+
+	require.NoError(t, state.save(true))
+	loaded := loadDownloadState(dest)
+	require.NotNil(t, loaded)
+	assert.Equal(t, state.Completed, loaded.Completed)
+	assert.True(t, loaded.matches("https://example.com/t.zip", remote))
+	assert.False(t, loaded.matches("https://example.com/t.zip", &remoteFile{Size: 100, ETag: `"v2"`}))
+	assert.False(t, loaded.matches("https://example.com/t.zip", &remoteFile{Size: 101, ETag: `"v1"`}))
+
+	// 弱ETag不能用于If-Range，没有Last-Modified时无法续传
+	weak := newDownloadState(dest, "https://example.com/t.zip", &remoteFile{Size: 100, ETag: `W/"v1"`})
+	assert.Empty(t, weak.validator())
+	assert.False(t, weak.matches("https://example.com/t.zip", &remoteFile{Size: 100, ETag: `W/"v1"`}))
+}
+
+// rangedTestServer 支持Range和If-Range的测试服务器，记录收到的请求头
+type rangedTestServer struct {
+	mu       sync.Mutex
+	content  []byte
+	etag     string
+	headETag string // 非空时HEAD请求返回该ETag（模拟HEAD之后文件发生变化）
+	ranges   []string
+	ifRanges []string
+}
+
+func (s *rangedTestServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	content, etag := s.content, s.etag
+	if r.Method == http.MethodHead && s.headETag != "" {
+		etag = s.headETag
+		s.headETag = ""
+	}
+	if rng := r.Header.Get("Range"); rng != "" {
+		s.ranges = append(s.ranges, rng)
+		s.ifRanges = append(s.ifRanges, r.Header.Get("If-Range"))
+	}
+	s.mu.Unlock()
+
+	w.Header().Set("ETag", etag)
+	http.ServeContent(w, r, "template.zip", time.Time{}, bytes.NewReader(content))
+}
+
+// reset 清空记录的请求头
+func (s *rangedTestServer) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ranges, s.ifRanges = nil, nil
+}
+
+// seedPartial 模拟中断的下载：<dest>.part中只有前half字节，状态文件记录对应范围
+func seedPartial(t *testing.T, dest, url, etag string, content []byte, half int64) {
+	partial := make([]byte, len(content))
+	copy(partial, content[:half])
+	require.NoError(t, os.WriteFile(partFilePath(dest), partial, 0644))
+
+	state := newDownloadState(dest, url, &remoteFile{Size: int64(len(content)), ETag: etag})
+	state.markCompleted(0, half-1)
+	require.NoError(t, state.save(true))
+}
+
+// TestEnhancedDownloader_DownloadRanged 测试分段并发下载、校验和断点续传
+func TestEnhancedDownloader_DownloadRanged(t *testing.T) {
+	content := make([]byte, minRangedDownloadSize+12345)
+	rand.New(rand.NewSource(1)).Read(content)
+	sum := sha256.Sum256(content)
+
+	srv := &rangedTestServer{content: content, etag: `"v1"`}
+	server := httptest.NewServer(srv)
+	defer server.Close()
+
+	downloader := NewEnhancedDownloader(resty.New(), nil, nil)
+	dest := filepath.Join(t.TempDir(), "template.zip")
+	opts := &types.DownloadOptions{
+		MaxConcurrent:  4,
+		VerifyChecksum: true,
+		ChecksumType:   "sha256",
+		Checksum:       hex.EncodeToString(sum[:]),
+	}
+	assertDownloaded := func() {
+		data, err := os.ReadFile(dest)
+		require.NoError(t, err)
+		assert.True(t, bytes.Equal(content, data))
+		for _, path := range []string{partFilePath(dest), downloadStatePath(dest)} {
+			_, err := os.Stat(path)
+			assert.True(t, os.IsNotExist(err), "%s is removed after download", path)
+		}
+	}
+
+	require.NoError(t, downloader.DownloadRanged(context.Background(), server.URL, dest, opts))
+	assertDownloaded()
+	assert.Len(t, srv.ranges, 4)
+	assert.Equal(t, []string{`"v1"`, `"v1"`, `"v1"`, `"v1"`}, srv.ifRanges)
+
+	// 中断的下载只请求缺少的范围
+	half := int64(len(content) / 2)
+	os.Remove(dest)
+	seedPartial(t, dest, server.URL, `"v1"`, content, half)
+	srv.reset()
+
+	require.NoError(t, downloader.DownloadRanged(context.Background(), server.URL, dest, opts))
+	assertDownloaded()
+	require.NotEmpty(t, srv.ranges)
+	for _, rng := range srv.ranges {
+		var start int64
+		_, err := fmt.Sscanf(rng, "bytes=%d-", &start)
+		require.NoError(t, err)
+		assert.GreaterOrEqual(t, start, half, "completed bytes are not downloaded again: %s", rng)
+	}
+
+	// ETag已变化的部分下载被丢弃
+	os.Remove(dest)
+	seedPartial(t, dest, server.URL, `"v0"`, content, half)
+	srv.reset()
+
+	require.NoError(t, downloader.DownloadRanged(context.Background(), server.URL, dest, opts))
+	assertDownloaded()
+	assert.Contains(t, srv.ranges, "bytes=0-"+strconv.FormatInt(splitRanges(int64(len(content)), 4, DefaultDownloadConfig().ChunkSize)[0].End, 10))
+
+	// HEAD之后文件发生变化：If-Range不匹配，服务器返回整个文件，重新开始下载
+	os.Remove(dest)
+	seedPartial(t, dest, server.URL, `"v0"`, content, half)
+	srv.mu.Lock()
+	srv.headETag = `"v0"`
+	srv.mu.Unlock()
+	srv.reset()
+
+	require.NoError(t, downloader.DownloadRanged(context.Background(), server.URL, dest, opts))
+	assertDownloaded()
+	assert.Contains(t, srv.ifRanges, `"v0"`)
+	assert.Equal(t, `"v1"`, srv.ifRanges[len(srv.ifRanges)-1])
+
+	// 校验和不匹配
+	opts.Checksum = strings.Repeat("0", 64)
+	assert.ErrorContains(t, downloader.DownloadRanged(context.Background(), server.URL, dest, opts), "checksum")
+}
+
+// TestEnhancedDownloader_DownloadRangedInterrupted 测试取消下载后保留部分下载并在再次下载时继续
+func TestEnhancedDownloader_DownloadRangedInterrupted(t *testing.T) {
+	content := make([]byte, minRangedDownloadSize+12345)
+	rand.New(rand.NewSource(2)).Read(content)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var once sync.Once
+	srv := &rangedTestServer{content: content, etag: `"v1"`}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet && ctx.Err() == nil {
+			// 第一次下载：每个分段只发送部分数据，然后取消下载
+			w.Header().Set("ETag", srv.etag)
+			w.Header().Set("Content-Range", fmt.Sprintf("bytes %s/%d", strings.TrimPrefix(r.Header.Get("Range"), "bytes="), len(content)))
+			w.WriteHeader(http.StatusPartialContent)
+			var start int64
+			fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-", &start)
+			w.Write(content[start : start+4096])
+			w.(http.Flusher).Flush()
+			once.Do(func() { time.AfterFunc(200*time.Millisecond, cancel) })
+			<-r.Context().Done()
+			return
+		}
+		srv.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	downloader := NewEnhancedDownloader(resty.New(), nil, nil)
+	dest := filepath.Join(t.TempDir(), "template.zip")
+	opts := &types.DownloadOptions{MaxConcurrent: 4}
+
+	require.Error(t, downloader.DownloadRanged(ctx, server.URL, dest, opts))
+	state := loadDownloadState(dest)
+	require.NotNil(t, state, "download state is kept after an interrupted download")
+	assert.Equal(t, `"v1"`, state.ETag)
+	assert.Equal(t, int64(4*4096), state.completedBytes())
+
+	require.NoError(t, downloader.DownloadRanged(context.Background(), server.URL, dest, opts))
+	data, err := os.ReadFile(dest)
+	require.NoError(t, err)
+	assert.True(t, bytes.Equal(content, data))
+	assert.Len(t, srv.ranges, 4)
+	for _, ifRange := range srv.ifRanges {
+		assert.Equal(t, `"v1"`, ifRange)
+	}
+}
+
+// TestTemplateProvider_ResumeSmallAsset 测试启用断点续传时小于minRangedDownloadSize的资源同样从部分下载继续
+func TestTemplateProvider_ResumeSmallAsset(t *testing.T) {
+	content := make([]byte, 64*1024)
+	rand.New(rand.NewSource(3)).Read(content)
+
+	srv := &rangedTestServer{content: content, etag: `"v1"`}
+	server := httptest.NewServer(srv)
+	defer server.Close()
+
+	dest := filepath.Join(t.TempDir(), "template.zip")
+	half := int64(len(content) / 2)
+	seedPartial(t, dest, server.URL, `"v1"`, content, half)
+
+	tp := NewTemplateProvider().(*TemplateProvider)
+	opts := types.DownloadOptions{EnableResume: true, MaxConcurrent: 4}
+	require.NoError(t, tp.downloadFromSource(context.Background(), server.URL, nil, dest, int64(len(content)), opts, nil))
+
+	data, err := os.ReadFile(dest)
+	require.NoError(t, err)
+	assert.True(t, bytes.Equal(content, data))
+	assert.Equal(t, []string{"bytes=" + strconv.FormatInt(half, 10) + "-" + strconv.Itoa(len(content)-1)}, srv.ranges)
+	assert.Equal(t, []string{`"v1"`}, srv.ifRanges)
+	_, err = os.Stat(downloadStatePath(dest))
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,173 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"specify-cli/internal/types"
+)
+
+// maxRateLimitWait 速率限制即将重置时自动等待的最长时间，超过后改用缓存的发布信息
+const maxRateLimitWait = time.Minute
+
+// ParseRateLimit 从响应头解析速率限制状态
+//
+// 支持GitHub（及Gitea）的X-RateLimit-Limit/Remaining/Used/Reset/Resource头
+// 和标准的Retry-After头（秒数或HTTP日期）。响应不包含这些头时返回nil。
+func ParseRateLimit(header http.Header) *types.RateLimit {
+	limit := header.Get("X-RateLimit-Limit")
+	retryAfter := header.Get("Retry-After")
+	if limit == "" && retryAfter == "" {
+		return nil
+	}
+
+	rl := &types.RateLimit{Resource: header.Get("X-RateLimit-Resource")}
+	rl.Limit, _ = strconv.Atoi(limit)
+	rl.Remaining, _ = strconv.Atoi(header.Get("X-RateLimit-Remaining"))
+	rl.Used, _ = strconv.Atoi(header.Get("X-RateLimit-Used"))
+	if reset, err := strconv.ParseInt(header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		rl.Reset = time.Unix(reset, 0)
+	}
+
+	if retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil {
+			rl.RetryAfter = time.Duration(seconds) * time.Second
+		} else if date, err := http.ParseTime(retryAfter); err == nil {
+			rl.RetryAfter = time.Until(date)
+		}
+	}
+	return rl
+}
+
+// isRateLimited 判断响应是否因速率限制被拒绝
+//
+// GitHub的主速率限制返回403（或429）且X-RateLimit-Remaining为0，
+// 次级速率限制返回403/429并携带Retry-After。
+func isRateLimited(statusCode int, rl *types.RateLimit) bool {
+	if statusCode != http.StatusForbidden && statusCode != http.StatusTooManyRequests {
+		return false
+	}
+	if statusCode == http.StatusTooManyRequests {
+		return true
+	}
+	return rl != nil && (rl.RetryAfter > 0 || (rl.Limit > 0 && rl.Remaining == 0))
+}
+
+// rateLimitWait 计算速率限制解除前需要等待的时间
+func rateLimitWait(rl *types.RateLimit) time.Duration {
+	if rl == nil {
+		return 0
+	}
+	if rl.RetryAfter > 0 {
+		return rl.RetryAfter
+	}
+	if !rl.Reset.IsZero() {
+		if wait := time.Until(rl.Reset); wait > 0 {
+			return wait
+		}
+	}
+	return 0
+}
+
+// RateLimitError API速率限制错误
+type RateLimitError struct {
+	Source        string
+	StatusCode    int
+	RateLimit     *types.RateLimit
+	Authenticated bool
+	GitHub        bool // GitHub源：提示使用GH_TOKEN
+}
+
+// Error 实现error接口
+func (e *RateLimitError) Error() string {
+	msg := fmt.Sprintf("%s rate limit exceeded", e.Source)
+	if rl := e.RateLimit; rl != nil && rl.Limit > 0 {
+		msg += fmt.Sprintf(" (%d/%d requests remaining", rl.Remaining, rl.Limit)
+		if !rl.Reset.IsZero() {
+			msg += fmt.Sprintf(", resets at %s", rl.Reset.Local().Format("15:04:05"))
+		}
+		msg += ")"
+	}
+	if wait := rateLimitWait(e.RateLimit); wait > 0 {
+		msg += fmt.Sprintf("; retry in %s", wait.Round(time.Second))
+	}
+	if !e.Authenticated {
+		if e.GitHub {
+			msg += "; set GH_TOKEN (or pass --token) to use the authenticated limit of 5000 requests per hour"
+		} else {
+			msg += "; pass --token to authenticate"
+		}
+	}
+	return msg
+}
+
+// WaitTime 速率限制解除前需要等待的时间
+func (e *RateLimitError) WaitTime() time.Duration {
+	return rateLimitWait(e.RateLimit)
+}
+
+// asRateLimitError 从错误链中取出速率限制错误
+func asRateLimitError(err error) (*RateLimitError, bool) {
+	var rlErr *RateLimitError
+	ok := errors.As(err, &rlErr)
+	return rlErr, ok
+}
+
+// FetchRateLimit 查询GitHub API的剩余配额
+//
+// /rate_limit 接口本身不消耗配额。token为空时返回匿名（按IP计算）的配额。
+func FetchRateLimit(ctx context.Context, networkConfig *types.NetworkConfig, token string) (*types.RateLimit, error) {
+	transport, err := newNetworkTransport(networkConfig)
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{Timeout: 10 * time.Second, Transport: transport}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, apiBaseURL(networkConfig)+"/rate_limit", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+	req.Header.Set("User-Agent", "Specify-CLI/1.0.0")
+	if token != "" {
+		req.Header.Set("Authorization", "token "+token)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rate limit: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GitHub API returned status %d for /rate_limit", resp.StatusCode)
+	}
+
+	var body struct {
+		Resources struct {
+			Core struct {
+				Limit     int   `json:"limit"`
+				Remaining int   `json:"remaining"`
+				Used      int   `json:"used"`
+				Reset     int64 `json:"reset"`
+			} `json:"core"`
+		} `json:"resources"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to parse rate limit: %w", err)
+	}
+
+	core := body.Resources.Core
+	return &types.RateLimit{
+		Limit:     core.Limit,
+		Remaining: core.Remaining,
+		Used:      core.Used,
+		Reset:     time.Unix(core.Reset, 0),
+		Resource:  "core",
+	}, nil
+}
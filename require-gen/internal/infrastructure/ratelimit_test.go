@@ -0,0 +1,132 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"specify-cli/internal/types"
+)
+
+// TestParseRateLimit 测试速率限制响应头解析
+func TestParseRateLimit(t *testing.T) {
+	assert.Nil(t, ParseRateLimit(http.Header{}))
+
+	reset := time.Now().Add(30 * time.Minute).Unix()
+	header := http.Header{}
+	header.Set("X-RateLimit-Limit", "60")
+	header.Set("X-RateLimit-Remaining", "0")
+	header.Set("X-RateLimit-Used", "60")
+	header.Set("X-RateLimit-Reset", fmt.Sprint(reset))
+	header.Set("X-RateLimit-Resource", "core")
+
+	rl := ParseRateLimit(header)
+	require.NotNil(t, rl)
+	assert.Equal(t, 60, rl.Limit)
+	assert.Equal(t, 0, rl.Remaining)
+	assert.Equal(t, reset, rl.Reset.Unix())
+	assert.True(t, isRateLimited(http.StatusForbidden, rl))
+	assert.False(t, isRateLimited(http.StatusNotFound, rl))
+
+	// 普通的权限错误不是速率限制
+	header.Set("X-RateLimit-Remaining", "59")
+	assert.False(t, isRateLimited(http.StatusForbidden, ParseRateLimit(header)))
+
+	secondary := http.Header{}
+	secondary.Set("Retry-After", "2")
+	rl = ParseRateLimit(secondary)
+	assert.Equal(t, 2*time.Second, rl.RetryAfter)
+	assert.True(t, isRateLimited(http.StatusForbidden, rl))
+}
+
+// TestTemplateProvider_RateLimitFallback 测试速率受限时使用缓存的发布信息并给出GH_TOKEN提示
+func TestTemplateProvider_RateLimitFallback(t *testing.T) {
+	t.Setenv("GH_TOKEN", "")
+	t.Setenv("GITHUB_TOKEN", "")
+
+	var limited atomic.Bool
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if limited.Load() {
+			w.Header().Set("X-RateLimit-Limit", "60")
+			w.Header().Set("X-RateLimit-Remaining", "0")
+			w.Header().Set("X-RateLimit-Reset", fmt.Sprint(time.Now().Add(time.Hour).Unix()))
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"message":"API rate limit exceeded"}`))
+			return
+		}
+		w.Write([]byte(`{"tag_name":"v1.0.0","assets":[]}`))
+	}))
+	defer server.Close()
+
+	tp := NewTemplateProviderWithConfig(&types.NetworkConfig{APIBaseURL: server.URL}, nil).(*TemplateProvider)
+	tp.SetReleaseCacheDir(t.TempDir())
+
+	// 没有缓存时返回带GH_TOKEN提示的错误
+	limited.Store(true)
+	_, err := tp.getLatestRelease(context.Background(), "my-org/spec-kit", "")
+	var rlErr *RateLimitError
+	require.ErrorAs(t, err, &rlErr)
+	assert.Contains(t, err.Error(), "0/60 requests remaining")
+	assert.Contains(t, err.Error(), "GH_TOKEN")
+	assert.Equal(t, int32(1), requests.Load(), "rate-limited responses must not be retried")
+
+	// 成功获取后写入缓存，之后受限时使用缓存
+	limited.Store(false)
+	_, err = tp.getLatestRelease(context.Background(), "my-org/spec-kit", "")
+	require.NoError(t, err)
+
+	limited.Store(true)
+	release, err := tp.getLatestRelease(context.Background(), "my-org/spec-kit", "")
+	require.NoError(t, err)
+	assert.Equal(t, "v1.0.0", release.TagName)
+
+	// 其他仓库没有缓存
+	_, err = tp.getLatestRelease(context.Background(), "other-org/spec-kit", "")
+	assert.Error(t, err)
+}
+
+// TestTemplateProvider_RateLimitWait 测试次级速率限制在短时间内解除时等待后重试
+func TestTemplateProvider_RateLimitWait(t *testing.T) {
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			w.Header().Set("Retry-After", "1")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.Write([]byte(`{"tag_name":"v2.0.0","assets":[]}`))
+	}))
+	defer server.Close()
+
+	tp := NewTemplateProviderWithConfig(&types.NetworkConfig{APIBaseURL: server.URL}, nil).(*TemplateProvider)
+	tp.SetReleaseCacheDir(t.TempDir())
+	release, err := tp.getLatestRelease(context.Background(), "my-org/spec-kit", "token")
+	require.NoError(t, err)
+	assert.Equal(t, "v2.0.0", release.TagName)
+	assert.Equal(t, int32(2), requests.Load())
+}
+
+// TestFetchRateLimit 测试查询剩余配额
+func TestFetchRateLimit(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/rate_limit", r.URL.Path)
+		assert.Equal(t, "token secret", r.Header.Get("Authorization"))
+		w.Write([]byte(`{"resources":{"core":{"limit":5000,"remaining":4990,"used":10,"reset":1700000000}}}`))
+	}))
+	defer server.Close()
+
+	rl, err := FetchRateLimit(context.Background(), &types.NetworkConfig{APIBaseURL: server.URL}, "secret")
+	require.NoError(t, err)
+	assert.Equal(t, 5000, rl.Limit)
+	assert.Equal(t, 4990, rl.Remaining)
+	assert.Equal(t, int64(1700000000), rl.Reset.Unix())
+}
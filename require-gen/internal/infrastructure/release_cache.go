@@ -0,0 +1,97 @@
+package infrastructure
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"specify-cli/internal/types"
+)
+
+// cachedRelease 缓存的发布信息
+type cachedRelease struct {
+	Source    string               `json:"source"`
+	Release   *types.GitHubRelease `json:"release"`
+	FetchedAt time.Time            `json:"fetched_at"`
+	httpValidators
+}
+
+// releaseCache 发布信息缓存
+//
+// 每次成功获取发布信息后连同ETag/Last-Modified写入用户缓存目录
+// （Linux为~/.cache/specify/releases）。之后的请求带上这些校验值发送条件请求，
+// 发布信息未变化时直接使用缓存；API速率受限或不可达时也使用最近一次的结果，
+// 使init和download仍可继续。
+type releaseCache struct {
+	dir string
+}
+
+// defaultReleaseCacheDir 返回用户缓存目录下的发布信息缓存目录，无法确定时返回空字符串
+//
+// 测试替换该函数，避免写入开发者的用户缓存目录。
+var defaultReleaseCacheDir = func() string {
+	cacheDir, err := NewSystemOperations().GetPlatformSpecificPath("cache")
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(cacheDir, "specify", "releases")
+}
+
+// newReleaseCache 创建位于dir的发布信息缓存，dir为空时禁用缓存
+func newReleaseCache(dir string) *releaseCache {
+	return &releaseCache{dir: dir}
+}
+
+// path 模板源对应的缓存文件
+func (c *releaseCache) path(source string) string {
+	sum := sha256.Sum256([]byte(source))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:8])+".json")
+}
+
+// Load 读取模板源的缓存发布信息
+func (c *releaseCache) Load(source string) (*cachedRelease, error) {
+	if c == nil || c.dir == "" {
+		return nil, fmt.Errorf("release cache is not available")
+	}
+
+	data, err := os.ReadFile(c.path(source))
+	if err != nil {
+		return nil, err
+	}
+
+	var entry cachedRelease
+	if err := json.Unmarshal(data, &entry); err != nil {
+		return nil, fmt.Errorf("invalid release cache: %w", err)
+	}
+	if entry.Source != source || entry.Release == nil {
+		return nil, fmt.Errorf("release cache does not match %s", source)
+	}
+	return &entry, nil
+}
+
+// Store 写入模板源的发布信息
+func (c *releaseCache) Store(entry *cachedRelease) error {
+	if c == nil || c.dir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(c.dir, 0755); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+
+	// 先写临时文件再重命名，避免并发运行时读到不完整的缓存
+	path := c.path(entry.Source)
+	tmp := fmt.Sprintf("%s.%d.tmp", path, os.Getpid())
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
@@ -0,0 +1,59 @@
+package infrastructure
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"specify-cli/internal/types"
+)
+
+// TestTemplateProvider_ConditionalRelease 测试使用ETag发送条件请求，304时使用缓存的发布信息
+func TestTemplateProvider_ConditionalRelease(t *testing.T) {
+
+	var requests, notModified atomic.Int32
+	var tag atomic.Value
+	tag.Store("v1.0.0")
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		etag := `"` + tag.Load().(string) + `"`
+		if r.Header.Get("If-None-Match") == etag {
+			notModified.Add(1)
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Write([]byte(`{"tag_name":"` + tag.Load().(string) + `","assets":[]}`))
+	}))
+	defer server.Close()
+
+	tp := NewTemplateProviderWithConfig(&types.NetworkConfig{APIBaseURL: server.URL}, nil).(*TemplateProvider)
+	tp.SetReleaseCacheDir(t.TempDir())
+
+	release, err := tp.getLatestRelease(context.Background(), "my-org/spec-kit", "")
+	require.NoError(t, err)
+	assert.Equal(t, "v1.0.0", release.TagName)
+	assert.Equal(t, int32(0), notModified.Load())
+
+	// 发布信息未变化：服务器返回304，使用缓存
+	release, err = tp.getLatestRelease(context.Background(), "my-org/spec-kit", "")
+	require.NoError(t, err)
+	assert.Equal(t, "v1.0.0", release.TagName)
+	assert.Equal(t, int32(1), notModified.Load())
+
+	// 新的发布：ETag变化后返回新的发布信息并更新缓存
+	tag.Store("v1.1.0")
+	release, err = tp.getLatestRelease(context.Background(), "my-org/spec-kit", "")
+	require.NoError(t, err)
+	assert.Equal(t, "v1.1.0", release.TagName)
+
+	release, err = tp.getLatestRelease(context.Background(), "my-org/spec-kit", "")
+	require.NoError(t, err)
+	assert.Equal(t, "v1.1.0", release.TagName)
+	assert.Equal(t, int32(2), notModified.Load())
+	assert.Equal(t, int32(4), requests.Load())
+}
@@ -3,6 +3,7 @@ package infrastructure
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"math"
 	"sync"
 	"time"
@@ -18,6 +19,7 @@ type RetryManager struct {
 	strategies    map[string]*RetryStrategy
 	activeRetries map[string]*RetryContext
 	stats         *RetryStatistics
+	logger        *slog.Logger
 }
 
 // RetryManagerConfig 重试管理器配置
@@ -126,6 +128,7 @@ func NewRetryManager(config *RetryManagerConfig, errorHandler *NetworkErrorHandl
 			RetriesByHost:      make(map[string]int64),
 			RetriesByOperation: make(map[string]int64),
 		},
+		logger: DefaultLogger(),
 	}
 	
 	// 初始化默认策略
@@ -216,8 +219,17 @@ func (rm *RetryManager) ExecuteWithRetry(ctx context.Context, operation RetryOpe
 			result.AttemptCount = attempt + 1
 			result.TotalDuration = time.Since(startTime)
 			
+			if attempt > 0 {
+				rm.getLogger().Info("operation succeeded after retry",
+					"operation", retryCtx.Operation, "host", retryCtx.Host,
+					"attempts", attempt+1, "duration", result.TotalDuration)
+			}
+
 			// 记录成功统计
 			rm.recordSuccess(retryCtx)
+			if retryCtx.Host != "" {
+				rm.errorHandler.RecordSuccess(retryCtx.Host)
+			}
 			
 			// 调用成功回调
 			if retryCtx.OnSuccess != nil {
@@ -239,6 +251,11 @@ func (rm *RetryManager) ExecuteWithRetry(ctx context.Context, operation RetryOpe
 			result.TotalDuration = time.Since(startTime)
 			result.LastError = networkErr
 			
+			rm.getLogger().Warn("operation failed",
+				"operation", retryCtx.Operation, "host", retryCtx.Host,
+				"attempts", attempt+1, "error_type", networkErr.Type.String(),
+				"error", networkErr.Message)
+
 			// 记录失败统计
 			rm.recordFailure(retryCtx)
 			
@@ -253,6 +270,12 @@ func (rm *RetryManager) ExecuteWithRetry(ctx context.Context, operation RetryOpe
 		// 计算重试延迟
 		delay := rm.calculateRetryDelay(networkErr, attempt, retryCtx.Strategy)
 		
+		rm.getLogger().Info("retrying operation",
+			"operation", retryCtx.Operation, "host", retryCtx.Host,
+			"attempt", attempt+1, "max_retries", retryCtx.MaxRetries,
+			"delay", delay, "error_type", networkErr.Type.String(),
+			"error", networkErr.Message)
+
 		// 调用重试回调
 		if retryCtx.OnRetry != nil {
 			retryCtx.OnRetry(attempt+1, delay)
@@ -492,6 +515,23 @@ func (rm *RetryManager) GetRetryStatistics() RetryStatistics {
 	return *rm.stats
 }
 
+// SetLogger 设置日志器
+func (rm *RetryManager) SetLogger(logger *slog.Logger) {
+	if logger == nil {
+		return
+	}
+	rm.mu.Lock()
+	defer rm.mu.Unlock()
+	rm.logger = logger
+}
+
+// getLogger 获取当前日志器
+func (rm *RetryManager) getLogger() *slog.Logger {
+	rm.mu.RLock()
+	defer rm.mu.RUnlock()
+	return rm.logger
+}
+
 // AddStrategy 添加重试策略
 func (rm *RetryManager) AddStrategy(name string, strategy *RetryStrategy) {
 	rm.mu.Lock()
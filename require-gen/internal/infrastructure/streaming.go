@@ -1,6 +1,7 @@
 package infrastructure
 
 import (
+	"context"
 	"crypto/md5"
 	"crypto/sha1"
 	"crypto/sha256"
@@ -24,6 +25,8 @@ type StreamingDownloader struct {
 	maxRetries   int
 	retryWait    time.Duration
 	maxRetryWait time.Duration
+	headers      map[string]string
+	limiter      *BandwidthLimiter
 }
 
 // NewStreamingDownloader 创建流式下载器
@@ -41,22 +44,63 @@ func NewStreamingDownloader(client *http.Client, chunkSize int64) *StreamingDown
 	}
 }
 
+// SetHeaders 设置每个下载请求附加的HTTP头
+func (sd *StreamingDownloader) SetHeaders(headers map[string]string) {
+	sd.headers = headers
+}
+
+// SetBandwidthLimiter 设置各分块共享的限速器
+//
+// 未设置时每次下载按DownloadOptions.LimitRate单独限速。
+func (sd *StreamingDownloader) SetBandwidthLimiter(limiter *BandwidthLimiter) {
+	sd.limiter = limiter
+}
+
+// setHeaders 为请求添加附加的HTTP头
+func (sd *StreamingDownloader) setHeaders(req *http.Request) {
+	for name, value := range sd.headers {
+		req.Header.Set(name, value)
+	}
+}
+
 // DownloadWithStreaming 流式下载文件
-func (sd *StreamingDownloader) DownloadWithStreaming(url, filePath string, opts *types.DownloadOptions) error {
+//
+// 所有请求都绑定ctx，取消后正在进行的请求立即中断，不再发起新的分块请求。
+func (sd *StreamingDownloader) DownloadWithStreaming(ctx context.Context, url, filePath string, opts *types.DownloadOptions) error {
 	// 获取文件信息
-	size, supportsRange, err := sd.getFileInfo(url)
+	size, supportsRange, err := sd.getFileInfo(ctx, url)
 	if err != nil {
 		return CreateNetworkError(fmt.Errorf("failed to get file info: %w", err), url, 0)
 	}
 
+	// 创建校验和计算器
+	var hasher hash.Hash
+	if opts.VerifyChecksum && opts.Checksum != "" {
+		hasher = sd.createHasher(opts.ChecksumType)
+	}
+
 	// 检查是否支持断点续传
 	var startPos int64 = 0
 	if opts.EnableResume && supportsRange {
 		if fileInfo, err := os.Stat(filePath); err == nil {
 			startPos = fileInfo.Size()
-			if startPos >= size {
-				// 文件已完整下载
-				return nil
+			if startPos > size {
+				// 本地文件比远程文件大，不是它的部分下载，重新下载
+				startPos = 0
+			} else if startPos == size {
+				// 文件已完整下载，仍需校验内容
+				if hasher == nil {
+					return nil
+				}
+				if err := hashFilePrefix(hasher, filePath, size); err != nil {
+					return err
+				}
+				return sd.verifyChecksum(hasher, opts.Checksum)
+			} else if hasher != nil {
+				// 续传时校验和覆盖整个文件，先计算已下载部分
+				if err := hashFilePrefix(hasher, filePath, startPos); err != nil {
+					return err
+				}
 			}
 		}
 	}
@@ -80,14 +124,14 @@ func (sd *StreamingDownloader) DownloadWithStreaming(url, filePath string, opts
 		progressDisplay.Start(size)
 	}
 
-	// 创建校验和计算器
-	var hasher hash.Hash
-	if opts.VerifyChecksum && opts.Checksum != "" {
-		hasher = sd.createHasher(opts.ChecksumType)
+	// 限速器在所有分块之间共享
+	limiter := sd.limiter
+	if limiter == nil {
+		limiter = NewBandwidthLimiter(opts.LimitRate)
 	}
 
 	// 流式下载
-	err = sd.streamDownload(url, file, startPos, size, opts, progressDisplay, hasher)
+	err = sd.streamDownload(ctx, url, file, startPos, size, opts, progressDisplay, hasher, limiter)
 	if err != nil {
 		return err
 	}
@@ -107,12 +151,27 @@ func (sd *StreamingDownloader) DownloadWithStreaming(url, filePath string, opts
 	return nil
 }
 
+// hashFilePrefix 将文件的前n个字节写入校验和计算器
+func hashFilePrefix(hasher hash.Hash, filePath string, n int64) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read partial download: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := io.CopyN(hasher, file, n); err != nil {
+		return fmt.Errorf("failed to read partial download: %w", err)
+	}
+	return nil
+}
+
 // getFileInfo 获取文件信息
-func (sd *StreamingDownloader) getFileInfo(url string) (size int64, supportsRange bool, err error) {
-	req, err := http.NewRequest("HEAD", url, nil)
+func (sd *StreamingDownloader) getFileInfo(ctx context.Context, url string) (size int64, supportsRange bool, err error) {
+	req, err := http.NewRequestWithContext(ctx, "HEAD", url, nil)
 	if err != nil {
 		return 0, false, err
 	}
+	sd.setHeaders(req)
 
 	resp, err := sd.client.Do(req)
 	if err != nil {
@@ -138,11 +197,15 @@ func (sd *StreamingDownloader) getFileInfo(url string) (size int64, supportsRang
 }
 
 // streamDownload 执行流式下载
-func (sd *StreamingDownloader) streamDownload(url string, file *os.File, startPos, totalSize int64, opts *types.DownloadOptions, progressDisplay types.ProgressDisplay, hasher hash.Hash) error {
+func (sd *StreamingDownloader) streamDownload(ctx context.Context, url string, file *os.File, startPos, totalSize int64, opts *types.DownloadOptions, progressDisplay types.ProgressDisplay, hasher hash.Hash, limiter *BandwidthLimiter) error {
 	var downloaded int64 = startPos
 	startTime := time.Now()
 
 	for downloaded < totalSize {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
 		// 计算当前块的范围
 		endPos := downloaded + sd.chunkSize - 1
 		if endPos >= totalSize {
@@ -151,7 +214,7 @@ func (sd *StreamingDownloader) streamDownload(url string, file *os.File, startPo
 
 		// 下载当前块
 		err := ExecuteWithRetry(func() error {
-			return sd.downloadChunk(url, file, downloaded, endPos, &downloaded, totalSize, startTime, opts, progressDisplay, hasher)
+			return sd.downloadChunk(ctx, url, file, downloaded, endPos, &downloaded, totalSize, startTime, opts, progressDisplay, hasher, limiter)
 		}, sd.maxRetries, sd.retryWait, sd.maxRetryWait)
 
 		if err != nil {
@@ -163,11 +226,12 @@ func (sd *StreamingDownloader) streamDownload(url string, file *os.File, startPo
 }
 
 // downloadChunk 下载单个数据块
-func (sd *StreamingDownloader) downloadChunk(url string, file *os.File, startPos, endPos int64, downloaded *int64, totalSize int64, startTime time.Time, opts *types.DownloadOptions, progressDisplay types.ProgressDisplay, hasher hash.Hash) error {
-	req, err := http.NewRequest("GET", url, nil)
+func (sd *StreamingDownloader) downloadChunk(ctx context.Context, url string, file *os.File, startPos, endPos int64, downloaded *int64, totalSize int64, startTime time.Time, opts *types.DownloadOptions, progressDisplay types.ProgressDisplay, hasher hash.Hash, limiter *BandwidthLimiter) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return CreateNetworkError(err, url, 0)
 	}
+	sd.setHeaders(req)
 
 	// 设置范围请求头
 	if startPos > 0 || endPos < totalSize-1 {
@@ -191,7 +255,8 @@ func (sd *StreamingDownloader) downloadChunk(url string, file *os.File, startPos
 
 	// 创建进度读取器
 	reader := io.Reader(resp.Body)
-	if progressDisplay != nil || opts.ProgressCallback != nil {
+	tracked := progressDisplay != nil || opts.ProgressCallback != nil
+	if tracked {
 		reader = &ChunkProgressReader{
 			Reader:           resp.Body,
 			downloaded:       downloaded,
@@ -202,6 +267,9 @@ func (sd *StreamingDownloader) downloadChunk(url string, file *os.File, startPos
 		}
 	}
 
+	// 限速读取器包裹进度读取器，进度按实际写入的速度更新
+	reader = limiter.Reader(ctx, reader)
+
 	// 如果需要校验和，创建多重写入器
 	var writer io.Writer = file
 	if hasher != nil {
@@ -209,7 +277,11 @@ func (sd *StreamingDownloader) downloadChunk(url string, file *os.File, startPos
 	}
 
 	// 复制数据
-	_, err = io.Copy(writer, reader)
+	written, err := io.Copy(writer, reader)
+	if !tracked {
+		// 没有进度读取器时在这里累计已下载的字节数，否则分块循环不会前进
+		*downloaded += written
+	}
 	if err != nil {
 		return CreateNetworkError(fmt.Errorf("failed to copy data: %w", err), url, 0)
 	}
@@ -322,7 +394,7 @@ func NewConcurrentDownloader(client *http.Client, chunkSize int64, maxConcurrent
 }
 
 // DownloadConcurrently 并发下载多个文件
-func (cd *ConcurrentDownloader) DownloadConcurrently(downloads []DownloadTask) error {
+func (cd *ConcurrentDownloader) DownloadConcurrently(ctx context.Context, downloads []DownloadTask) error {
 	semaphore := make(chan struct{}, cd.maxConcurrent)
 	var wg sync.WaitGroup
 	var mu sync.Mutex
@@ -338,7 +410,7 @@ func (cd *ConcurrentDownloader) DownloadConcurrently(downloads []DownloadTask) e
 			defer func() { <-semaphore }()
 
 			// 执行下载
-			err := cd.streamingDownloader.DownloadWithStreaming(task.URL, task.FilePath, task.Options)
+			err := cd.streamingDownloader.DownloadWithStreaming(ctx, task.URL, task.FilePath, task.Options)
 			if err != nil {
 				mu.Lock()
 				errors = append(errors, fmt.Errorf("failed to download %s: %w", task.URL, err))
@@ -0,0 +1,42 @@
+package infrastructure
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"specify-cli/internal/types"
+)
+
+// TestStreamingDownloader_ChunksWithoutProgress 测试没有进度显示时分块下载逐块前进
+func TestStreamingDownloader_ChunksWithoutProgress(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 1000)
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		http.ServeContent(w, r, "template.zip", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+
+	// 分块位置不前进时会反复请求第一个分块，直到超时
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	downloader := NewStreamingDownloader(server.Client(), 4096)
+	dest := filepath.Join(t.TempDir(), "template.zip")
+	require.NoError(t, downloader.DownloadWithStreaming(ctx, server.URL, dest, &types.DownloadOptions{}))
+
+	data, err := os.ReadFile(dest)
+	require.NoError(t, err)
+	assert.Equal(t, content, data)
+	// 一次HEAD请求和三个分块请求
+	assert.Equal(t, int32(4), atomic.LoadInt32(&requests))
+}
@@ -0,0 +1,603 @@
+package infrastructure
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// StructuredMergeResult 结构化合并结果
+//
+// 记录模板文件合并到现有配置文件时新增的键以及因冲突而保留用户值的键，
+// 键路径使用点号连接（例如 "editor.formatOnSave"）。
+type StructuredMergeResult struct {
+	Added     []string // 从模板新增的键
+	Preserved []string // 与模板取值不同、保留用户取值的键
+}
+
+// Changed 是否有新增内容需要写回文件
+func (r *StructuredMergeResult) Changed() bool {
+	return len(r.Added) > 0
+}
+
+// Summary 生成合并结果摘要
+func (r *StructuredMergeResult) Summary() string {
+	if !r.Changed() && len(r.Preserved) == 0 {
+		return "no changes"
+	}
+	parts := []string{fmt.Sprintf("%d key(s) added", len(r.Added))}
+	if len(r.Added) > 0 {
+		parts[0] += fmt.Sprintf(" (%s)", strings.Join(r.Added, ", "))
+	}
+	if len(r.Preserved) > 0 {
+		parts = append(parts, fmt.Sprintf("%d existing value(s) kept", len(r.Preserved)))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// StructuredFormat 根据文件扩展名判断可结构化合并的格式，不支持时返回空字符串
+func StructuredFormat(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return "json"
+	case ".yaml", ".yml":
+		return "yaml"
+	case ".toml":
+		return "toml"
+	default:
+		return ""
+	}
+}
+
+// MergeStructuredFile 将模板配置深度合并到现有配置中
+//
+// 合并规则：
+//   - 模板中存在而现有文件中缺失的键会被添加
+//   - 两边都是对象（映射）时递归合并
+//   - 其他情况（标量、数组、类型不一致）一律保留用户的值
+//
+// JSON和YAML保持现有文件的键顺序（YAML同时保留注释），
+// TOML由于编码器限制会按键名重新排序。JSON按JSONC解析（允许注释和末尾逗号，
+// 如VS Code的settings.json），有新增键需要写回时注释不会保留。
+//
+// 参数：
+//
+//	existing - 现有文件内容
+//	incoming - 模板文件内容
+//	format - 文件格式：json, yaml, toml
+//
+// 返回值：
+//
+//	[]byte - 合并后的内容（没有新增键时返回原内容）
+//	*StructuredMergeResult - 合并结果
+//	error - 任一文件无法解析或顶层不是对象时返回错误
+func MergeStructuredFile(existing, incoming []byte, format string) ([]byte, *StructuredMergeResult, error) {
+	switch format {
+	case "json":
+		return mergeJSON(existing, incoming)
+	case "yaml":
+		return mergeYAML(existing, incoming)
+	case "toml":
+		return mergeTOML(existing, incoming)
+	default:
+		return nil, nil, fmt.Errorf("unsupported structured format: %s", format)
+	}
+}
+
+// mergeConflictFile 将冲突的模板文件结构化合并到现有文件
+//
+// 解析失败时保留现有文件不变（不会退化为覆盖），并通过OnMerge回调报告错误，
+// 避免带注释或格式特殊的用户配置被模板替换。
+func mergeConflictFile(conflictInfo *FileConflictInfo, opts *ExtractOptions) error {
+	result, err := mergeIntoExisting(conflictInfo)
+	if opts.OnMerge != nil {
+		opts.OnMerge(conflictInfo.TargetPath, result, err)
+	} else if opts.Verbose {
+		if err != nil {
+			fmt.Printf("Keeping existing file %s: %v\n", conflictInfo.TargetPath, err)
+		} else {
+			fmt.Printf("Merged %s: %s\n", conflictInfo.TargetPath, result.Summary())
+		}
+	}
+	return nil
+}
+
+// mergeIntoExisting 读取两侧内容、合并并写回目标文件
+func mergeIntoExisting(conflictInfo *FileConflictInfo) (*StructuredMergeResult, error) {
+	format := StructuredFormat(conflictInfo.TargetPath)
+	if format == "" || conflictInfo.ReadSource == nil {
+		return nil, fmt.Errorf("file type does not support structured merge")
+	}
+
+	incoming, err := conflictInfo.ReadSource()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read template file: %w", err)
+	}
+
+	stat, err := os.Stat(conflictInfo.TargetPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat existing file: %w", err)
+	}
+	existing, err := os.ReadFile(conflictInfo.TargetPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read existing file: %w", err)
+	}
+
+	merged, result, err := MergeStructuredFile(existing, incoming, format)
+	if err != nil {
+		return nil, err
+	}
+
+	if result.Changed() {
+		if err := os.WriteFile(conflictInfo.TargetPath, merged, stat.Mode().Perm()); err != nil {
+			return nil, fmt.Errorf("failed to write merged file: %w", err)
+		}
+	}
+	return result, nil
+}
+
+// ---------------------------------------------------------------------------
+// JSON
+// ---------------------------------------------------------------------------
+
+// jsonMember 有序JSON对象成员
+type jsonMember struct {
+	Key   string
+	Value interface{}
+}
+
+// jsonObject 保持键顺序的JSON对象
+type jsonObject []jsonMember
+
+// get 查找成员索引
+func (o jsonObject) get(key string) int {
+	for i, m := range o {
+		if m.Key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// mergeJSON 合并JSON文件
+func mergeJSON(existing, incoming []byte) ([]byte, *StructuredMergeResult, error) {
+	dst, err := decodeOrderedJSON(existing)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse existing JSON: %w", err)
+	}
+	src, err := decodeOrderedJSON(incoming)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse template JSON: %w", err)
+	}
+
+	dstObj, ok := dst.(jsonObject)
+	if !ok {
+		return nil, nil, fmt.Errorf("existing JSON top-level value is not an object")
+	}
+	srcObj, ok := src.(jsonObject)
+	if !ok {
+		return nil, nil, fmt.Errorf("template JSON top-level value is not an object")
+	}
+
+	result := &StructuredMergeResult{}
+	merged := mergeJSONObjects(dstObj, srcObj, "", result)
+	if !result.Changed() {
+		return existing, result, nil
+	}
+
+	var buf bytes.Buffer
+	writeJSONValue(&buf, merged, detectIndent(existing), 0)
+	buf.WriteByte('\n')
+	return buf.Bytes(), result, nil
+}
+
+// mergeJSONObjects 递归合并有序JSON对象
+func mergeJSONObjects(dst, src jsonObject, prefix string, result *StructuredMergeResult) jsonObject {
+	for _, member := range src {
+		path := joinKeyPath(prefix, member.Key)
+		idx := dst.get(member.Key)
+		if idx < 0 {
+			dst = append(dst, member)
+			result.Added = append(result.Added, path)
+			continue
+		}
+
+		dstChild, dstIsObj := dst[idx].Value.(jsonObject)
+		srcChild, srcIsObj := member.Value.(jsonObject)
+		if dstIsObj && srcIsObj {
+			dst[idx].Value = mergeJSONObjects(dstChild, srcChild, path, result)
+			continue
+		}
+
+		if !jsonValuesEqual(dst[idx].Value, member.Value) {
+			result.Preserved = append(result.Preserved, path)
+		}
+	}
+	return dst
+}
+
+// decodeOrderedJSON 解码JSON（允许JSONC注释和末尾逗号）并保持对象键顺序
+func decodeOrderedJSON(data []byte) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader(stripJSONC(data)))
+	dec.UseNumber()
+	value, err := decodeJSONValue(dec)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return nil, fmt.Errorf("unexpected trailing data")
+	}
+	return value, nil
+}
+
+// stripJSONC 去除JSONC中的注释（// 和 /* */）以及对象、数组的末尾逗号
+//
+// 注释替换为空格（保留换行），字符串内的内容不受影响，
+// 使结果可以由标准JSON解码器解析且错误位置的行号不变。
+func stripJSONC(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	inString := false
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case inString:
+			out = append(out, c)
+			if c == '\\' && i+1 < len(data) {
+				i++
+				out = append(out, data[i])
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+			out = append(out, c)
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i < len(data) && data[i] != '\n' {
+				out = append(out, ' ')
+				i++
+			}
+			if i < len(data) {
+				out = append(out, '\n')
+			}
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			out = append(out, ' ', ' ')
+			i += 2
+			for i < len(data) && !(data[i] == '*' && i+1 < len(data) && data[i+1] == '/') {
+				if data[i] == '\n' {
+					out = append(out, '\n')
+				} else {
+					out = append(out, ' ')
+				}
+				i++
+			}
+			if i < len(data) {
+				out = append(out, ' ', ' ')
+				i++
+			}
+		default:
+			out = append(out, c)
+		}
+	}
+
+	// 去除末尾逗号：逗号之后（跳过空白）紧跟 } 或 ]
+	inString = false
+	for i := 0; i < len(out); i++ {
+		c := out[i]
+		if inString {
+			if c == '\\' {
+				i++
+			} else if c == '"' {
+				inString = false
+			}
+			continue
+		}
+		if c == '"' {
+			inString = true
+			continue
+		}
+		if c != ',' {
+			continue
+		}
+		j := i + 1
+		for j < len(out) && (out[j] == ' ' || out[j] == '\t' || out[j] == '\n' || out[j] == '\r') {
+			j++
+		}
+		if j < len(out) && (out[j] == '}' || out[j] == ']') {
+			out[i] = ' '
+		}
+	}
+	return out
+}
+
+// decodeJSONValue 递归解码单个JSON值
+func decodeJSONValue(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '{':
+			obj := jsonObject{}
+			for dec.More() {
+				keyTok, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+				key, ok := keyTok.(string)
+				if !ok {
+					return nil, fmt.Errorf("invalid object key: %v", keyTok)
+				}
+				value, err := decodeJSONValue(dec)
+				if err != nil {
+					return nil, err
+				}
+				obj = append(obj, jsonMember{Key: key, Value: value})
+			}
+			if _, err := dec.Token(); err != nil {
+				return nil, err
+			}
+			return obj, nil
+		case '[':
+			arr := []interface{}{}
+			for dec.More() {
+				value, err := decodeJSONValue(dec)
+				if err != nil {
+					return nil, err
+				}
+				arr = append(arr, value)
+			}
+			if _, err := dec.Token(); err != nil {
+				return nil, err
+			}
+			return arr, nil
+		default:
+			return nil, fmt.Errorf("unexpected delimiter: %v", t)
+		}
+	default:
+		return tok, nil
+	}
+}
+
+// writeJSONValue 按指定缩进输出有序JSON值
+func writeJSONValue(buf *bytes.Buffer, value interface{}, indent string, depth int) {
+	pad := strings.Repeat(indent, depth)
+	childPad := pad + indent
+
+	switch v := value.(type) {
+	case jsonObject:
+		if len(v) == 0 {
+			buf.WriteString("{}")
+			return
+		}
+		buf.WriteString("{\n")
+		for i, m := range v {
+			buf.WriteString(childPad)
+			writeJSONScalar(buf, m.Key)
+			buf.WriteString(": ")
+			writeJSONValue(buf, m.Value, indent, depth+1)
+			if i < len(v)-1 {
+				buf.WriteByte(',')
+			}
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(pad + "}")
+	case []interface{}:
+		if len(v) == 0 {
+			buf.WriteString("[]")
+			return
+		}
+		buf.WriteString("[\n")
+		for i, item := range v {
+			buf.WriteString(childPad)
+			writeJSONValue(buf, item, indent, depth+1)
+			if i < len(v)-1 {
+				buf.WriteByte(',')
+			}
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(pad + "]")
+	default:
+		writeJSONScalar(buf, v)
+	}
+}
+
+// writeJSONScalar 输出JSON标量（不转义HTML字符）
+func writeJSONScalar(buf *bytes.Buffer, value interface{}) {
+	var tmp bytes.Buffer
+	enc := json.NewEncoder(&tmp)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(value); err != nil {
+		buf.WriteString("null")
+		return
+	}
+	buf.Write(bytes.TrimRight(tmp.Bytes(), "\n"))
+}
+
+// jsonValuesEqual 比较两个JSON值是否相同
+func jsonValuesEqual(a, b interface{}) bool {
+	var bufA, bufB bytes.Buffer
+	writeJSONValue(&bufA, a, "", 0)
+	writeJSONValue(&bufB, b, "", 0)
+	return bufA.String() == bufB.String()
+}
+
+// detectIndent 检测现有文件使用的缩进，默认两个空格
+func detectIndent(data []byte) string {
+	for _, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" || len(trimmed) == len(line) {
+			continue
+		}
+		return line[:len(line)-len(trimmed)]
+	}
+	return "  "
+}
+
+// ---------------------------------------------------------------------------
+// YAML
+// ---------------------------------------------------------------------------
+
+// mergeYAML 合并YAML文件（基于yaml.Node，保留键顺序和注释）
+func mergeYAML(existing, incoming []byte) ([]byte, *StructuredMergeResult, error) {
+	var dstDoc, srcDoc yaml.Node
+	if err := yaml.Unmarshal(existing, &dstDoc); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse existing YAML: %w", err)
+	}
+	if err := yaml.Unmarshal(incoming, &srcDoc); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse template YAML: %w", err)
+	}
+
+	dst := yamlRootMapping(&dstDoc)
+	if dst == nil {
+		return nil, nil, fmt.Errorf("existing YAML top-level value is not a mapping")
+	}
+	src := yamlRootMapping(&srcDoc)
+	if src == nil {
+		return nil, nil, fmt.Errorf("template YAML top-level value is not a mapping")
+	}
+
+	result := &StructuredMergeResult{}
+	mergeYAMLMappings(dst, src, "", result)
+	if !result.Changed() {
+		return existing, result, nil
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&dstDoc); err != nil {
+		return nil, nil, fmt.Errorf("failed to encode merged YAML: %w", err)
+	}
+	if err := enc.Close(); err != nil {
+		return nil, nil, fmt.Errorf("failed to encode merged YAML: %w", err)
+	}
+	return buf.Bytes(), result, nil
+}
+
+// yamlRootMapping 获取文档的顶层映射节点
+func yamlRootMapping(doc *yaml.Node) *yaml.Node {
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return nil
+	}
+	if root := doc.Content[0]; root.Kind == yaml.MappingNode {
+		return root
+	}
+	return nil
+}
+
+// mergeYAMLMappings 递归合并YAML映射节点
+func mergeYAMLMappings(dst, src *yaml.Node, prefix string, result *StructuredMergeResult) {
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		srcKey, srcValue := src.Content[i], src.Content[i+1]
+		path := joinKeyPath(prefix, srcKey.Value)
+
+		var dstValue *yaml.Node
+		for j := 0; j+1 < len(dst.Content); j += 2 {
+			if dst.Content[j].Value == srcKey.Value {
+				dstValue = dst.Content[j+1]
+				break
+			}
+		}
+
+		if dstValue == nil {
+			dst.Content = append(dst.Content, srcKey, srcValue)
+			result.Added = append(result.Added, path)
+			continue
+		}
+
+		if dstValue.Kind == yaml.MappingNode && srcValue.Kind == yaml.MappingNode {
+			mergeYAMLMappings(dstValue, srcValue, path, result)
+			continue
+		}
+
+		var dstRaw, srcRaw interface{}
+		_ = dstValue.Decode(&dstRaw)
+		_ = srcValue.Decode(&srcRaw)
+		if fmt.Sprint(dstRaw) != fmt.Sprint(srcRaw) {
+			result.Preserved = append(result.Preserved, path)
+		}
+	}
+}
+
+// ---------------------------------------------------------------------------
+// TOML
+// ---------------------------------------------------------------------------
+
+// mergeTOML 合并TOML文件
+func mergeTOML(existing, incoming []byte) ([]byte, *StructuredMergeResult, error) {
+	dst := map[string]interface{}{}
+	if _, err := toml.Decode(string(existing), &dst); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse existing TOML: %w", err)
+	}
+	src := map[string]interface{}{}
+	if _, err := toml.Decode(string(incoming), &src); err != nil {
+		return nil, nil, fmt.Errorf("failed to parse template TOML: %w", err)
+	}
+
+	result := &StructuredMergeResult{}
+	mergeMaps(dst, src, "", result)
+	if !result.Changed() {
+		return existing, result, nil
+	}
+
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(dst); err != nil {
+		return nil, nil, fmt.Errorf("failed to encode merged TOML: %w", err)
+	}
+	return buf.Bytes(), result, nil
+}
+
+// mergeMaps 递归合并通用映射
+func mergeMaps(dst, src map[string]interface{}, prefix string, result *StructuredMergeResult) {
+	for _, key := range sortedKeys(src) {
+		srcValue := src[key]
+		path := joinKeyPath(prefix, key)
+
+		dstValue, exists := dst[key]
+		if !exists {
+			dst[key] = srcValue
+			result.Added = append(result.Added, path)
+			continue
+		}
+
+		dstChild, dstIsMap := dstValue.(map[string]interface{})
+		srcChild, srcIsMap := srcValue.(map[string]interface{})
+		if dstIsMap && srcIsMap {
+			mergeMaps(dstChild, srcChild, path, result)
+			continue
+		}
+
+		if fmt.Sprint(dstValue) != fmt.Sprint(srcValue) {
+			result.Preserved = append(result.Preserved, path)
+		}
+	}
+}
+
+// sortedKeys 返回排序后的键列表，保证合并报告顺序稳定
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// joinKeyPath 拼接键路径
+func joinKeyPath(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
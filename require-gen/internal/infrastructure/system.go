@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"log/slog"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -45,6 +46,7 @@ import (
 type SystemOperations struct{
 	securityConfig *SecurityConfig // 安全配置
 	tempManager    *TempFileManager // 临时文件管理器
+	logger         *slog.Logger     // 日志器
 }
 
 // TempFileManager 临时文件管理器
@@ -107,6 +109,7 @@ func NewSystemOperations() types.SystemOperations {
 	sysOps := &SystemOperations{
 		securityConfig: DefaultSecurityConfig(),
 		tempManager:    tempManager,
+		logger:         DefaultLogger(),
 	}
 	
 	// 启动临时文件清理协程
@@ -354,6 +357,8 @@ func (so *SystemOperations) ExecuteCommandWithOptions(name string, args []string
 
 	// 执行命令并捕获输出
 	startTime := time.Now()
+	so.getLogger().Debug("executing command",
+		"command", result.Command, "dir", options.WorkingDir, "timeout", options.Timeout)
 	
 	if options.CaptureOutput {
 		output, err := cmd.CombinedOutput()
@@ -420,6 +425,10 @@ func (so *SystemOperations) ExecuteCommandWithOptions(name string, args []string
 		}
 	}
 
+	so.getLogger().Debug("command finished",
+		"command", result.Command, "success", result.Success,
+		"exit_code", result.ExitCode, "duration", result.Duration)
+
 	return result, nil
 }
 
@@ -1259,6 +1268,21 @@ func (so *SystemOperations) SetSecurityConfig(config *SecurityConfig) {
 	so.securityConfig = config
 }
 
+// SetLogger 设置日志器
+func (so *SystemOperations) SetLogger(logger *slog.Logger) {
+	if logger != nil {
+		so.logger = logger
+	}
+}
+
+// getLogger 获取当前日志器，未设置时使用进程级默认日志器
+func (so *SystemOperations) getLogger() *slog.Logger {
+	if so.logger == nil {
+		return DefaultLogger()
+	}
+	return so.logger
+}
+
 // GetSecurityConfig 获取当前安全配置
 func (so *SystemOperations) GetSecurityConfig() *SecurityConfig {
 	if so.securityConfig == nil {
@@ -1575,7 +1599,7 @@ func (so *SystemOperations) ExtractZipArchive(zipPath, targetDir string, overwri
 	}
 	
 	// 执行ZIP提取
-	return zipProcessor.ExtractZip(zipPath, targetDir, opts)
+	return zipProcessor.ExtractZip(context.Background(), zipPath, targetDir, opts)
 }
 
 // ValidateZipArchive 验证ZIP文件完整性
@@ -1699,6 +1723,7 @@ func (so *SystemOperations) cleanupExpiredTempFiles() {
 				os.Remove(path)
 			}
 			delete(so.tempManager.tempFiles, path)
+			so.getLogger().Debug("removed expired temp file", "path", path, "ttl", info.TTL)
 		}
 	}
 	
@@ -1709,6 +1734,7 @@ func (so *SystemOperations) cleanupExpiredTempFiles() {
 				os.RemoveAll(path)
 			}
 			delete(so.tempManager.tempDirs, path)
+			so.getLogger().Debug("removed expired temp directory", "path", path, "ttl", info.TTL)
 		}
 	}
 	so.tempManager.mu.Unlock()
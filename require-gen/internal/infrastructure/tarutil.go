@@ -4,6 +4,7 @@ import (
 	"archive/tar"
 	"bytes"
 	"compress/gzip"
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -16,9 +17,9 @@ import (
 // TarProcessor 定义TAR文件处理接口
 type TarProcessor interface {
 	// ExtractTar 解压TAR文件到目标目录
-	ExtractTar(tarPath, targetDir string, opts *ExtractOptions) error
+	ExtractTar(ctx context.Context, tarPath, targetDir string, opts *ExtractOptions) error
 	// ExtractWithProgress 带进度显示的TAR解压
-	ExtractWithProgress(tarPath, targetDir string, opts *ExtractOptions, 
+	ExtractWithProgress(ctx context.Context, tarPath, targetDir string, opts *ExtractOptions, 
 		progressCallback func(current, total int64)) error
 }
 
@@ -35,7 +36,7 @@ func NewTarProcessor(sysOps types.SystemOperations) TarProcessor {
 }
 
 // ExtractTar 解压TAR文件到目标目录
-func (tp *TarProcessorImpl) ExtractTar(tarPath, targetDir string, opts *ExtractOptions) error {
+func (tp *TarProcessorImpl) ExtractTar(ctx context.Context, tarPath, targetDir string, opts *ExtractOptions) error {
 	if opts == nil {
 		opts = &ExtractOptions{}
 	}
@@ -83,6 +84,10 @@ func (tp *TarProcessorImpl) ExtractTar(tarPath, targetDir string, opts *ExtractO
 
 	// 解压所有条目
 	for {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("extraction cancelled: %w", err)
+		}
+
 		header, err := tarReader.Next()
 		if err == io.EOF {
 			break // 到达文件末尾
@@ -100,7 +105,7 @@ func (tp *TarProcessorImpl) ExtractTar(tarPath, targetDir string, opts *ExtractO
 }
 
 // ExtractWithProgress 带进度显示的TAR解压
-func (tp *TarProcessorImpl) ExtractWithProgress(tarPath, targetDir string, opts *ExtractOptions,
+func (tp *TarProcessorImpl) ExtractWithProgress(ctx context.Context, tarPath, targetDir string, opts *ExtractOptions,
 	progressCallback func(current, total int64)) error {
 	
 	if opts == nil {
@@ -191,6 +196,10 @@ func (tp *TarProcessorImpl) ExtractWithProgress(tarPath, targetDir string, opts
 
 	// 解压所有条目
 	for {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("extraction cancelled: %w", err)
+		}
+
 		header, err := tarReader.Next()
 		if err == io.EOF {
 			break
@@ -295,6 +304,30 @@ func (tp *TarProcessorImpl) extractRegularFile(header *tar.Header, reader *tar.R
 		return fmt.Errorf("failed to read file content: %w", err)
 	}
 
+	// 检查文件是否已存在
+	if tp.sysOps.FileExists(targetPath) {
+		conflictInfo := &FileConflictInfo{
+			SourcePath: header.Name,
+			TargetPath: targetPath,
+			Exists:     true,
+			Size:       header.Size,
+			ModTime:    header.ModTime.Unix(),
+			ReadSource: func() ([]byte, error) { return fileData, nil },
+		}
+
+		finalPath, action, err := tp.handleFileConflict(conflictInfo, opts)
+		if err != nil {
+			return fmt.Errorf("failed to handle file conflict: %w", err)
+		}
+		switch action {
+		case ConflictSkip:
+			return nil
+		case ConflictMerge:
+			return mergeConflictFile(conflictInfo, opts)
+		}
+		targetPath = finalPath
+	}
+
 	// 写入文件
 	if err := tp.sysOps.WriteFile(targetPath, fileData); err != nil {
 		return fmt.Errorf("failed to write file: %w", err)
@@ -331,6 +364,8 @@ func (tp *TarProcessorImpl) handleFileConflict(conflictInfo *FileConflictInfo, o
 		case ConflictRename:
 			newPath := tp.generateUniqueFileName(conflictInfo.TargetPath)
 			return newPath, ConflictRename, nil
+		case ConflictMerge:
+			return tp.resolveMerge(conflictInfo)
 		default:
 			return conflictInfo.TargetPath, ConflictOverwrite, nil
 		}
@@ -351,6 +386,8 @@ func (tp *TarProcessorImpl) handleFileConflict(conflictInfo *FileConflictInfo, o
 			fmt.Printf("File conflict: %s already exists\n", conflictInfo.TargetPath)
 		}
 		return conflictInfo.TargetPath, ConflictOverwrite, nil
+	case "merge":
+		return tp.resolveMerge(conflictInfo)
 	default:
 		// 默认策略：如果源文件更新则覆盖，否则跳过
 		if tp.isSourceNewer(conflictInfo) {
@@ -360,6 +397,17 @@ func (tp *TarProcessorImpl) handleFileConflict(conflictInfo *FileConflictInfo, o
 	}
 }
 
+// resolveMerge 合并策略（TAR版本）：配置文件结构化合并，其他文件仅当源文件更新时覆盖
+func (tp *TarProcessorImpl) resolveMerge(conflictInfo *FileConflictInfo) (string, ConflictAction, error) {
+	if StructuredFormat(conflictInfo.TargetPath) != "" {
+		return conflictInfo.TargetPath, ConflictMerge, nil
+	}
+	if tp.isSourceNewer(conflictInfo) {
+		return conflictInfo.TargetPath, ConflictOverwrite, nil
+	}
+	return "", ConflictSkip, nil
+}
+
 // generateUniqueFileName 生成唯一文件名（TAR版本）
 func (tp *TarProcessorImpl) generateUniqueFileName(originalPath string) string {
 	dir := filepath.Dir(originalPath)
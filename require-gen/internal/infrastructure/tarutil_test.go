@@ -4,6 +4,7 @@ import (
 	"archive/tar"
 	"bytes"
 	"compress/gzip"
+	"context"
 	"io"
 	"os"
 	"path/filepath"
@@ -342,7 +343,7 @@ func TestTarProcessor_ExtractTar(t *testing.T) {
 		OverwriteExisting: true,
 	}
 
-	err := processor.ExtractTar(tarPath, targetDir, opts)
+	err := processor.ExtractTar(context.Background(), tarPath, targetDir, opts)
 	if err != nil {
 		t.Errorf("ExtractTar failed: %v", err)
 	}
@@ -369,7 +370,7 @@ func TestTarProcessor_ExtractTarCompressed(t *testing.T) {
 		OverwriteExisting: true,
 	}
 
-	err := processor.ExtractTar(tarPath, targetDir, opts)
+	err := processor.ExtractTar(context.Background(), tarPath, targetDir, opts)
 	if err != nil {
 		t.Errorf("ExtractTar compressed failed: %v", err)
 	}
@@ -402,7 +403,7 @@ func TestTarProcessor_ExtractWithProgress(t *testing.T) {
 		t.Logf("Progress: %d/%d bytes", current, total)
 	}
 
-	err := processor.ExtractWithProgress(tarPath, targetDir, opts, progressCallback)
+	err := processor.ExtractWithProgress(context.Background(), tarPath, targetDir, opts, progressCallback)
 	if err != nil {
 		t.Logf("ExtractWithProgress failed: %v", err)
 		// 对于模拟环境，我们可以接受某些错误
@@ -427,19 +428,19 @@ func TestTarProcessor_InvalidInputs(t *testing.T) {
 	opts := &ExtractOptions{}
 
 	// 测试空TAR路径
-	err := processor.ExtractTar("", "/test", opts)
+	err := processor.ExtractTar(context.Background(), "", "/test", opts)
 	if err == nil {
 		t.Error("Expected error for empty tar path")
 	}
 
 	// 测试空目标目录
-	err = processor.ExtractTar("/test.tar", "", opts)
+	err = processor.ExtractTar(context.Background(), "/test.tar", "", opts)
 	if err == nil {
 		t.Error("Expected error for empty target directory")
 	}
 
 	// 测试不存在的TAR文件
-	err = processor.ExtractTar("/nonexistent.tar", "/test", opts)
+	err = processor.ExtractTar(context.Background(), "/nonexistent.tar", "/test", opts)
 	if err == nil {
 		t.Error("Expected error for nonexistent tar file")
 	}
@@ -3,12 +3,13 @@ package infrastructure
 import (
 	"context"
 	"crypto/tls"
-	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"io/ioutil"
+	"log/slog"
 	"net/http"
+	neturl "net/url"
 	"os"
 	"path/filepath"
 	"strings"
@@ -28,6 +29,8 @@ type TemplateProvider struct {
 	clientManager *HTTPClientManager
 	errorHandler  *NetworkErrorHandler
 	retryManager  *RetryManager
+	releaseCache  *releaseCache
+	logger        *slog.Logger
 }
 
 // NewTemplateProvider 创建新的模板提供者实例
@@ -56,12 +59,14 @@ func NewTemplateProviderWithConfig(networkConfig *types.NetworkConfig, httpConfi
 
 	tp := &TemplateProvider{
 		client:        client,
-		authProvider:  NewAuthProvider(),
+		authProvider:  NewAuthProviderWithConfig(networkConfig),
 		networkConfig: networkConfig,
 		httpConfig:    httpConfig,
 		clientManager: clientManager,
 		errorHandler:  errorHandler,
 		retryManager:  retryManager,
+		releaseCache:  newReleaseCache(defaultReleaseCacheDir()),
+		logger:        DefaultLogger(),
 	}
 
 	// 应用配置
@@ -71,6 +76,43 @@ func NewTemplateProviderWithConfig(networkConfig *types.NetworkConfig, httpConfi
 	return tp
 }
 
+// SetLogger 设置日志器
+//
+// 同时替换内部HTTPClientManager和RetryManager使用的日志器。
+func (tp *TemplateProvider) SetLogger(logger *slog.Logger) {
+	if logger == nil {
+		return
+	}
+	tp.logger = logger
+	if tp.clientManager != nil {
+		tp.clientManager.SetLogger(logger)
+	}
+	if tp.retryManager != nil {
+		tp.retryManager.SetLogger(logger)
+	}
+}
+
+// SetReleaseCacheDir 设置发布信息缓存目录，dir为空时禁用缓存
+func (tp *TemplateProvider) SetReleaseCacheDir(dir string) {
+	tp.releaseCache = newReleaseCache(dir)
+}
+
+// getLogger 获取当前日志器，未设置时使用进程级默认日志器
+func (tp *TemplateProvider) getLogger() *slog.Logger {
+	if tp.logger == nil {
+		return DefaultLogger()
+	}
+	return tp.logger
+}
+
+// getErrorHandler 获取网络错误处理器，未设置时创建
+func (tp *TemplateProvider) getErrorHandler() *NetworkErrorHandler {
+	if tp.errorHandler == nil {
+		tp.errorHandler = NewNetworkErrorHandler(nil)
+	}
+	return tp.errorHandler
+}
+
 // applyHTTPConfig 应用HTTP客户端配置
 func (tp *TemplateProvider) applyHTTPConfig() {
 	if tp.httpConfig == nil {
@@ -86,8 +128,8 @@ func (tp *TemplateProvider) applyHTTPConfig() {
 	// 重定向配置
 	if !tp.httpConfig.FollowRedirects {
 		tp.client.SetRedirectPolicy(resty.NoRedirectPolicy())
-	} else if tp.httpConfig.MaxRedirects > 0 {
-		tp.client.SetRedirectPolicy(resty.FlexibleRedirectPolicy(tp.httpConfig.MaxRedirects))
+	} else {
+		tp.client.SetRedirectPolicy(resty.RedirectPolicyFunc(redirectPolicy(tp.httpConfig.MaxRedirects)))
 	}
 
 	// User-Agent
@@ -112,6 +154,7 @@ func (tp *TemplateProvider) applyHTTPConfig() {
 
 	// 传输层配置
 	transport := &http.Transport{
+		Proxy:               http.ProxyFromEnvironment,
 		MaxIdleConns:        tp.httpConfig.MaxIdleConns,
 		MaxIdleConnsPerHost: tp.httpConfig.MaxConnsPerHost,
 		IdleConnTimeout:     tp.httpConfig.IdleConnTimeout,
@@ -121,14 +164,22 @@ func (tp *TemplateProvider) applyHTTPConfig() {
 }
 
 // applyNetworkConfig 应用网络配置
+//
+// 配置错误（如证书文件不存在）只记录警告，调用方应事先使用
+// ValidateNetworkConfig校验，以便向用户报告错误。
 func (tp *TemplateProvider) applyNetworkConfig() {
 	if tp.networkConfig == nil {
 		return
 	}
 
-	// 代理配置
+	// 代理配置（显式代理仍遵循NO_PROXY）
 	if tp.networkConfig.ProxyURL != "" {
-		tp.client.SetProxy(tp.networkConfig.ProxyURL)
+		proxy, err := ProxyFunc(tp.networkConfig.ProxyURL)
+		if err != nil {
+			tp.getLogger().Warn("ignoring proxy configuration", "error", err)
+		} else if transport := tp.httpTransport(); transport != nil {
+			transport.Proxy = proxy
+		}
 	}
 
 	// 超时配置
@@ -146,54 +197,33 @@ func (tp *TemplateProvider) applyNetworkConfig() {
 
 	// TLS配置
 	if tp.networkConfig.TLS != nil {
-		tlsConfig, err := tp.buildTLSConfig(tp.networkConfig.TLS)
-		if err == nil {
+		tlsConfig, err := BuildTLSConfig(tp.networkConfig.TLS)
+		if err != nil {
+			tp.getLogger().Warn("ignoring TLS configuration", "error", err)
+		} else {
 			tp.client.SetTLSClientConfig(tlsConfig)
 		}
 	}
 }
 
-// buildTLSConfig 构建TLS配置
-func (tp *TemplateProvider) buildTLSConfig(tlsConf *types.TLSConfig) (*tls.Config, error) {
-	config := &tls.Config{
-		InsecureSkipVerify: tlsConf.InsecureSkipVerify,
-		ServerName:         tlsConf.ServerName,
-	}
-
-	// 加载客户端证书
-	if tlsConf.CertFile != "" && tlsConf.KeyFile != "" {
-		cert, err := tls.LoadX509KeyPair(tlsConf.CertFile, tlsConf.KeyFile)
-		if err != nil {
-			return nil, fmt.Errorf("failed to load client certificate: %w", err)
-		}
-		config.Certificates = []tls.Certificate{cert}
-	}
-
-	// 加载CA证书
-	if tlsConf.CAFile != "" {
-		caCert, err := ioutil.ReadFile(tlsConf.CAFile)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read CA certificate: %w", err)
-		}
-
-		caCertPool := x509.NewCertPool()
-		if !caCertPool.AppendCertsFromPEM(caCert) {
-			return nil, fmt.Errorf("failed to parse CA certificate")
-		}
-		config.RootCAs = caCertPool
-	}
-
-	return config, nil
+// httpTransport 获取客户端使用的http.Transport，非标准传输层时返回nil
+func (tp *TemplateProvider) httpTransport() *http.Transport {
+	transport, _ := tp.client.GetClient().Transport.(*http.Transport)
+	return transport
 }
 
 // Download 下载模板
-func (tp *TemplateProvider) Download(opts types.DownloadOptions) (string, error) {
-	// 根据SkipTLS标志动态配置TLS设置
+//
+// ctx贯穿发布信息查询、资源下载和解压全过程，取消后尽快返回ctx.Err()，
+// 已下载的部分文件由调用方负责清理（init命令在暂存目录中完成下载）。
+func (tp *TemplateProvider) Download(ctx context.Context, opts types.DownloadOptions) (string, error) {
+	// 根据SkipTLS标志动态配置TLS设置（保留已配置的客户端证书）
 	if opts.SkipTLS {
-		// 创建跳过TLS验证的配置
-		tlsConfig := &tls.Config{
-			InsecureSkipVerify: true,
+		tlsConfig := &tls.Config{}
+		if transport := tp.httpTransport(); transport != nil && transport.TLSClientConfig != nil {
+			tlsConfig = transport.TLSClientConfig.Clone()
 		}
+		tlsConfig.InsecureSkipVerify = true
 		tp.client.SetTLSClientConfig(tlsConfig)
 	}
 
@@ -203,8 +233,19 @@ func (tp *TemplateProvider) Download(opts types.DownloadOptions) (string, error)
 		return "", fmt.Errorf("failed to create target directory: %w", err)
 	}
 
+	tp.getLogger().Info("downloading template",
+		"assistant", opts.AIAssistant, "script_type", opts.ScriptType, "dir", targetDir)
+	// 此后opts.GitHubToken为发送给模板源的令牌
+	opts.GitHubToken = tp.sourceToken(opts.TemplateRepo, opts)
+
+	// git仓库模板源优先于发布源
+	if opts.TemplateGit != "" {
+		return tp.downloadFromGit(ctx, targetDir, opts)
+	}
+
 	// 获取最新发布信息
-	release, err := tp.getLatestRelease(opts.GitHubToken)
+	release, token, err := tp.fetchRelease(ctx, opts.TemplateRepo, opts.GitHubToken)
+	opts.GitHubToken = token
 	if err != nil {
 		return "", fmt.Errorf("failed to get latest release: %w", err)
 	}
@@ -215,17 +256,39 @@ func (tp *TemplateProvider) Download(opts types.DownloadOptions) (string, error)
 		return "", fmt.Errorf("failed to find suitable asset: %w", err)
 	}
 
+	tp.getLogger().Info("release resolved",
+		"tag", release.TagName, "asset", asset.Name, "asset_id", asset.ID,
+		"content_type", asset.ContentType, "size", asset.Size)
+
+	if opts.OnResolved != nil {
+		opts.OnResolved(release, asset)
+	}
+
+	// 确定预期校验和，下载器在写入完成后据此校验
+	if err := tp.resolveChecksum(ctx, release, asset, &opts); err != nil {
+		return "", fmt.Errorf("failed to verify release: %w", err)
+	}
+
 	// 下载资源
 	downloadPath := filepath.Join(targetDir, asset.Name)
-	if err := tp.downloadAsset(asset, downloadPath, opts); err != nil {
+	// 所有下载来源（包括镜像重试）共享同一个限速器
+	limiter := NewBandwidthLimiter(opts.LimitRate)
+	if err := tp.downloadAsset(ctx, asset, downloadPath, opts, limiter); err != nil {
+		// 删除未完成的下载文件（启用断点续传时保留以便下次继续）
+		if !opts.EnableResume {
+			os.Remove(downloadPath)
+			tp.getLogger().Debug("removed partial download", "path", downloadPath)
+		}
 		return "", fmt.Errorf("failed to download asset: %w", err)
 	}
 
 	// 提取文件（如果是压缩包）
-	if err := tp.extractAsset(downloadPath, targetDir, opts); err != nil {
+	if err := tp.extractAsset(ctx, downloadPath, targetDir, opts); err != nil {
 		return "", fmt.Errorf("failed to extract asset: %w", err)
 	}
 
+	tp.getLogger().Info("template downloaded", "asset", asset.Name, "dir", targetDir)
+
 	return targetDir, nil
 }
 
@@ -264,34 +327,199 @@ func (tp *TemplateProvider) Validate(path string) error {
 }
 
 // getLatestRelease 获取最新发布信息
-func (tp *TemplateProvider) getLatestRelease(token string) (*types.GitHubRelease, error) {
-	url := "https://api.github.com/repos/github/spec-kit/releases/latest"
+//
+// source为模板源（格式见TemplateSource），空表示默认的模板仓库。
+// 速率受限或API不可达时使用缓存的发布信息，资源随后可以从镜像下载。
+func (tp *TemplateProvider) getLatestRelease(ctx context.Context, source, token string) (*types.GitHubRelease, error) {
+	src, err := tp.templateSource(source)
+	if err != nil {
+		return nil, err
+	}
 
-	req := tp.client.R()
+	tp.getLogger().Debug("fetching latest release", "source", src.String(), "authenticated", token != "")
 
-	// 添加认证头
-	if token != "" {
-		req.SetHeader("Authorization", fmt.Sprintf("token %s", token))
+	// 已缓存的发布信息带有ETag/Last-Modified时发送条件请求，
+	// 未变化时服务器返回304，不消耗GitHub的API配额
+	cacheKey := tp.apiBaseURL() + " " + source
+	entry, _ := tp.releaseCache.Load(cacheKey)
+	validators := &httpValidators{}
+	if entry != nil {
+		*validators = entry.httpValidators
 	}
 
-	// 设置User-Agent
-	req.SetHeader("User-Agent", "Specify-CLI/1.0.0")
+	release, err := src.LatestRelease(ctx, tp.client, token, validators)
+	if errors.Is(err, errNotModified) && entry != nil {
+		tp.getLogger().Debug("release not modified, using cached release information", "source", src.String(), "tag", entry.Release.TagName)
+		entry.FetchedAt = time.Now()
+		if err := tp.releaseCache.Store(entry); err != nil {
+			tp.getLogger().Debug("failed to cache release", "error", err)
+		}
+		return entry.Release, nil
+	}
+	if rlErr, ok := asRateLimitError(err); ok {
+		var cached bool
+		release, cached, err = tp.handleRateLimit(ctx, src, cacheKey, token, rlErr, validators)
+		if err == nil && cached {
+			return release, nil
+		}
+	}
+	if err != nil && entry != nil && ctx.Err() == nil && isUnreachable(err) {
+		// API不可达（例如网络屏蔽了GitHub）时使用缓存的发布信息，资源仍可从镜像下载
+		tp.getLogger().Warn("release API unreachable, using cached release information",
+			"source", src.String(), "tag", entry.Release.TagName, "fetched_at", entry.FetchedAt.Format(time.RFC3339), "error", err)
+		return entry.Release, nil
+	}
+	if err != nil {
+		tp.getLogger().Warn("failed to fetch release", "source", src.String(), "error", err)
+		return nil, err
+	}
 
-	resp, err := req.Get(url)
+	if err := tp.releaseCache.Store(&cachedRelease{
+		Source:         cacheKey,
+		Release:        release,
+		FetchedAt:      time.Now(),
+		httpValidators: *validators,
+	}); err != nil {
+		tp.getLogger().Debug("failed to cache release", "error", err)
+	}
+	return release, nil
+}
+
+// handleRateLimit 处理发布信息请求的速率限制
+//
+// 配额在maxRateLimitWait内重置时等待后重试一次；否则使用缓存的发布信息
+// （cached为true）。两者都不可行时返回带有GH_TOKEN提示的RateLimitError。
+// 重试时不发送条件请求，validators更新为重试响应的校验值。
+func (tp *TemplateProvider) handleRateLimit(ctx context.Context, src TemplateSource, cacheKey, token string, rlErr *RateLimitError, validators *httpValidators) (release *types.GitHubRelease, cached bool, err error) {
+	if wait := rlErr.WaitTime(); wait > 0 && wait <= maxRateLimitWait {
+		tp.getLogger().Warn("API rate limit reached, waiting for reset", "source", src.String(), "wait", wait.Round(time.Second))
+		select {
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		case <-time.After(wait):
+		}
+
+		*validators = httpValidators{}
+		release, err := src.LatestRelease(ctx, tp.client, token, validators)
+		if err == nil {
+			return release, false, nil
+		}
+		if rlErr, _ = asRateLimitError(err); rlErr == nil {
+			return nil, false, err
+		}
+	}
+
+	entry, cacheErr := tp.releaseCache.Load(cacheKey)
+	if cacheErr != nil {
+		return nil, false, rlErr
+	}
+	tp.getLogger().Warn("API rate limit reached, using cached release information",
+		"source", src.String(), "tag", entry.Release.TagName, "fetched_at", entry.FetchedAt.Format(time.RFC3339))
+	return entry.Release, true, nil
+}
+
+// credentialProvider 区分显式令牌和外部凭据来源的认证提供者
+type credentialProvider interface {
+	ExplicitToken() string
+	CredentialToken() string
+}
+
+// isUnreachable 判断请求是否因为网络原因失败（连接失败、DNS解析失败、超时或服务器错误）
+func isUnreachable(err error) bool {
+	var urlErr *neturl.Error
+	if errors.As(err, &urlErr) {
+		return true
+	}
+	var statusErr *ReleaseStatusError
+	return errors.As(err, &statusErr) && statusErr.StatusCode >= http.StatusInternalServerError
+}
+
+// sourceToken 确定访问模板源使用的令牌
+//
+// GitHub令牌（--token或SPECIFY_TOKEN）只发送给GitHub源，未指定时使用GH_TOKEN或
+// GITHUB_TOKEN环境变量（认证请求的速率限制为每小时5000次，匿名请求为60次）。
+// GitLab和Gitea源只使用各自平台的令牌（SPECIFY_GITLAB_TOKEN、SPECIFY_GITEA_TOKEN），
+// 更换模板源不会把GitHub令牌泄露给其他主机；静态索引不使用令牌。
+// 外部凭据来源（密钥环、gh、git凭据助手、netrc）不在这里查询，见fetchRelease。
+func (tp *TemplateProvider) sourceToken(source string, opts types.DownloadOptions) string {
+	src, err := tp.templateSource(source)
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch release info: %w", err)
+		return ""
+	}
+	switch src.(type) {
+	case *githubSource:
+	case *gitlabSource:
+		return opts.GitLabToken
+	case *giteaSource:
+		return opts.GiteaToken
+	default:
+		return ""
 	}
 
-	if resp.StatusCode() != 200 {
-		return nil, fmt.Errorf("GitHub API returned status %d: %s", resp.StatusCode(), resp.String())
+	if opts.GitHubToken != "" {
+		return opts.GitHubToken
 	}
+	if cp, ok := tp.authProvider.(credentialProvider); ok {
+		return cp.ExplicitToken()
+	}
+	return tp.authProvider.GetToken()
+}
+
+// fetchRelease 获取最新发布信息，同时返回后续下载资源使用的令牌
+//
+// 没有显式令牌的GitHub源先匿名请求，返回401、404或速率限制时
+// 再使用外部凭据来源中的令牌重试。公开模板因此不会读取密钥环或启动gh、git，
+// 也不会因为过期或无关的凭据而失败。
+func (tp *TemplateProvider) fetchRelease(ctx context.Context, source, token string) (*types.GitHubRelease, string, error) {
+	release, err := tp.getLatestRelease(ctx, source, token)
+	if err == nil || token != "" || !needsCredential(err) || !tp.isGitHubSource(source) {
+		return release, token, err
+	}
+
+	cp, ok := tp.authProvider.(credentialProvider)
+	if !ok {
+		return nil, token, err
+	}
+	credential := cp.CredentialToken()
+	if credential == "" {
+		return nil, token, err
+	}
+
+	tp.getLogger().Info("anonymous release request failed, retrying with stored credentials", "error", err)
+	release, retryErr := tp.getLatestRelease(ctx, source, credential)
+	if retryErr != nil {
+		return nil, token, retryErr
+	}
+	return release, credential, nil
+}
 
-	var release types.GitHubRelease
-	if err := json.Unmarshal(resp.Body(), &release); err != nil {
-		return nil, fmt.Errorf("failed to parse release info: %w", err)
+// needsCredential 判断匿名请求的失败是否可能通过认证解决
+//
+// 私有仓库对匿名请求返回404，速率限制时认证请求的配额更高。
+func needsCredential(err error) bool {
+	if _, ok := asRateLimitError(err); ok {
+		return true
+	}
+	var statusErr *ReleaseStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusUnauthorized || statusErr.StatusCode == http.StatusNotFound
 	}
+	return false
+}
 
-	return &release, nil
+// isGitHubSource 判断模板源是否为GitHub Releases
+func (tp *TemplateProvider) isGitHubSource(source string) bool {
+	src, err := tp.templateSource(source)
+	if err != nil {
+		return false
+	}
+	_, ok := src.(*githubSource)
+	return ok
+}
+
+// templateSource 解析模板源，GitHub源使用配置的API地址
+func (tp *TemplateProvider) templateSource(source string) (TemplateSource, error) {
+	return ParseTemplateSource(source, tp.apiBaseURL())
 }
 
 // findAsset 查找合适的资源
@@ -322,63 +550,182 @@ func (tp *TemplateProvider) findAsset(release *types.GitHubRelease, assistant, s
 }
 
 // downloadAsset 下载资源
-func (tp *TemplateProvider) downloadAsset(asset *types.Asset, downloadPath string, opts types.DownloadOptions) error {
+//
+// limiter为下载共享的限速器，nil表示不限速。
+func (tp *TemplateProvider) downloadAsset(ctx context.Context, asset *types.Asset, downloadPath string, opts types.DownloadOptions, limiter *BandwidthLimiter) error {
 	if opts.Verbose {
 		ui.ShowInfo(fmt.Sprintf("Downloading %s (%d bytes)", asset.Name, asset.Size))
 	}
 
-	// 使用增强的下载方法
-	return tp.downloadWithEnhancedProgress(asset.BrowserDownloadURL, downloadPath, asset.Size, opts)
+	src, err := tp.templateSource(opts.TemplateRepo)
+	if err != nil {
+		return err
+	}
+	url, headers := src.AssetRequest(asset, opts.GitHubToken)
+	tp.getLogger().Debug("downloading asset", "url", url, "authenticated", headers != nil)
+
+	// 静态索引中的本地资源直接复制
+	if strings.HasPrefix(url, "file://") {
+		if err := tp.copyLocalAsset(ctx, url, downloadPath); err != nil {
+			return err
+		}
+		if opts.VerifyChecksum && opts.Checksum != "" {
+			return verifyFileChecksum(downloadPath, opts.Checksum)
+		}
+		return nil
+	}
+
+	// 使用增强的下载方法，原始地址失败时依次尝试配置的镜像
+	sources := downloadSources(url, headers, asset, opts.Mirrors)
+	return tp.downloadWithEnhancedProgress(ctx, sources, downloadPath, asset.Size, opts, limiter)
+}
+
+// copyLocalAsset 复制file://地址指向的本地资源
+func (tp *TemplateProvider) copyLocalAsset(ctx context.Context, fileURL, downloadPath string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	u, err := neturl.Parse(fileURL)
+	if err != nil {
+		return fmt.Errorf("invalid asset URL: %w", err)
+	}
+
+	src, err := os.Open(fileURLPath(u))
+	if err != nil {
+		return fmt.Errorf("failed to open local asset: %w", err)
+	}
+	defer src.Close()
+
+	dst, err := os.Create(downloadPath)
+	if err != nil {
+		return fmt.Errorf("failed to create file: %w", err)
+	}
+	defer dst.Close()
+
+	if _, err := io.Copy(dst, src); err != nil {
+		return fmt.Errorf("failed to copy local asset: %w", err)
+	}
+	return nil
+}
+
+// apiBaseURL 获取GitHub API基础URL
+func (tp *TemplateProvider) apiBaseURL() string {
+	return apiBaseURL(tp.networkConfig)
 }
 
 // downloadWithEnhancedProgress 增强的带进度下载方法
-func (tp *TemplateProvider) downloadWithEnhancedProgress(url, filePath string, size int64, opts types.DownloadOptions) error {
+//
+// 按顺序尝试sources中的下载来源，直到有一个成功。每个来源的失败交给错误处理器
+// 记录，并计入该主机的熔断器；熔断器已打开的主机（如不可用的镜像）直接跳过。
+func (tp *TemplateProvider) downloadWithEnhancedProgress(ctx context.Context, sources []downloadSource, filePath string, size int64, opts types.DownloadOptions, limiter *BandwidthLimiter) error {
+	errorHandler := tp.getErrorHandler()
+	var lastErr error
+	for i, src := range sources {
+		host := extractHost(src.URL)
+		if !errorHandler.AllowRequest(host) {
+			tp.getLogger().Info("skipping download source, circuit breaker is open", "host", host)
+			lastErr = fmt.Errorf("%s is unavailable (too many recent failures)", host)
+			continue
+		}
+
+		err := tp.downloadFromSource(ctx, src.URL, src.Headers, filePath, size, opts, limiter)
+		if err == nil {
+			errorHandler.RecordSuccess(host)
+			if src.Mirror {
+				tp.getLogger().Info("downloaded from mirror", "url", src.URL)
+			}
+			return nil
+		}
+		if ctx.Err() != nil {
+			return err
+		}
+
+		networkErr := errorHandler.HandleError(ctx, err, host)
+		lastErr = err
+		if i < len(sources)-1 {
+			tp.getLogger().Warn("download failed, trying next mirror", "host", host,
+				"error_type", networkErr.Type.String(), "error", err)
+			// 不同来源的文件不能拼接，切换来源前丢弃部分下载
+			discardPartialDownload(filePath)
+			os.Remove(filePath)
+		}
+	}
+
+	if len(sources) > 1 {
+		return fmt.Errorf("all %d download sources failed, last error: %w", len(sources), lastErr)
+	}
+	return lastErr
+}
+
+// downloadFromSource 从一个下载来源下载文件
+//
+// headers为每个下载请求附加的HTTP头（例如资源API所需的认证和Accept头）。
+// limiter为所有下载来源共享的限速器，nil时下载器按opts.LimitRate单独限速。
+func (tp *TemplateProvider) downloadFromSource(ctx context.Context, url string, headers map[string]string, filePath string, size int64, opts types.DownloadOptions, limiter *BandwidthLimiter) error {
+	if useRangedDownload(size, opts) {
+		tp.getLogger().Debug("using ranged downloader", "url", url, "size", size, "resume", opts.EnableResume)
+		return tp.downloadWithErrorHandling(ctx, url, headers, filePath, size, opts, limiter)
+	}
+
 	// 如果配置了流式下载，使用流式下载器
-	if opts.ChunkSize > 0 || opts.EnableResume || opts.MaxConcurrent > 1 {
+	if opts.ChunkSize > 0 || opts.MaxConcurrent > 1 {
 		// 创建HTTP客户端
 		client := &http.Client{
-			Timeout: tp.getTimeout(),
+			Timeout:       tp.getTimeout(),
+			CheckRedirect: redirectPolicy(tp.getMaxRedirects()),
 		}
 
-		// 应用TLS配置
-		var tlsConfig *tls.Config
+		// 应用网络配置中的代理和TLS设置
+		transport, err := newNetworkTransport(tp.networkConfig)
+		if err != nil {
+			return fmt.Errorf("failed to configure network: %w", err)
+		}
 		if opts.SkipTLS {
 			// 如果设置了SkipTLS标志，跳过TLS验证
-			tlsConfig = &tls.Config{
-				InsecureSkipVerify: true,
-			}
-		} else if tp.networkConfig != nil && tp.networkConfig.TLS != nil {
-			// 否则使用网络配置中的TLS设置
-			var err error
-			tlsConfig, err = tp.buildTLSConfig(tp.networkConfig.TLS)
-			if err != nil {
-				return fmt.Errorf("failed to build TLS config: %w", err)
+			if transport.TLSClientConfig == nil {
+				transport.TLSClientConfig = &tls.Config{}
 			}
+			transport.TLSClientConfig.InsecureSkipVerify = true
 		}
+		// 与resty客户端一样记录--debug请求跟踪和脱敏的请求头
+		client.Transport = newLoggingTransport(transport, tp.getLogger)
 
-		if tlsConfig != nil {
-			transport := &http.Transport{
-				TLSClientConfig: tlsConfig,
-			}
-			client.Transport = transport
-		}
+		tp.getLogger().Debug("using streaming downloader", "url", url, "max_concurrent", opts.MaxConcurrent)
 
 		// 使用流式下载器
 		downloader := NewStreamingDownloader(client, 1024*1024) // 1MB
-		return downloader.DownloadWithStreaming(url, filePath, &opts)
+		downloader.SetHeaders(headers)
+		downloader.SetBandwidthLimiter(limiter)
+		return downloader.DownloadWithStreaming(ctx, url, filePath, &opts)
 	}
 
+	tp.getLogger().Debug("using enhanced downloader", "url", url, "size", size)
+
 	// 使用增强的下载方法，集成错误处理
-	return tp.downloadWithErrorHandling(url, filePath, size, opts)
+	return tp.downloadWithErrorHandling(ctx, url, headers, filePath, size, opts, limiter)
 }
 
 // downloadWithErrorHandling 使用错误处理的下载
-func (tp *TemplateProvider) downloadWithErrorHandling(url, dest string, size int64, opts types.DownloadOptions) error {
+func (tp *TemplateProvider) downloadWithErrorHandling(ctx context.Context, url string, headers map[string]string, dest string, size int64, opts types.DownloadOptions, limiter *BandwidthLimiter) error {
 	// 创建增强下载器
 	downloader := NewEnhancedDownloader(tp.client, tp.errorHandler, tp.retryManager)
+	downloader.SetHeaders(headers)
+	downloader.SetBandwidthLimiter(limiter)
 	
 	// 执行下载
-	return downloader.Download(context.Background(), url, dest, &opts)
+	if useRangedDownload(size, opts) {
+		return downloader.DownloadRanged(ctx, url, dest, &opts)
+	}
+	return downloader.Download(ctx, url, dest, &opts)
+}
+
+// useRangedDownload 判断是否使用分段下载（中断后再次下载从<dest>.part继续）
+//
+// 启用断点续传时任意大小的文件都通过<dest>.part.json记录进度，并用If-Range校验远程文件；
+// 否则只有不小于minRangedDownloadSize的大文件分段并发下载，MaxConcurrent为1时禁用。
+func useRangedDownload(size int64, opts types.DownloadOptions) bool {
+	return opts.EnableResume || (size >= minRangedDownloadSize && opts.MaxConcurrent != 1)
 }
 
 // getTimeout 获取超时时间
@@ -392,6 +739,14 @@ func (tp *TemplateProvider) getTimeout() time.Duration {
 	return 30 * time.Second // 默认值
 }
 
+// getMaxRedirects 获取最大重定向次数，0表示使用默认值
+func (tp *TemplateProvider) getMaxRedirects() int {
+	if tp.httpConfig != nil {
+		return tp.httpConfig.MaxRedirects
+	}
+	return 0
+}
+
 // getRetryCount 获取重试次数
 func (tp *TemplateProvider) getRetryCount() int {
 	if tp.networkConfig != nil && tp.networkConfig.RetryCount > 0 {
@@ -423,15 +778,16 @@ func (tp *TemplateProvider) getMaxRetryWaitTime() time.Duration {
 }
 
 // extractAsset 提取资源
-func (tp *TemplateProvider) extractAsset(assetPath, targetDir string, opts types.DownloadOptions) error {
+func (tp *TemplateProvider) extractAsset(ctx context.Context, assetPath, targetDir string, opts types.DownloadOptions) error {
 	// 检查文件扩展名
 	ext := strings.ToLower(filepath.Ext(assetPath))
+	tp.getLogger().Debug("extracting asset", "path", assetPath, "format", ext, "dir", targetDir)
 
 	switch ext {
 	case ".zip":
-		return tp.extractZip(assetPath, targetDir, opts)
+		return tp.extractZip(ctx, assetPath, targetDir, opts)
 	case ".tar", ".gz":
-		return tp.extractTar(assetPath, targetDir, opts)
+		return tp.extractTar(ctx, assetPath, targetDir, opts)
 	default:
 		// 不是压缩文件，直接返回
 		return nil
@@ -439,7 +795,7 @@ func (tp *TemplateProvider) extractAsset(assetPath, targetDir string, opts types
 }
 
 // extractZip 提取ZIP文件
-func (tp *TemplateProvider) extractZip(zipPath, targetDir string, opts types.DownloadOptions) error {
+func (tp *TemplateProvider) extractZip(ctx context.Context, zipPath, targetDir string, opts types.DownloadOptions) error {
 	if opts.Verbose {
 		ui.ShowInfo(fmt.Sprintf("Extracting %s", filepath.Base(zipPath)))
 	}
@@ -460,6 +816,7 @@ func (tp *TemplateProvider) extractZip(zipPath, targetDir string, opts types.Dow
 		SkipHidden:          false,
 		Verbose:             opts.Verbose,
 	}
+	applyConflictPolicy(extractOpts, opts.OnConflict, opts.Verbose)
 
 	// 执行ZIP提取
 	var err error
@@ -473,10 +830,10 @@ func (tp *TemplateProvider) extractZip(zipPath, targetDir string, opts types.Dow
 			}
 		}
 
-		err = zipProcessor.ExtractWithProgress(zipPath, targetDir, extractOpts, progressCallback)
+		err = zipProcessor.ExtractWithProgress(ctx, zipPath, targetDir, extractOpts, progressCallback)
 	} else {
 		// 使用普通提取
-		err = zipProcessor.ExtractZip(zipPath, targetDir, extractOpts)
+		err = zipProcessor.ExtractZip(ctx, zipPath, targetDir, extractOpts)
 	}
 
 	if err != nil {
@@ -561,7 +918,7 @@ func (tp *TemplateProvider) handleNestedDirectories(targetDir string, opts types
 }
 
 // extractTar 提取TAR文件
-func (tp *TemplateProvider) extractTar(tarPath, targetDir string, opts types.DownloadOptions) error {
+func (tp *TemplateProvider) extractTar(ctx context.Context, tarPath, targetDir string, opts types.DownloadOptions) error {
 	if opts.Verbose {
 		ui.ShowInfo(fmt.Sprintf("Extracting %s", filepath.Base(tarPath)))
 	}
@@ -578,6 +935,7 @@ func (tp *TemplateProvider) extractTar(tarPath, targetDir string, opts types.Dow
 		SkipHidden:          true,
 		MaxFileSize:         100 * 1024 * 1024, // 100MB 限制
 	}
+	applyConflictPolicy(extractOpts, opts.OnConflict, opts.Verbose)
 
 	// 进度回调函数
 	progressCallback := func(current, total int64) {
@@ -590,13 +948,13 @@ func (tp *TemplateProvider) extractTar(tarPath, targetDir string, opts types.Dow
 	var err error
 	if opts.Verbose {
 		// 带进度显示的解压
-		err = tarProcessor.ExtractWithProgress(tarPath, targetDir, extractOpts, progressCallback)
+		err = tarProcessor.ExtractWithProgress(ctx, tarPath, targetDir, extractOpts, progressCallback)
 		if opts.Verbose && err == nil {
 			fmt.Println() // 换行
 		}
 	} else {
 		// 静默解压
-		err = tarProcessor.ExtractTar(tarPath, targetDir, extractOpts)
+		err = tarProcessor.ExtractTar(ctx, tarPath, targetDir, extractOpts)
 	}
 
 	if err != nil {
@@ -651,7 +1009,7 @@ func (tp *TemplateProvider) GetTemplateInfo(path string) (map[string]interface{}
 
 // ListTemplates 列出可用模板
 func (tp *TemplateProvider) ListTemplates(token string) ([]string, error) {
-	release, err := tp.getLatestRelease(token)
+	release, _, err := tp.fetchRelease(context.Background(), "", tp.sourceToken("", types.DownloadOptions{GitHubToken: token}))
 	if err != nil {
 		return nil, err
 	}
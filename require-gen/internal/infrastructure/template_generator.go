@@ -0,0 +1,235 @@
+package infrastructure
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// commandFormat 描述某个AI助手的命令文件格式
+type commandFormat struct {
+	dir  string // 相对项目根目录的命令目录
+	ext  string // 文件扩展名
+	args string // 参数占位符
+	toml bool   // 是否输出为TOML格式
+}
+
+// commandFormats 各AI助手的命令文件格式（与spec-kit发布包的打包脚本一致）
+var commandFormats = map[string]commandFormat{
+	"claude":       {dir: ".claude/commands", ext: ".md", args: "$ARGUMENTS"},
+	"gemini":       {dir: ".gemini/commands", ext: ".toml", args: "{{args}}", toml: true},
+	"copilot":      {dir: ".github/prompts", ext: ".prompt.md", args: "$ARGUMENTS"},
+	"cursor-agent": {dir: ".cursor/commands", ext: ".md", args: "$ARGUMENTS"},
+	"qwen":         {dir: ".qwen/commands", ext: ".toml", args: "{{args}}", toml: true},
+	"opencode":     {dir: ".opencode/command", ext: ".md", args: "$ARGUMENTS"},
+	"codex":        {dir: ".codex/prompts", ext: ".md", args: "$ARGUMENTS"},
+	"windsurf":     {dir: ".windsurf/workflows", ext: ".md", args: "$ARGUMENTS"},
+	"kilocode":     {dir: ".kilocode/workflows", ext: ".md", args: "$ARGUMENTS"},
+	"auggie":       {dir: ".augment/commands", ext: ".md", args: "$ARGUMENTS"},
+	"codebuddy":    {dir: ".codebuddy/commands", ext: ".md", args: "$ARGUMENTS"},
+	"roo":          {dir: ".roo/commands", ext: ".md", args: "$ARGUMENTS"},
+	"q":            {dir: ".amazonq/prompts", ext: ".md", args: "$ARGUMENTS"},
+}
+
+// scriptVariants 脚本类型对应的源码目录和frontmatter键
+var scriptVariants = map[string]struct{ dir, key string }{
+	"sh": {dir: "bash", key: "sh"},
+	"ps": {dir: "powershell", key: "ps"},
+}
+
+// repoPathPattern 匹配命令模板中指向仓库根目录的路径引用
+var repoPathPattern = regexp.MustCompile(`(^|[\s"'(\x60])/?(memory|scripts|templates)/`)
+
+// GenerateTemplate 将spec-kit源码仓库转换为与发布包相同的模板结构
+//
+// 源码中已经存在.specify目录时视为打包好的模板，按原样复制（跳过.git）。
+// 否则按发布包打包脚本的规则生成：memory、scripts/<类型>和templates（不含commands）
+// 复制到.specify下，templates/commands中的命令模板渲染为指定AI助手的命令文件。
+func GenerateTemplate(srcDir, destDir, assistant, scriptType string) error {
+	if _, err := os.Stat(filepath.Join(srcDir, ".specify")); err == nil {
+		return copyDirExcluding(srcDir, destDir, ".git")
+	}
+
+	format, ok := commandFormats[assistant]
+	if !ok {
+		return fmt.Errorf("unsupported AI assistant for template generation: %s", assistant)
+	}
+	if scriptType == "" {
+		// 与发布包选择一致：Windows默认PowerShell，其他平台默认bash
+		scriptType = "sh"
+		if runtime.GOOS == "windows" {
+			scriptType = "ps"
+		}
+	}
+	variant, ok := scriptVariants[scriptType]
+	if !ok {
+		return fmt.Errorf("unsupported script type for template generation: %s", scriptType)
+	}
+
+	specifyDir := filepath.Join(destDir, ".specify")
+	copies := []struct{ src, dest string }{
+		{filepath.Join(srcDir, "memory"), filepath.Join(specifyDir, "memory")},
+		{filepath.Join(srcDir, "scripts", variant.dir), filepath.Join(specifyDir, "scripts", variant.dir)},
+		{filepath.Join(srcDir, "templates"), filepath.Join(specifyDir, "templates")},
+	}
+	for _, c := range copies {
+		if _, err := os.Stat(c.src); os.IsNotExist(err) {
+			continue
+		}
+		if err := copyDirExcluding(c.src, c.dest, ".git", "commands"); err != nil {
+			return err
+		}
+	}
+
+	commandsDir := filepath.Join(srcDir, "templates", "commands")
+	entries, err := os.ReadDir(commandsDir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read command templates: %w", err)
+	}
+
+	outDir := filepath.Join(destDir, filepath.FromSlash(format.dir))
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return fmt.Errorf("failed to create command directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != ".md" {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(commandsDir, entry.Name()))
+		if err != nil {
+			return fmt.Errorf("failed to read command template %s: %w", entry.Name(), err)
+		}
+
+		rendered, err := renderCommand(content, assistant, variant.key, format)
+		if err != nil {
+			return fmt.Errorf("failed to render command template %s: %w", entry.Name(), err)
+		}
+
+		name := "speckit." + strings.TrimSuffix(entry.Name(), ".md") + format.ext
+		if err := os.WriteFile(filepath.Join(outDir, name), rendered, 0644); err != nil {
+			return fmt.Errorf("failed to write command file %s: %w", name, err)
+		}
+	}
+
+	return nil
+}
+
+// renderCommand 渲染单个命令模板
+func renderCommand(content []byte, assistant, scriptKey string, format commandFormat) ([]byte, error) {
+	frontmatter, body := splitFrontmatter(string(content))
+
+	var meta struct {
+		Description  string            `yaml:"description"`
+		Scripts      map[string]string `yaml:"scripts"`
+		AgentScripts map[string]string `yaml:"agent_scripts"`
+	}
+	if frontmatter != "" {
+		if err := yaml.Unmarshal([]byte(frontmatter), &meta); err != nil {
+			return nil, fmt.Errorf("invalid frontmatter: %w", err)
+		}
+	}
+
+	replacer := strings.NewReplacer(
+		"{SCRIPT}", meta.Scripts[scriptKey],
+		"{AGENT_SCRIPT}", meta.AgentScripts[scriptKey],
+		"{ARGS}", format.args,
+		"$ARGUMENTS", format.args,
+		"__AGENT__", assistant,
+	)
+	body = rewriteRepoPaths(replacer.Replace(body))
+
+	if format.toml {
+		var buf bytes.Buffer
+		fmt.Fprintf(&buf, "description = %q\n\nprompt = \"\"\"\n%s\n\"\"\"\n", meta.Description, strings.TrimRight(body, "\n"))
+		return buf.Bytes(), nil
+	}
+
+	frontmatter = stripFrontmatterKeys(frontmatter, "scripts", "agent_scripts")
+	if strings.TrimSpace(frontmatter) == "" {
+		return []byte(body), nil
+	}
+	return []byte("---\n" + frontmatter + "---\n" + body), nil
+}
+
+// splitFrontmatter 拆分YAML frontmatter和正文
+func splitFrontmatter(content string) (string, string) {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+	if !strings.HasPrefix(content, "---\n") {
+		return "", content
+	}
+	rest := content[len("---\n"):]
+	end := strings.Index(rest, "\n---\n")
+	if end < 0 {
+		return "", content
+	}
+	return rest[:end+1], rest[end+len("\n---\n"):]
+}
+
+// stripFrontmatterKeys 删除frontmatter中的顶级键及其缩进的子项
+func stripFrontmatterKeys(frontmatter string, keys ...string) string {
+	var out []string
+	skipping := false
+	for _, line := range strings.Split(frontmatter, "\n") {
+		indented := strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")
+		if skipping && (indented || line == "") {
+			continue
+		}
+		skipping = false
+		for _, key := range keys {
+			if strings.HasPrefix(line, key+":") {
+				skipping = true
+				break
+			}
+		}
+		if !skipping {
+			out = append(out, line)
+		}
+	}
+	result := strings.Join(out, "\n")
+	if result != "" && !strings.HasSuffix(result, "\n") {
+		result += "\n"
+	}
+	return result
+}
+
+// rewriteRepoPaths 将仓库根目录下的memory/、scripts/和templates/引用改写到.specify下
+func rewriteRepoPaths(body string) string {
+	return repoPathPattern.ReplaceAllString(body, "${1}.specify/${2}/")
+}
+
+// copyDirExcluding 递归复制目录，跳过指定名称的子目录
+func copyDirExcluding(src, dest string, exclude ...string) error {
+	sysOps := NewSystemOperations()
+	sort.Strings(exclude)
+
+	return filepath.WalkDir(src, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && path != src {
+			if i := sort.SearchStrings(exclude, d.Name()); i < len(exclude) && exclude[i] == d.Name() {
+				return filepath.SkipDir
+			}
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dest, rel)
+		if d.IsDir() {
+			return os.MkdirAll(target, 0755)
+		}
+		return sysOps.CopyFile(path, target)
+	})
+}
@@ -0,0 +1,137 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"specify-cli/internal/types"
+	"specify-cli/internal/ui"
+)
+
+// ParseGitTemplateSource 解析git模板源（url@ref）
+//
+// ref为分支、标签或提交哈希，省略时使用远程仓库的默认分支。
+// 只有仓库路径部分中的@才被视为ref分隔符，因此 https://user@host/org/repo.git
+// 和 git@host:org/repo.git 这样带用户名的地址不会被误拆分，ref中也可以包含/。
+func ParseGitTemplateSource(spec string) (url, ref string, err error) {
+	spec = strings.TrimSpace(spec)
+	if spec == "" {
+		return "", "", fmt.Errorf("git template source is empty")
+	}
+
+	pathStart := 0
+	if i := strings.Index(spec, "://"); i >= 0 {
+		slash := strings.Index(spec[i+3:], "/")
+		if slash < 0 {
+			return "", "", fmt.Errorf("invalid git template source '%s': missing repository path", spec)
+		}
+		pathStart = i + 3 + slash
+	} else if len(spec) < 2 || spec[1] != ':' {
+		// scp风格地址（git@host:org/repo.git），Windows盘符路径除外
+		if colon := strings.Index(spec, ":"); colon >= 0 {
+			pathStart = colon + 1
+		}
+	}
+
+	url = spec
+	if at := strings.LastIndex(spec[pathStart:], "@"); at >= 0 {
+		url = spec[:pathStart+at]
+		ref = spec[pathStart+at+1:]
+		if ref == "" {
+			return "", "", fmt.Errorf("invalid git template source '%s': empty ref after @", spec)
+		}
+	}
+	if strings.TrimRight(url[pathStart:], "/") == "" {
+		return "", "", fmt.Errorf("invalid git template source '%s': missing repository path", spec)
+	}
+
+	return url, ref, nil
+}
+
+// ValidateGitTemplateSource 校验git模板源格式
+func ValidateGitTemplateSource(spec string) error {
+	_, _, err := ParseGitTemplateSource(spec)
+	return err
+}
+
+// downloadFromGit 从git仓库获取模板
+//
+// 仓库浅克隆到临时目录并检出指定的ref，随后按发布包的结构生成模板
+// （见GenerateTemplate），通过与发布包相同的校验后才安装到targetDir，
+// targetDir中已存在的文件按--on-conflict策略处理。
+// 认证由git自身处理（凭据助手、SSH密钥等），不使用--token。
+//
+// 模板由仓库内容生成而不是下载发布包，因此没有可校验的归档：
+// 指定--checksum时返回错误，配置了--signing-key时给出警告。
+func (tp *TemplateProvider) downloadFromGit(ctx context.Context, targetDir string, opts types.DownloadOptions) (string, error) {
+	url, ref, err := ParseGitTemplateSource(opts.TemplateGit)
+	if err != nil {
+		return "", err
+	}
+	if opts.Checksum != "" {
+		return "", fmt.Errorf("--checksum cannot be used with a git template source: there is no release archive to verify")
+	}
+	if opts.SigningKey != "" {
+		ui.ShowWarning("Ignoring --signing-key: templates built from a git repository have no signed release to verify")
+		tp.getLogger().Warn("signing key ignored for git template source", "source", opts.TemplateGit)
+	}
+
+	tempDir, err := os.MkdirTemp("", "specify-template-git-")
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	cloneDir := filepath.Join(tempDir, "repo")
+	generatedDir := filepath.Join(tempDir, "template")
+
+	tp.getLogger().Info("cloning template repository", "url", url, "ref", ref)
+	if opts.Verbose {
+		ui.ShowInfo(fmt.Sprintf("Cloning %s", opts.TemplateGit))
+	}
+
+	git := &GitOperations{logger: tp.getLogger()}
+	if err := git.Clone(ctx, url, cloneDir, &types.CloneOptions{Ref: ref, Depth: 1}); err != nil {
+		return "", fmt.Errorf("failed to clone template repository: %w", err)
+	}
+
+	commit, err := git.command(ctx, cloneDir, "rev-parse", "HEAD").Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve template commit: %w", err)
+	}
+	version := strings.TrimSpace(string(commit))
+	if ref != "" {
+		version = ref
+	}
+
+	if opts.OnResolved != nil {
+		opts.OnResolved(
+			&types.GitHubRelease{TagName: version},
+			&types.Asset{Name: opts.TemplateGit, BrowserDownloadURL: url},
+		)
+	}
+
+	if err := GenerateTemplate(cloneDir, generatedDir, opts.AIAssistant, opts.ScriptType); err != nil {
+		return "", fmt.Errorf("failed to generate template: %w", err)
+	}
+	if err := tp.Validate(generatedDir); err != nil {
+		return "", fmt.Errorf("template repository %s is not a valid template: %w", opts.TemplateGit, err)
+	}
+
+	// 与发布包的提取一样按冲突策略处理已存在的文件，失败时撤销已安装的部分
+	installer := NewStagedInstaller(filepath.Join(tempDir, "backup"))
+	if err := installer.Install(generatedDir, targetDir, opts.OnConflict, opts.Verbose); err != nil {
+		if rbErr := installer.Rollback(); rbErr != nil {
+			tp.getLogger().Warn("failed to roll back template installation", "error", rbErr)
+		}
+		return "", fmt.Errorf("failed to install template: %w", err)
+	}
+
+	tp.getLogger().Info("template downloaded", "source", opts.TemplateGit,
+		"commit", strings.TrimSpace(string(commit)), "dir", targetDir)
+
+	return targetDir, nil
+}
@@ -0,0 +1,154 @@
+package infrastructure
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"specify-cli/internal/types"
+)
+
+// TestParseGitTemplateSource 测试url@ref的拆分
+func TestParseGitTemplateSource(t *testing.T) {
+	tests := []struct {
+		spec, url, ref string
+	}{
+		{"https://github.com/org/spec-kit.git@v1.2.0", "https://github.com/org/spec-kit.git", "v1.2.0"},
+		{"https://github.com/org/spec-kit.git", "https://github.com/org/spec-kit.git", ""},
+		{"https://user@git.example.com/org/spec-kit.git@feature/x", "https://user@git.example.com/org/spec-kit.git", "feature/x"},
+		{"git@github.com:org/spec-kit.git", "git@github.com:org/spec-kit.git", ""},
+		{"git@github.com:org/spec-kit.git@3f2a1b", "git@github.com:org/spec-kit.git", "3f2a1b"},
+		{"/srv/git/spec-kit@main", "/srv/git/spec-kit", "main"},
+	}
+	for _, tt := range tests {
+		url, ref, err := ParseGitTemplateSource(tt.spec)
+		require.NoError(t, err, tt.spec)
+		assert.Equal(t, tt.url, url, tt.spec)
+		assert.Equal(t, tt.ref, ref, tt.spec)
+	}
+
+	for _, invalid := range []string{"", "https://github.com", "https://github.com/org/spec-kit.git@"} {
+		assert.Error(t, ValidateGitTemplateSource(invalid), invalid)
+	}
+}
+
+// runGit 在dir中执行git命令（使用固定的提交者身份）
+func runGit(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	args = append([]string{"-c", "user.name=test", "-c", "user.email=test@example.com", "-c", "commit.gpgsign=false"}, args...)
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	output, err := cmd.CombinedOutput()
+	require.NoError(t, err, string(output))
+}
+
+// TestTemplateProvider_DownloadFromGit 测试从源码仓库的指定标签生成模板
+func TestTemplateProvider_DownloadFromGit(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	repoDir := t.TempDir()
+	files := map[string]string{
+		"memory/constitution.md":        "# Constitution",
+		"scripts/bash/common.sh":        "#!/bin/bash",
+		"scripts/powershell/common.ps1": "# common",
+		"templates/spec-template.md":    "# Spec v1",
+		"templates/commands/plan.md": "---\ndescription: Plan the feature\nscripts:\n  sh: scripts/bash/setup-plan.sh --json\n  ps: scripts/powershell/setup-plan.ps1 -Json\n---\n" +
+			"Run `{SCRIPT}` with {ARGS} and read templates/plan-template.md for __AGENT__.\n",
+	}
+	for name, content := range files {
+		path := filepath.Join(repoDir, filepath.FromSlash(name))
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+	runGit(t, repoDir, "init", "--quiet")
+	runGit(t, repoDir, "add", "-A")
+	runGit(t, repoDir, "commit", "--quiet", "-m", "v1")
+	runGit(t, repoDir, "tag", "v1")
+	require.NoError(t, os.WriteFile(filepath.Join(repoDir, "templates", "spec-template.md"), []byte("# Spec v2"), 0644))
+	runGit(t, repoDir, "commit", "--quiet", "-am", "v2")
+
+	var resolved string
+	targetDir := t.TempDir()
+	provider := NewTemplateProvider()
+	_, err := provider.Download(context.Background(), types.DownloadOptions{
+		AIAssistant: "claude",
+		ScriptType:  "sh",
+		DownloadDir: targetDir,
+		TemplateGit: "file://" + filepath.ToSlash(repoDir) + "@v1",
+		OnResolved: func(release *types.GitHubRelease, asset *types.Asset) {
+			resolved = release.TagName
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "v1", resolved)
+
+	spec, err := os.ReadFile(filepath.Join(targetDir, ".specify", "templates", "spec-template.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "# Spec v1", string(spec))
+	assert.FileExists(t, filepath.Join(targetDir, ".specify", "memory", "constitution.md"))
+	assert.FileExists(t, filepath.Join(targetDir, ".specify", "scripts", "bash", "common.sh"))
+	assert.NoDirExists(t, filepath.Join(targetDir, ".specify", "scripts", "powershell"))
+	assert.NoDirExists(t, filepath.Join(targetDir, ".specify", "templates", "commands"))
+
+	command, err := os.ReadFile(filepath.Join(targetDir, ".claude", "commands", "speckit.plan.md"))
+	require.NoError(t, err)
+	assert.Equal(t, "---\ndescription: Plan the feature\n---\n"+
+		"Run `.specify/scripts/bash/setup-plan.sh --json` with $ARGUMENTS and read .specify/templates/plan-template.md for claude.\n",
+		string(command))
+
+	// 已存在的文件按冲突策略处理
+	specPath := filepath.Join(targetDir, ".specify", "templates", "spec-template.md")
+	require.NoError(t, os.WriteFile(specPath, []byte("# My spec"), 0644))
+	_, err = provider.Download(context.Background(), types.DownloadOptions{
+		AIAssistant: "claude",
+		ScriptType:  "sh",
+		DownloadDir: targetDir,
+		TemplateGit: "file://" + filepath.ToSlash(repoDir),
+		OnConflict:  "skip",
+	})
+	require.NoError(t, err)
+	spec, err = os.ReadFile(specPath)
+	require.NoError(t, err)
+	assert.Equal(t, "# My spec", string(spec))
+
+	_, err = provider.Download(context.Background(), types.DownloadOptions{
+		AIAssistant: "claude",
+		ScriptType:  "sh",
+		DownloadDir: targetDir,
+		TemplateGit: "file://" + filepath.ToSlash(repoDir),
+		OnConflict:  "overwrite",
+	})
+	require.NoError(t, err)
+	spec, err = os.ReadFile(specPath)
+	require.NoError(t, err)
+	assert.Equal(t, "# Spec v2", string(spec))
+
+	// 没有发布包可供校验
+	_, err = provider.Download(context.Background(), types.DownloadOptions{
+		AIAssistant:  "claude",
+		ScriptType:   "sh",
+		DownloadDir:  t.TempDir(),
+		TemplateGit:  "file://" + filepath.ToSlash(repoDir),
+		Checksum:     "abc123",
+		ChecksumType: "sha256",
+	})
+	assert.ErrorContains(t, err, "--checksum")
+
+	// 克隆失败时目标目录保持为空
+	emptyTarget := t.TempDir()
+	_, err = provider.Download(context.Background(), types.DownloadOptions{
+		AIAssistant: "claude",
+		ScriptType:  "ps",
+		DownloadDir: emptyTarget,
+		TemplateGit: "file://" + filepath.ToSlash(filepath.Join(repoDir, "missing")),
+	})
+	assert.Error(t, err)
+	entries, _ := os.ReadDir(emptyTarget)
+	assert.Empty(t, entries)
+}
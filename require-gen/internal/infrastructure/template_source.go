@@ -0,0 +1,417 @@
+package infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-resty/resty/v2"
+	"specify-cli/internal/types"
+)
+
+// TemplateSource 模板发布源
+//
+// 模板源由 template_repo 配置项（--template-repo 标志）选择，按格式区分：
+//   - owner/repo: GitHub Releases（API地址由 api_url 配置）
+//   - gitlab://host/group/project: GitLab Releases（资源取自发布的链接）
+//   - gitea://host/owner/repo: Gitea或Forgejo Releases
+//   - https://host/path/index.json、http://... 或 file:///path/index.json: 静态索引
+//
+// 各发布源把发布信息统一转换为types.GitHubRelease，后续的资源匹配、
+// 下载和解压流程与GitHub相同。令牌按各平台的方式发送，
+// 每个平台使用各自的令牌（见TemplateProvider.sourceToken）。
+type TemplateSource interface {
+	// String 返回用于日志和错误信息的发布源描述
+	String() string
+
+	// LatestRelease 获取最新发布信息
+	//
+	// validators非nil时发送条件请求（If-None-Match/If-Modified-Since），
+	// 成功时更新为响应中的ETag和Last-Modified；服务器返回304时返回errNotModified，
+	// 由调用方使用缓存的发布信息。
+	LatestRelease(ctx context.Context, client *resty.Client, token string, validators *httpValidators) (*types.GitHubRelease, error)
+
+	// AssetRequest 确定资源的下载地址和附加请求头
+	AssetRequest(asset *types.Asset, token string) (string, map[string]string)
+}
+
+// ParseTemplateSource 解析模板源
+//
+// githubAPIURL为GitHub源使用的API基础URL，空表示api.github.com。
+func ParseTemplateSource(source, githubAPIURL string) (TemplateSource, error) {
+	if source == "" {
+		source = types.DefaultTemplateRepo
+	}
+	if githubAPIURL == "" {
+		githubAPIURL = types.DefaultGitHubAPIURL
+	}
+
+	if !strings.Contains(source, "://") {
+		parts := strings.Split(source, "/")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, fmt.Errorf("invalid template source '%s': expected owner/repo, gitlab://host/group/project, gitea://host/owner/repo or an index.json URL", source)
+		}
+		return &githubSource{apiBaseURL: strings.TrimRight(githubAPIURL, "/"), repo: source}, nil
+	}
+
+	u, err := neturl.Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("invalid template source '%s': %w", source, err)
+	}
+	project := strings.Trim(u.Path, "/")
+
+	switch u.Scheme {
+	case "gitlab":
+		if u.Host == "" || !strings.Contains(project, "/") {
+			return nil, fmt.Errorf("invalid GitLab source '%s': expected gitlab://host/group/project", source)
+		}
+		return &gitlabSource{apiBaseURL: "https://" + u.Host + "/api/v4", project: project}, nil
+	case "gitea":
+		if u.Host == "" || len(strings.Split(project, "/")) != 2 {
+			return nil, fmt.Errorf("invalid Gitea source '%s': expected gitea://host/owner/repo", source)
+		}
+		return &giteaSource{apiBaseURL: "https://" + u.Host + "/api/v1", repo: project}, nil
+	case "http", "https":
+		if u.Host == "" {
+			return nil, fmt.Errorf("invalid index URL '%s'", source)
+		}
+		return &indexSource{indexURL: u}, nil
+	case "file":
+		if u.Path == "" {
+			return nil, fmt.Errorf("invalid index path '%s': expected file:///path/to/index.json", source)
+		}
+		return &indexSource{indexURL: u}, nil
+	default:
+		return nil, fmt.Errorf("unsupported template source scheme '%s' (supported: gitlab, gitea, http, https, file)", u.Scheme)
+	}
+}
+
+// ValidateTemplateSource 校验模板源格式
+func ValidateTemplateSource(source string) error {
+	_, err := ParseTemplateSource(source, "")
+	return err
+}
+
+// errNotModified 条件请求的资源未变化（HTTP 304）
+var errNotModified = errors.New("release info not modified")
+
+// httpValidators 条件请求使用的缓存校验值
+type httpValidators struct {
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"last_modified,omitempty"`
+}
+
+// fetchJSON 发送GET请求并解析JSON响应
+//
+// validators的用法见TemplateSource.LatestRelease。
+func fetchJSON(ctx context.Context, client *resty.Client, url string, headers map[string]string, source TemplateSource, out interface{}, validators *httpValidators) error {
+	req := client.R().
+		SetContext(ctx).
+		SetHeader("User-Agent", "Specify-CLI/1.0.0").
+		SetHeaders(headers)
+	if validators != nil {
+		if validators.ETag != "" {
+			req.SetHeader("If-None-Match", validators.ETag)
+		}
+		if validators.LastModified != "" {
+			req.SetHeader("If-Modified-Since", validators.LastModified)
+		}
+	}
+
+	resp, err := req.Get(url)
+	if err != nil {
+		return fmt.Errorf("failed to fetch release info: %w", err)
+	}
+
+	if resp.StatusCode() == http.StatusNotModified && validators != nil {
+		return errNotModified
+	}
+
+	if resp.StatusCode() != 200 {
+		if rl := ParseRateLimit(resp.Header()); isRateLimited(resp.StatusCode(), rl) {
+			_, github := source.(*githubSource)
+			return &RateLimitError{
+				Source:        source.String(),
+				StatusCode:    resp.StatusCode(),
+				RateLimit:     rl,
+				Authenticated: len(headers) > 0,
+				GitHub:        github,
+			}
+		}
+		return &ReleaseStatusError{Source: source.String(), StatusCode: resp.StatusCode(), Body: resp.String()}
+	}
+
+	if err := json.Unmarshal(resp.Body(), out); err != nil {
+		return fmt.Errorf("failed to parse release info: %w", err)
+	}
+
+	if validators != nil {
+		validators.ETag = resp.Header().Get("ETag")
+		validators.LastModified = resp.Header().Get("Last-Modified")
+	}
+	return nil
+}
+
+// ReleaseStatusError 发布信息请求返回的非200状态（速率限制除外）
+type ReleaseStatusError struct {
+	Source     string
+	StatusCode int
+	Body       string
+}
+
+// Error 实现error接口
+func (e *ReleaseStatusError) Error() string {
+	return fmt.Sprintf("%s returned status %d: %s", e.Source, e.StatusCode, e.Body)
+}
+
+// sameHost 判断资源地址是否与API位于同一主机（令牌只发送给该主机）
+func sameHost(rawURL, apiBaseURL string) bool {
+	u, err := neturl.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	api, err := neturl.Parse(apiBaseURL)
+	if err != nil {
+		return false
+	}
+	return u.Host == api.Host
+}
+
+// fileURLPath 将file:// URL转换为本地路径（兼容Windows盘符，如 file:///C:/templates/index.json）
+func fileURLPath(u *neturl.URL) string {
+	path := u.Path
+	if len(path) >= 3 && path[0] == '/' && path[2] == ':' {
+		path = path[1:]
+	}
+	return filepath.FromSlash(path)
+}
+
+// githubSource GitHub Releases
+type githubSource struct {
+	apiBaseURL string
+	repo       string
+}
+
+// String 实现TemplateSource接口
+func (s *githubSource) String() string {
+	return "GitHub API"
+}
+
+// LatestRelease 实现TemplateSource接口
+func (s *githubSource) LatestRelease(ctx context.Context, client *resty.Client, token string, validators *httpValidators) (*types.GitHubRelease, error) {
+	url := fmt.Sprintf("%s/repos/%s/releases/latest", s.apiBaseURL, s.repo)
+
+	var headers map[string]string
+	if token != "" {
+		headers = map[string]string{"Authorization": fmt.Sprintf("token %s", token)}
+	}
+
+	var release types.GitHubRelease
+	if err := fetchJSON(ctx, client, url, headers, s, &release, validators); err != nil {
+		return nil, err
+	}
+	return &release, nil
+}
+
+// AssetRequest 实现TemplateSource接口
+//
+// 私有仓库的browser_download_url需要网页会话，即使带令牌也返回404，
+// 因此使用令牌时改为请求资源API地址并指定Accept: application/octet-stream。
+// API会重定向到对象存储的签名URL，重定向时Authorization头被移除（见redirectPolicy）。
+func (s *githubSource) AssetRequest(asset *types.Asset, token string) (string, map[string]string) {
+	if token == "" || asset.URL == "" {
+		return asset.BrowserDownloadURL, nil
+	}
+	return asset.URL, map[string]string{
+		"Authorization": fmt.Sprintf("token %s", token),
+		"Accept":        "application/octet-stream",
+	}
+}
+
+// gitlabSource GitLab Releases
+//
+// 资源取自发布的链接（release links），通常指向通用软件包仓库或项目上传文件。
+type gitlabSource struct {
+	apiBaseURL string
+	project    string
+}
+
+// gitlabRelease GitLab发布信息
+type gitlabRelease struct {
+	TagName string `json:"tag_name"`
+	Assets  struct {
+		Links []struct {
+			ID             int64  `json:"id"`
+			Name           string `json:"name"`
+			URL            string `json:"url"`
+			DirectAssetURL string `json:"direct_asset_url"`
+		} `json:"links"`
+	} `json:"assets"`
+}
+
+// String 实现TemplateSource接口
+func (s *gitlabSource) String() string {
+	return "GitLab API"
+}
+
+// LatestRelease 实现TemplateSource接口
+func (s *gitlabSource) LatestRelease(ctx context.Context, client *resty.Client, token string, validators *httpValidators) (*types.GitHubRelease, error) {
+	url := fmt.Sprintf("%s/projects/%s/releases?order_by=released_at&sort=desc&per_page=1",
+		s.apiBaseURL, neturl.PathEscape(s.project))
+
+	var releases []gitlabRelease
+	if err := fetchJSON(ctx, client, url, s.authHeaders(token), s, &releases, validators); err != nil {
+		return nil, err
+	}
+	if len(releases) == 0 {
+		return nil, fmt.Errorf("no releases found for GitLab project %s", s.project)
+	}
+
+	release := &types.GitHubRelease{TagName: releases[0].TagName}
+	for _, link := range releases[0].Assets.Links {
+		downloadURL := link.DirectAssetURL
+		if downloadURL == "" {
+			downloadURL = link.URL
+		}
+		release.Assets = append(release.Assets, types.Asset{
+			ID:                 link.ID,
+			Name:               link.Name,
+			BrowserDownloadURL: downloadURL,
+		})
+	}
+	return release, nil
+}
+
+// AssetRequest 实现TemplateSource接口
+//
+// GitLab会将软件包下载重定向到对象存储，重定向时PRIVATE-TOKEN头被移除（见redirectPolicy）。
+func (s *gitlabSource) AssetRequest(asset *types.Asset, token string) (string, map[string]string) {
+	if !sameHost(asset.BrowserDownloadURL, s.apiBaseURL) {
+		return asset.BrowserDownloadURL, nil
+	}
+	return asset.BrowserDownloadURL, s.authHeaders(token)
+}
+
+// authHeaders GitLab使用PRIVATE-TOKEN头认证
+func (s *gitlabSource) authHeaders(token string) map[string]string {
+	if token == "" {
+		return nil
+	}
+	return map[string]string{"PRIVATE-TOKEN": token}
+}
+
+// giteaSource Gitea（及Forgejo）Releases
+type giteaSource struct {
+	apiBaseURL string
+	repo       string
+}
+
+// String 实现TemplateSource接口
+func (s *giteaSource) String() string {
+	return "Gitea API"
+}
+
+// LatestRelease 实现TemplateSource接口
+//
+// Gitea的发布信息格式与GitHub兼容，可以直接解析。
+func (s *giteaSource) LatestRelease(ctx context.Context, client *resty.Client, token string, validators *httpValidators) (*types.GitHubRelease, error) {
+	url := fmt.Sprintf("%s/repos/%s/releases/latest", s.apiBaseURL, s.repo)
+
+	var release types.GitHubRelease
+	if err := fetchJSON(ctx, client, url, s.authHeaders(token), s, &release, validators); err != nil {
+		return nil, err
+	}
+	return &release, nil
+}
+
+// AssetRequest 实现TemplateSource接口
+func (s *giteaSource) AssetRequest(asset *types.Asset, token string) (string, map[string]string) {
+	if !sameHost(asset.BrowserDownloadURL, s.apiBaseURL) {
+		return asset.BrowserDownloadURL, nil
+	}
+	return asset.BrowserDownloadURL, s.authHeaders(token)
+}
+
+// authHeaders Gitea使用 Authorization: token 头认证
+func (s *giteaSource) authHeaders(token string) map[string]string {
+	if token == "" {
+		return nil
+	}
+	return map[string]string{"Authorization": fmt.Sprintf("token %s", token)}
+}
+
+// indexSource 静态索引文件
+//
+// 索引文件格式：
+//
+//	{
+//	  "version": "v1.2.0",
+//	  "assets": [
+//	    {"name": "spec-kit-template-claude-sh-v1.2.0.zip", "url": "claude-sh.zip", "size": 12345}
+//	  ]
+//	}
+//
+// 资源url可以是绝对地址，也可以是相对于索引文件的路径。
+// 索引可以放在任意HTTP服务器上，也可以是本地或网络共享上的文件（file://），
+// 令牌不会发送给索引服务器。
+type indexSource struct {
+	indexURL *neturl.URL
+}
+
+// templateIndex 静态索引文件内容
+type templateIndex struct {
+	Version string `json:"version"`
+	Assets  []struct {
+		Name        string `json:"name"`
+		URL         string `json:"url"`
+		Size        int64  `json:"size"`
+		ContentType string `json:"content_type"`
+	} `json:"assets"`
+}
+
+// String 实现TemplateSource接口
+func (s *indexSource) String() string {
+	return "template index " + s.indexURL.String()
+}
+
+// LatestRelease 实现TemplateSource接口
+func (s *indexSource) LatestRelease(ctx context.Context, client *resty.Client, token string, validators *httpValidators) (*types.GitHubRelease, error) {
+	var index templateIndex
+	if s.indexURL.Scheme == "file" {
+		data, err := os.ReadFile(fileURLPath(s.indexURL))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read template index: %w", err)
+		}
+		if err := json.Unmarshal(data, &index); err != nil {
+			return nil, fmt.Errorf("failed to parse template index: %w", err)
+		}
+	} else if err := fetchJSON(ctx, client, s.indexURL.String(), nil, s, &index, validators); err != nil {
+		return nil, err
+	}
+
+	release := &types.GitHubRelease{TagName: index.Version}
+	for _, asset := range index.Assets {
+		ref, err := neturl.Parse(asset.URL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid URL for asset %s in template index: %w", asset.Name, err)
+		}
+		release.Assets = append(release.Assets, types.Asset{
+			Name:               asset.Name,
+			BrowserDownloadURL: s.indexURL.ResolveReference(ref).String(),
+			ContentType:        asset.ContentType,
+			Size:               asset.Size,
+		})
+	}
+	return release, nil
+}
+
+// AssetRequest 实现TemplateSource接口
+func (s *indexSource) AssetRequest(asset *types.Asset, token string) (string, map[string]string) {
+	return asset.BrowserDownloadURL, nil
+}
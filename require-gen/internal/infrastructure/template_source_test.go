@@ -0,0 +1,175 @@
+package infrastructure
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"specify-cli/internal/types"
+)
+
+// TestParseTemplateSource 测试按格式选择模板源
+func TestParseTemplateSource(t *testing.T) {
+	tests := []struct {
+		source string
+		want   interface{}
+	}{
+		{"", &githubSource{}},
+		{"my-org/spec-kit", &githubSource{}},
+		{"gitlab://gitlab.example.com/team/sub/spec-kit", &gitlabSource{}},
+		{"gitea://git.example.com/team/spec-kit", &giteaSource{}},
+		{"https://files.example.com/spec-kit/index.json", &indexSource{}},
+		{"file:///srv/templates/index.json", &indexSource{}},
+	}
+	for _, tt := range tests {
+		source, err := ParseTemplateSource(tt.source, "")
+		require.NoError(t, err, tt.source)
+		assert.IsType(t, tt.want, source, tt.source)
+	}
+
+	for _, invalid := range []string{"spec-kit", "gitlab://gitlab.com/project", "gitea://host/a/b/c", "s3://bucket/index.json"} {
+		assert.Error(t, ValidateTemplateSource(invalid), invalid)
+	}
+}
+
+// TestGitLabSource_LatestRelease 测试GitLab发布链接转换和PRIVATE-TOKEN认证
+func TestGitLabSource_LatestRelease(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.EscapedPath() != "/api/v4/projects/team%2Fspec-kit/releases" || r.Header.Get("PRIVATE-TOKEN") != "glpat" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(w, `[{"tag_name":"v2.0.0","assets":{"links":[{"id":7,"name":"spec-kit-template-claude-sh-v2.0.0.zip",`+
+			`"url":"%[1]s/team/spec-kit/-/releases/v2.0.0/downloads/claude.zip",`+
+			`"direct_asset_url":"%[1]s/api/v4/projects/1/packages/generic/spec-kit/v2.0.0/claude.zip"}]}}]`, server.URL)
+	}))
+	defer server.Close()
+
+	source := &gitlabSource{apiBaseURL: server.URL + "/api/v4", project: "team/spec-kit"}
+	release, err := source.LatestRelease(context.Background(), resty.New(), "glpat", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "v2.0.0", release.TagName)
+	require.Len(t, release.Assets, 1)
+	assert.Equal(t, server.URL+"/api/v4/projects/1/packages/generic/spec-kit/v2.0.0/claude.zip", release.Assets[0].BrowserDownloadURL)
+
+	_, headers := source.AssetRequest(&release.Assets[0], "glpat")
+	assert.Equal(t, "glpat", headers["PRIVATE-TOKEN"])
+
+	// 令牌不发送给其他主机
+	_, headers = source.AssetRequest(&types.Asset{BrowserDownloadURL: "https://cdn.example.com/claude.zip"}, "glpat")
+	assert.Nil(t, headers)
+}
+
+// TestGiteaSource_LatestRelease 测试Gitea发布信息
+func TestGiteaSource_LatestRelease(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/repos/team/spec-kit/releases/latest" || r.Header.Get("Authorization") != "token gitea" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{"tag_name":"v3.0.0","assets":[{"id":3,"name":"templates.zip","size":10,` +
+			`"browser_download_url":"https://git.example.com/attachments/abc"}]}`))
+	}))
+	defer server.Close()
+
+	source := &giteaSource{apiBaseURL: server.URL + "/api/v1", repo: "team/spec-kit"}
+	release, err := source.LatestRelease(context.Background(), resty.New(), "gitea", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "v3.0.0", release.TagName)
+	require.Len(t, release.Assets, 1)
+	assert.Equal(t, int64(10), release.Assets[0].Size)
+
+	_, err = source.LatestRelease(context.Background(), resty.New(), "", nil)
+	assert.ErrorContains(t, err, "Gitea API returned status 404")
+}
+
+// TestTemplateProvider_DownloadFromFileIndex 测试从本地静态索引下载并解压模板
+func TestTemplateProvider_DownloadFromFileIndex(t *testing.T) {
+	isolateCredentials(t)
+	repoDir := t.TempDir()
+	require.NoError(t, createTestZipFile(filepath.Join(repoDir, "claude-sh.zip"), map[string]string{
+		".specify/templates/spec-template.md": "# Spec",
+	}))
+	index := `{"version":"v1.0.0","assets":[{"name":"spec-kit-template-claude-sh-v1.0.0.zip","url":"claude-sh.zip"}]}`
+	indexPath := filepath.Join(repoDir, "index.json")
+	require.NoError(t, os.WriteFile(indexPath, []byte(index), 0644))
+
+	var resolved string
+	targetDir := t.TempDir()
+	provider := NewTemplateProvider()
+	_, err := provider.Download(context.Background(), types.DownloadOptions{
+		AIAssistant:  "claude",
+		ScriptType:   "sh",
+		DownloadDir:  targetDir,
+		TemplateRepo: "file://" + filepath.ToSlash(indexPath),
+		OnResolved: func(release *types.GitHubRelease, asset *types.Asset) {
+			resolved = release.TagName
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "v1.0.0", resolved)
+	assert.FileExists(t, filepath.Join(targetDir, ".specify", "templates", "spec-template.md"))
+}
+
+// TestGitLabSource_AssetRedirect 测试GitLab软件包下载重定向到对象存储时不发送PRIVATE-TOKEN
+func TestGitLabSource_AssetRedirect(t *testing.T) {
+	storage := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "" || r.Header.Get("Authorization") != "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte("zipdata"))
+	}))
+	defer storage.Close()
+
+	gitlab := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "glpat" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		http.Redirect(w, r, storage.URL+"/packages/claude.zip?X-Amz-Signature=abc", http.StatusFound)
+	}))
+	defer gitlab.Close()
+
+	source := &gitlabSource{apiBaseURL: gitlab.URL + "/api/v4", project: "team/spec-kit"}
+	asset := &types.Asset{
+		Name:               "spec-kit-template-claude-sh-v2.0.0.zip",
+		BrowserDownloadURL: gitlab.URL + "/api/v4/projects/1/packages/generic/spec-kit/v2.0.0/claude.zip",
+		Size:               7,
+	}
+	url, headers := source.AssetRequest(asset, "glpat")
+	require.Equal(t, "glpat", headers["PRIVATE-TOKEN"])
+
+	// resty客户端和流式下载使用同一重定向策略
+	for name, opts := range map[string]types.DownloadOptions{
+		"enhanced":  {},
+		"streaming": {ChunkSize: 1024},
+	} {
+		t.Run(name, func(t *testing.T) {
+			provider := NewTemplateProviderWithConfig(nil, nil).(*TemplateProvider)
+			dest := filepath.Join(t.TempDir(), asset.Name)
+			require.NoError(t, provider.downloadFromSource(context.Background(), url, headers, dest, asset.Size, opts, nil))
+
+			content, err := os.ReadFile(dest)
+			require.NoError(t, err)
+			assert.Equal(t, "zipdata", string(content))
+		})
+	}
+
+	req, _ := http.NewRequest("GET", storage.URL, nil)
+	via, _ := http.NewRequest("GET", gitlab.URL, nil)
+	req.Header.Set("PRIVATE-TOKEN", "glpat")
+	req.Header.Set("Authorization", "Bearer glpat")
+	require.NoError(t, redirectPolicy(0)(req, []*http.Request{via}))
+	assert.Empty(t, req.Header.Get("PRIVATE-TOKEN"))
+	assert.Empty(t, req.Header.Get("Authorization"))
+}
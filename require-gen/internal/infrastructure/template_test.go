@@ -1,6 +1,7 @@
 package infrastructure
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -77,6 +78,10 @@ func (m *mockAuthProvider) SaveToFile(filePath string) error {
 	return nil
 }
 
+func (m *mockAuthProvider) GetTokenDetails() (*types.TokenDetails, error) {
+	return &types.TokenDetails{Type: "classic personal access token", Login: "testuser", Scopes: []string{"repo"}}, nil
+}
+
 func (m *mockAuthProvider) IsTokenExpired() (bool, error) {
 	return false, nil
 }
@@ -90,7 +95,7 @@ type MockTemplateProvider struct {
 	templates      []string
 }
 
-func (m *MockTemplateProvider) Download(opts types.DownloadOptions) (string, error) {
+func (m *MockTemplateProvider) Download(ctx context.Context, opts types.DownloadOptions) (string, error) {
 	if m.downloadError != nil {
 		return "", m.downloadError
 	}
@@ -425,10 +430,11 @@ func TestTemplateProvider_Download_Integration(t *testing.T) {
 		},
 	}
 
+	isolateCredentials(t)
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			provider := NewTemplateProvider()
-			_, err := provider.Download(tt.opts)
+			_, err := provider.Download(context.Background(), tt.opts)
 			
 			if tt.expectError {
 				assert.Error(t, err)
@@ -464,7 +470,7 @@ func TestTemplateProvider_getLatestRelease(t *testing.T) {
 				client: resty.New(),
 			}
 			
-			_, err := provider.getLatestRelease(tt.token)
+			_, err := provider.getLatestRelease(context.Background(), "", tt.token)
 			
 			if tt.expectError {
 				assert.Error(t, err)
@@ -502,7 +508,7 @@ func TestTemplateProvider_downloadAsset(t *testing.T) {
 		authProvider: &mockAuthProvider{}, // 使用模拟的认证提供者
 	}
 
-	err := provider.downloadAsset(asset, downloadPath, opts)
+	err := provider.downloadAsset(context.Background(), asset, downloadPath, opts, nil)
 	assert.NoError(t, err)
 
 	// 验证文件是否存在
@@ -528,12 +534,13 @@ func TestTemplateProvider_extractAsset(t *testing.T) {
 	provider := &TemplateProvider{}
 	
 	// 这个测试预期会失败，因为文件不是真正的zip格式
-	err = provider.extractAsset(zipPath, tempDir, opts)
+	err = provider.extractAsset(context.Background(), zipPath, tempDir, opts)
 	assert.Error(t, err) // 预期错误，因为不是真正的zip文件
 }
 
 // TestTemplateProvider_ListTemplates 测试列出模板
 func TestTemplateProvider_ListTemplates(t *testing.T) {
+	isolateCredentials(t)
 	provider := NewTemplateProvider()
 	
 	// 这个测试预期会失败，因为没有真实的GitHub仓库
@@ -601,4 +608,159 @@ func createBenchmarkTemplateStructure(b *testing.B, baseDir string) {
 	data, err := json.Marshal(info)
 	require.NoError(b, err)
 	require.NoError(b, ioutil.WriteFile(infoPath, data, 0644))
-}
\ No newline at end of file
+}
+// TestTemplateProvider_EnterpriseAssetDownload 测试GitHub Enterprise的发布查询和资源API下载
+func TestTemplateProvider_EnterpriseAssetDownload(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v3/repos/corp/spec-kit/releases/latest":
+			fmt.Fprintf(w, `{"tag_name":"v1.0.0","assets":[{"name":"spec-kit-template-claude-sh-v1.0.0.zip","size":7,`+
+				`"url":"%[1]s/api/v3/repos/corp/spec-kit/releases/assets/1",`+
+				`"browser_download_url":"%[1]s/corp/spec-kit/releases/download/v1.0.0/template.zip"}]}`, server.URL)
+		case "/api/v3/repos/corp/spec-kit/releases/assets/1":
+			if r.Header.Get("Accept") != "application/octet-stream" || r.Header.Get("Authorization") != "token ghe_token" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write([]byte("content"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	provider := NewTemplateProviderWithConfig(&types.NetworkConfig{
+		APIBaseURL: server.URL + "/api/v3/",
+	}, nil).(*TemplateProvider)
+
+	release, err := provider.getLatestRelease(context.Background(), "corp/spec-kit", "ghe_token")
+	require.NoError(t, err)
+	asset, err := provider.findAsset(release, "claude", "sh")
+	require.NoError(t, err)
+
+	dest := filepath.Join(t.TempDir(), asset.Name)
+	opts := types.DownloadOptions{GitHubToken: "ghe_token"}
+	require.NoError(t, provider.downloadAsset(context.Background(), asset, dest, opts, nil))
+
+	content, err := os.ReadFile(dest)
+	require.NoError(t, err)
+	assert.Equal(t, "content", string(content))
+}
+
+// TestTemplateProvider_CredentialFallback 测试外部凭据只在匿名请求返回404时使用
+func TestTemplateProvider_CredentialFallback(t *testing.T) {
+	isolateCredentials(t)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		switch r.URL.Path {
+		case "/api/v3/repos/corp/public/releases/latest":
+			// 公开仓库拒绝过期的凭据
+			if auth != "" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Write([]byte(`{"tag_name":"v1.0.0","assets":[]}`))
+		case "/api/v3/repos/corp/private/releases/latest":
+			if auth != "token ghe_netrc_token" {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write([]byte(`{"tag_name":"v2.0.0","assets":[]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	netrc := filepath.Join(t.TempDir(), "netrc")
+	require.NoError(t, os.WriteFile(netrc, []byte("machine 127.0.0.1 login octocat password ghe_netrc_token\n"), 0600))
+	t.Setenv("NETRC", netrc)
+
+	provider := NewTemplateProviderWithConfig(&types.NetworkConfig{
+		APIBaseURL: server.URL + "/api/v3/",
+	}, nil).(*TemplateProvider)
+
+	release, token, err := provider.fetchRelease(context.Background(), "corp/public", provider.sourceToken("corp/public", types.DownloadOptions{}))
+	require.NoError(t, err)
+	assert.Equal(t, "v1.0.0", release.TagName)
+	assert.Empty(t, token)
+
+	release, token, err = provider.fetchRelease(context.Background(), "corp/private", provider.sourceToken("corp/private", types.DownloadOptions{}))
+	require.NoError(t, err)
+	assert.Equal(t, "v2.0.0", release.TagName)
+	assert.Equal(t, "ghe_netrc_token", token)
+
+	// 显式指定的令牌失败时不改用外部凭据
+	_, token, err = provider.fetchRelease(context.Background(), "corp/private", "ghp_wrong")
+	assert.ErrorContains(t, err, "returned status 404")
+	assert.Equal(t, "ghp_wrong", token)
+}
+
+// TestTemplateProvider_SourceToken 测试GitHub令牌只发送给GitHub源，其他平台使用各自的令牌
+func TestTemplateProvider_SourceToken(t *testing.T) {
+	isolateCredentials(t)
+	t.Setenv("GH_TOKEN", "ghp_env")
+
+	provider := NewTemplateProvider().(*TemplateProvider)
+	opts := types.DownloadOptions{GitHubToken: "ghp_secret", GitLabToken: "glpat", GiteaToken: "gitea_token"}
+
+	assert.Equal(t, "ghp_secret", provider.sourceToken("corp/spec-kit", opts))
+	assert.Equal(t, "glpat", provider.sourceToken("gitlab://gitlab.example.com/team/spec-kit", opts))
+	assert.Equal(t, "gitea_token", provider.sourceToken("gitea://git.example.com/team/spec-kit", opts))
+	assert.Empty(t, provider.sourceToken("https://templates.example.com/index.json", opts))
+
+	// 没有对应平台的令牌时不使用GitHub令牌或GH_TOKEN
+	opts = types.DownloadOptions{GitHubToken: "ghp_secret"}
+	assert.Empty(t, provider.sourceToken("gitlab://gitlab.example.com/team/spec-kit", opts))
+	assert.Empty(t, provider.sourceToken("gitea://git.example.com/team/spec-kit", opts))
+	assert.Equal(t, "ghp_env", provider.sourceToken("corp/spec-kit", types.DownloadOptions{}))
+}
+
+// TestTemplateProvider_PrivateAssetRedirect 测试私有仓库资源通过API下载且重定向到存储主机时不发送令牌
+func TestTemplateProvider_PrivateAssetRedirect(t *testing.T) {
+	storage := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte("zipdata"))
+	}))
+	defer storage.Close()
+
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/repos/owner/private/releases/assets/42" ||
+			r.Header.Get("Authorization") != "token secret" ||
+			r.Header.Get("Accept") != "application/octet-stream" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		http.Redirect(w, r, storage.URL+"/signed/template.zip?sig=abc", http.StatusFound)
+	}))
+	defer api.Close()
+
+	var release types.GitHubRelease
+	require.NoError(t, json.Unmarshal([]byte(fmt.Sprintf(`{"tag_name":"v1","assets":[{"id":42,`+
+		`"name":"spec-kit-template-claude-sh-v1.zip","content_type":"application/zip","size":7,`+
+		`"url":"%s/repos/owner/private/releases/assets/42",`+
+		`"browser_download_url":"%s/owner/private/releases/download/v1/template.zip"}]}`, api.URL, api.URL)), &release))
+	asset := &release.Assets[0]
+	assert.Equal(t, int64(42), asset.ID)
+	assert.Equal(t, "application/zip", asset.ContentType)
+
+	provider := NewTemplateProviderWithConfig(&types.NetworkConfig{APIBaseURL: api.URL}, nil).(*TemplateProvider)
+	dest := filepath.Join(t.TempDir(), asset.Name)
+	require.NoError(t, provider.downloadAsset(context.Background(), asset, dest, types.DownloadOptions{GitHubToken: "secret"}, nil))
+
+	content, err := os.ReadFile(dest)
+	require.NoError(t, err)
+	assert.Equal(t, "zipdata", string(content))
+
+	// 请求头中的令牌不应跟随跨主机重定向
+	req, _ := http.NewRequest("GET", storage.URL, nil)
+	via, _ := http.NewRequest("GET", api.URL, nil)
+	req.Header.Set("Authorization", "token secret")
+	require.NoError(t, redirectPolicy(0)(req, []*http.Request{via}))
+	assert.Empty(t, req.Header.Get("Authorization"))
+}
@@ -0,0 +1,178 @@
+package infrastructure
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"specify-cli/internal/types"
+)
+
+// TokenType GitHub令牌类型
+type TokenType string
+
+const (
+	TokenTypeClassic      TokenType = "classic personal access token"
+	TokenTypeFineGrained  TokenType = "fine-grained personal access token"
+	TokenTypeOAuth        TokenType = "OAuth access token"
+	TokenTypeUserToServer TokenType = "GitHub App user access token"
+	TokenTypeInstallation TokenType = "GitHub App installation token"
+	TokenTypeRefresh      TokenType = "GitHub App refresh token"
+	TokenTypeLegacy       TokenType = "legacy personal access token" // 2021年以前的40位十六进制令牌
+	TokenTypeUnknown      TokenType = "unknown"
+)
+
+// tokenPrefixes GitHub令牌前缀与类型的对应关系
+var tokenPrefixes = []struct {
+	prefix    string
+	tokenType TokenType
+}{
+	{"github_pat_", TokenTypeFineGrained},
+	{"ghp_", TokenTypeClassic},
+	{"gho_", TokenTypeOAuth},
+	{"ghu_", TokenTypeUserToServer},
+	{"ghs_", TokenTypeInstallation},
+	{"ghr_", TokenTypeRefresh},
+}
+
+// tokenExpirationHeader GitHub返回的令牌过期时间响应头
+//
+// 设置了有效期的个人访问令牌（经典和细粒度）、GitHub App令牌的API响应中包含该头，
+// 格式为 "2024-06-30 12:00:00 UTC" 或 "2024-06-30 12:00:00 -0700"。
+const tokenExpirationHeader = "GitHub-Authentication-Token-Expiration"
+
+// DetectTokenType 根据前缀识别令牌类型
+func DetectTokenType(token string) TokenType {
+	for _, p := range tokenPrefixes {
+		if strings.HasPrefix(token, p.prefix) {
+			return p.tokenType
+		}
+	}
+	if len(token) == 40 && strings.Trim(strings.ToLower(token), "0123456789abcdef") == "" {
+		return TokenTypeLegacy
+	}
+	return TokenTypeUnknown
+}
+
+// tokenPrefix 令牌类型对应的前缀
+func tokenPrefix(tokenType TokenType) string {
+	for _, p := range tokenPrefixes {
+		if p.tokenType == tokenType {
+			return p.prefix
+		}
+	}
+	return ""
+}
+
+// parseTokenExpiration 解析令牌过期时间响应头，无法解析时返回nil
+func parseTokenExpiration(value string) *time.Time {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05 MST", "2006-01-02 15:04:05 -0700", time.RFC3339} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return &t
+		}
+	}
+	return nil
+}
+
+// GetTokenDetails 获取令牌类型、权限和过期时间
+//
+// 经典令牌和OAuth令牌的权限来自/user响应的X-OAuth-Scopes头；
+// GitHub App安装令牌不能访问/user，改为查询/installation/repositories，
+// 并汇总可访问仓库上的权限。过期时间来自GitHub-Authentication-Token-Expiration头。
+func (ap *AuthProvider) GetTokenDetails() (*types.TokenDetails, error) {
+	token := ap.GetToken()
+	if token == "" {
+		return nil, NewTokenNotFoundError(ap.GetTokenSource())
+	}
+	if err := ap.validateTokenFormat(token); err != nil {
+		return nil, err
+	}
+	return ap.fetchTokenDetails(token)
+}
+
+// fetchTokenDetails 通过GitHub API获取令牌详细信息
+//
+// 请求失败时仍返回已知的类型和过期时间（如果响应包含），便于区分过期和无效的令牌。
+func (ap *AuthProvider) fetchTokenDetails(token string) (*types.TokenDetails, error) {
+	tokenType := DetectTokenType(token)
+	details := &types.TokenDetails{Type: string(tokenType)}
+
+	path := "/user"
+	if tokenType == TokenTypeInstallation {
+		path = "/installation/repositories?per_page=100"
+	}
+
+	req, err := http.NewRequest("GET", ap.apiURL(path), nil)
+	if err != nil {
+		return details, NewNetworkError(fmt.Errorf("failed to create request: %w", err))
+	}
+
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	req.Header.Set("User-Agent", "Specify-CLI/1.0.0")
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+
+	resp, err := ap.httpClient().Do(req)
+	if err != nil {
+		return details, NewNetworkError(fmt.Errorf("failed to validate token: %w", err))
+	}
+	defer resp.Body.Close()
+
+	details.ExpiresAt = parseTokenExpiration(resp.Header.Get(tokenExpirationHeader))
+
+	switch resp.StatusCode {
+	case 200:
+	case 401:
+		if details.Expired() {
+			return details, NewTokenExpiredError()
+		}
+		return details, NewTokenInvalidError("invalid or expired token")
+	case 403:
+		return details, NewPermissionDeniedError("user information access")
+	default:
+		return details, NewAPIError(resp.StatusCode, fmt.Sprintf("token validation failed with status %d", resp.StatusCode))
+	}
+
+	// 从响应头获取权限范围（细粒度令牌和安装令牌没有该头）
+	if scopes := resp.Header.Get("X-OAuth-Scopes"); scopes != "" {
+		for _, scope := range strings.Split(scopes, ",") {
+			if scope = strings.TrimSpace(scope); scope != "" {
+				details.Scopes = append(details.Scopes, scope)
+			}
+		}
+	}
+
+	if tokenType == TokenTypeInstallation {
+		var body struct {
+			Repositories []struct {
+				Permissions map[string]bool `json:"permissions"`
+			} `json:"repositories"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+			return details, NewAPIError(0, fmt.Sprintf("failed to parse installation repositories: %v", err))
+		}
+		details.Permissions = make(map[string]bool)
+		for _, repo := range body.Repositories {
+			for name, granted := range repo.Permissions {
+				details.Permissions[name] = details.Permissions[name] || granted
+			}
+		}
+	} else {
+		var user struct {
+			Login string `json:"login"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&user); err == nil {
+			details.Login = user.Login
+		}
+	}
+
+	if details.Expired() {
+		return details, NewTokenExpiredError()
+	}
+	return details, nil
+}
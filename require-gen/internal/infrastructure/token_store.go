@@ -0,0 +1,193 @@
+package infrastructure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zalando/go-keyring"
+)
+
+// tokenKeyringService 系统密钥环中保存令牌使用的服务名
+const tokenKeyringService = "specify-cli"
+
+// ErrTokenNotStored 存储中没有主机的令牌
+var ErrTokenNotStored = errors.New("no token stored")
+
+// TokenStore 令牌存储后端
+//
+// specify auth login 保存的令牌按API主机存放，认证提供者在环境变量之后读取。
+type TokenStore interface {
+	// Name 后端名称，用于提示令牌保存位置
+	Name() string
+	// Get 读取主机的令牌，不存在时返回ErrTokenNotStored
+	Get(host string) (string, error)
+	// Set 保存主机的令牌
+	Set(host, token string) error
+	// Delete 删除主机的令牌，不存在时返回ErrTokenNotStored
+	Delete(host string) error
+}
+
+// keyringTokenStore 使用系统密钥环（macOS钥匙串、Windows凭据管理器、Secret Service）
+type keyringTokenStore struct{}
+
+// Name 实现TokenStore接口
+func (keyringTokenStore) Name() string {
+	return "system keyring"
+}
+
+// Get 实现TokenStore接口
+func (keyringTokenStore) Get(host string) (string, error) {
+	token, err := keyring.Get(tokenKeyringService, host)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return "", ErrTokenNotStored
+	}
+	return token, err
+}
+
+// Set 实现TokenStore接口
+func (keyringTokenStore) Set(host, token string) error {
+	return keyring.Set(tokenKeyringService, host, token)
+}
+
+// Delete 实现TokenStore接口
+func (keyringTokenStore) Delete(host string) error {
+	err := keyring.Delete(tokenKeyringService, host)
+	if errors.Is(err, keyring.ErrNotFound) {
+		return ErrTokenNotStored
+	}
+	return err
+}
+
+// available 检查密钥环服务是否可用
+//
+// 没有桌面会话的Linux（SSH、容器、CI）通常没有Secret Service，
+// 此时查询会返回ErrNotFound以外的错误。
+func (s keyringTokenStore) available() bool {
+	_, err := s.Get("specify-cli-probe")
+	return err == nil || errors.Is(err, ErrTokenNotStored)
+}
+
+// fileTokenStore 将令牌保存在用户配置目录下的文件中（仅所有者可读写）
+type fileTokenStore struct {
+	dir string
+}
+
+// Name 实现TokenStore接口
+func (s *fileTokenStore) Name() string {
+	return s.dir
+}
+
+// path 主机对应的令牌文件
+func (s *fileTokenStore) path(host string) string {
+	return filepath.Join(s.dir, strings.NewReplacer(":", "_", "/", "_", `\`, "_").Replace(host))
+}
+
+// Get 实现TokenStore接口
+func (s *fileTokenStore) Get(host string) (string, error) {
+	data, err := os.ReadFile(s.path(host))
+	if errors.Is(err, os.ErrNotExist) {
+		return "", ErrTokenNotStored
+	}
+	if err != nil {
+		return "", NewFileError("read", s.path(host), err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// Set 实现TokenStore接口
+func (s *fileTokenStore) Set(host, token string) error {
+	if err := os.MkdirAll(s.dir, 0700); err != nil {
+		return NewFileError("create", s.dir, err)
+	}
+	if err := os.WriteFile(s.path(host), []byte(token), 0600); err != nil {
+		return NewFileError("write", s.path(host), err)
+	}
+	// WriteFile不会修改已存在文件的权限
+	return os.Chmod(s.path(host), 0600)
+}
+
+// Delete 实现TokenStore接口
+func (s *fileTokenStore) Delete(host string) error {
+	err := os.Remove(s.path(host))
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrTokenNotStored
+	}
+	return err
+}
+
+// NewFileTokenStore 创建位于用户配置目录的文件令牌存储
+//
+// 令牌保存在 <配置目录>/specify/hosts/<主机名>，例如Linux上的
+// ~/.config/specify/hosts/github.com。
+func NewFileTokenStore() (TokenStore, error) {
+	configDir, err := NewSystemOperations().GetPlatformSpecificPath("config")
+	if err != nil {
+		return nil, fmt.Errorf("failed to locate user config directory: %w", err)
+	}
+	return &fileTokenStore{dir: filepath.Join(configDir, "specify", "hosts")}, nil
+}
+
+// NewTokenStore 创建保存令牌使用的存储
+//
+// 系统密钥环可用时使用密钥环，否则（如无桌面会话的Linux）退回到文件存储。
+// insecureStorage为true时总是使用文件存储。
+func NewTokenStore(insecureStorage bool) (TokenStore, error) {
+	if !insecureStorage && (keyringTokenStore{}).available() {
+		return keyringTokenStore{}, nil
+	}
+	return NewFileTokenStore()
+}
+
+// tokenStores 读取令牌时依次查询的存储
+func tokenStores() []TokenStore {
+	stores := []TokenStore{keyringTokenStore{}}
+	if fileStore, err := NewFileTokenStore(); err == nil {
+		stores = append(stores, fileStore)
+	}
+	return stores
+}
+
+// StoredToken 读取specify auth login为主机保存的令牌
+//
+// 返回令牌和保存它的存储；没有保存的令牌时返回ErrTokenNotStored。
+func StoredToken(host string) (string, TokenStore, error) {
+	for _, store := range tokenStores() {
+		if token, err := store.Get(host); err == nil && token != "" {
+			return token, store, nil
+		}
+	}
+	return "", nil, ErrTokenNotStored
+}
+
+// DeleteStoredToken 从所有存储中删除主机的令牌
+//
+// 返回删除了令牌的存储；没有保存的令牌时返回ErrTokenNotStored。
+func DeleteStoredToken(host string) ([]TokenStore, error) {
+	var deleted []TokenStore
+	for _, store := range tokenStores() {
+		err := store.Delete(host)
+		switch {
+		case err == nil:
+			deleted = append(deleted, store)
+		case errors.Is(err, ErrTokenNotStored):
+		case store.Name() == (keyringTokenStore{}).Name():
+			// 密钥环不可用时忽略，文件存储中可能有令牌
+		default:
+			return deleted, err
+		}
+	}
+	if len(deleted) == 0 {
+		return nil, ErrTokenNotStored
+	}
+	return deleted, nil
+}
+
+// storedTokenLookup 外部凭据来源：specify auth login保存的令牌
+func storedTokenLookup(ctx context.Context, host string) string {
+	token, _, _ := StoredToken(host)
+	return token
+}
@@ -2,6 +2,7 @@ package infrastructure
 
 import (
 	"archive/zip"
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -14,10 +15,10 @@ import (
 
 // ZipProcessor ZIP文件处理器接口
 type ZipProcessor interface {
-	ExtractZip(zipPath, targetDir string, opts *ExtractOptions) error
+	ExtractZip(ctx context.Context, zipPath, targetDir string, opts *ExtractOptions) error
 	ListZipContents(zipPath string) ([]string, error)
 	ValidateZip(zipPath string) error
-	ExtractWithProgress(zipPath, targetDir string, opts *ExtractOptions,
+	ExtractWithProgress(ctx context.Context, zipPath, targetDir string, opts *ExtractOptions,
 		progressCallback func(current, total int64)) error
 }
 
@@ -33,10 +34,11 @@ type ExtractOptions struct {
 	Verbose             bool     // 详细输出模式
 	TempDir             string   // 临时目录路径
 	SmartMerge          bool     // 智能合并模式（处理文件冲突）
-	ConflictResolution  string   // 冲突解决策略：skip, overwrite, rename, prompt
+	ConflictResolution  string   // 冲突解决策略：skip, overwrite, rename, prompt, merge
 	OnProgress          func(current, total int64, filename string) // 进度回调函数
 	OnError             func(filename string, err error) bool       // 错误处理回调，返回true继续，false停止
 	OnConflict          func(conflictInfo *FileConflictInfo) ConflictAction // 冲突处理回调
+	OnMerge             func(targetPath string, result *StructuredMergeResult, err error) // 结构化合并结果回调
 }
 
 // ConflictAction 文件冲突处理动作
@@ -47,8 +49,26 @@ const (
 	ConflictOverwrite
 	ConflictRename
 	ConflictPrompt
+	ConflictMerge
 )
 
+// ConflictStrategies 支持的冲突解决策略列表（与--on-conflict标志取值一致）
+var ConflictStrategies = []string{"skip", "overwrite", "rename", "prompt", "merge"}
+
+// ValidateConflictResolution 验证冲突解决策略是否受支持，空字符串表示使用默认行为
+func ValidateConflictResolution(strategy string) error {
+	if strategy == "" {
+		return nil
+	}
+	for _, s := range ConflictStrategies {
+		if s == strategy {
+			return nil
+		}
+	}
+	return fmt.Errorf("unsupported conflict resolution '%s' (expected one of: %s)",
+		strategy, strings.Join(ConflictStrategies, ", "))
+}
+
 // FileConflictInfo 文件冲突信息
 type FileConflictInfo struct {
 	SourcePath string
@@ -56,6 +76,7 @@ type FileConflictInfo struct {
 	Exists     bool
 	Size       int64
 	ModTime    int64
+	ReadSource func() ([]byte, error) // 读取源文件内容（用于交互式差异预览），可能为nil
 }
 
 // ZipProcessorImpl ZIP处理器实现
@@ -246,7 +267,9 @@ func (zp *ZipProcessorImpl) validateZipEntryPath(entryPath string) error {
 }
 
 // ExtractZip 提取ZIP文件到目标目录
-func (zp *ZipProcessorImpl) ExtractZip(zipPath, targetDir string, opts *ExtractOptions) error {
+//
+// 每个条目提取前都会检查ctx，取消后立即停止并返回包装了ctx.Err()的ZipError。
+func (zp *ZipProcessorImpl) ExtractZip(ctx context.Context, zipPath, targetDir string, opts *ExtractOptions) error {
 	zp.mu.Lock()
 	defer zp.mu.Unlock()
 
@@ -303,6 +326,13 @@ func (zp *ZipProcessorImpl) ExtractZip(zipPath, targetDir string, opts *ExtractO
 
 	// 提取所有文件
 	for _, file := range reader.File {
+		if err := ctx.Err(); err != nil {
+			return &ZipError{
+				Operation: "extract",
+				Path:      zipPath,
+				Cause:     fmt.Errorf("extraction cancelled: %w", err),
+			}
+		}
 		if err := zp.extractSingleFile(file, targetDir, opts); err != nil {
 			return &ZipError{
 				Operation: "extract",
@@ -316,7 +346,7 @@ func (zp *ZipProcessorImpl) ExtractZip(zipPath, targetDir string, opts *ExtractO
 }
 
 // ExtractWithProgress 带进度的提取操作
-func (zp *ZipProcessorImpl) ExtractWithProgress(zipPath, targetDir string, opts *ExtractOptions,
+func (zp *ZipProcessorImpl) ExtractWithProgress(ctx context.Context, zipPath, targetDir string, opts *ExtractOptions,
 	progressCallback func(current, total int64)) error {
 	zp.mu.Lock()
 	defer zp.mu.Unlock()
@@ -387,6 +417,14 @@ func (zp *ZipProcessorImpl) ExtractWithProgress(zipPath, targetDir string, opts
 	// 提取所有文件并报告进度
 	for _, file := range reader.File {
 		filename := file.Name
+
+		if err := ctx.Err(); err != nil {
+			return &ZipError{
+				Operation: "extract_progress",
+				Path:      zipPath,
+				Cause:     fmt.Errorf("extraction cancelled: %w", err),
+			}
+		}
 		
 		// 调用详细进度回调
 		if opts.OnProgress != nil {
@@ -492,6 +530,14 @@ func (zp *ZipProcessorImpl) extractSingleFile(file *zip.File, targetDir string,
 			Exists:     true,
 			Size:       int64(file.UncompressedSize64),
 			ModTime:    file.Modified.Unix(),
+			ReadSource: func() ([]byte, error) {
+				rc, err := file.Open()
+				if err != nil {
+					return nil, err
+				}
+				defer rc.Close()
+				return io.ReadAll(rc)
+			},
 		}
 
 		// 处理文件冲突
@@ -515,6 +561,8 @@ func (zp *ZipProcessorImpl) extractSingleFile(file *zip.File, targetDir string,
 			if opts.Verbose {
 				fmt.Printf("Overwriting existing file: %s\n", targetPath)
 			}
+		case ConflictMerge:
+			return mergeConflictFile(conflictInfo, opts)
 		}
 	}
 
@@ -570,6 +618,8 @@ func (zp *ZipProcessorImpl) handleFileConflict(conflictInfo *FileConflictInfo, o
 		case ConflictRename:
 			newPath := zp.generateUniqueFileName(conflictInfo.TargetPath)
 			return newPath, ConflictRename, nil
+		case ConflictMerge:
+			return zp.resolveMerge(conflictInfo)
 		default:
 			return conflictInfo.TargetPath, ConflictOverwrite, nil
 		}
@@ -590,6 +640,8 @@ func (zp *ZipProcessorImpl) handleFileConflict(conflictInfo *FileConflictInfo, o
 			fmt.Printf("File conflict: %s already exists\n", conflictInfo.TargetPath)
 		}
 		return conflictInfo.TargetPath, ConflictOverwrite, nil
+	case "merge":
+		return zp.resolveMerge(conflictInfo)
 	default:
 		// 默认策略：如果源文件更新则覆盖，否则跳过
 		if zp.isSourceNewer(conflictInfo) {
@@ -599,6 +651,18 @@ func (zp *ZipProcessorImpl) handleFileConflict(conflictInfo *FileConflictInfo, o
 	}
 }
 
+// resolveMerge 合并策略：JSON/YAML/TOML配置文件进行结构化合并，
+// 其他文件仅当源文件比现有文件更新时覆盖，否则保留现有文件
+func (zp *ZipProcessorImpl) resolveMerge(conflictInfo *FileConflictInfo) (string, ConflictAction, error) {
+	if StructuredFormat(conflictInfo.TargetPath) != "" {
+		return conflictInfo.TargetPath, ConflictMerge, nil
+	}
+	if zp.isSourceNewer(conflictInfo) {
+		return conflictInfo.TargetPath, ConflictOverwrite, nil
+	}
+	return "", ConflictSkip, nil
+}
+
 // generateUniqueFileName 生成唯一文件名
 func (zp *ZipProcessorImpl) generateUniqueFileName(originalPath string) string {
 	dir := filepath.Dir(originalPath)
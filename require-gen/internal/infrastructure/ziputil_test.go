@@ -2,6 +2,8 @@ package infrastructure
 
 import (
 	"archive/zip"
+	"context"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -300,7 +302,7 @@ func TestZipProcessor_ExtractZip(t *testing.T) {
 	}
 
 	// 测试ZIP提取
-	err = processor.ExtractZip(zipPath, extractDir, opts)
+	err = processor.ExtractZip(context.Background(), zipPath, extractDir, opts)
 	if err != nil {
 		t.Errorf("ExtractZip failed: %v", err)
 	}
@@ -317,6 +319,41 @@ func TestZipProcessor_ExtractZip(t *testing.T) {
 	}
 }
 
+func TestZipProcessor_ExtractZipCancelled(t *testing.T) {
+	// 创建临时目录
+	tempDir, err := os.MkdirTemp("", "ziptest_*")
+	if err != nil {
+		t.Fatalf("Failed to create temp dir: %v", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// 创建测试ZIP文件
+	zipPath := filepath.Join(tempDir, "test.zip")
+	createTestZip(t, zipPath, map[string]string{"file1.txt": "Hello World"})
+
+	extractDir := filepath.Join(tempDir, "extract")
+	if err := os.MkdirAll(extractDir, 0755); err != nil {
+		t.Fatalf("Failed to create extract dir: %v", err)
+	}
+
+	mockSysOps := NewMockSystemOperations()
+	mockSysOps.files[zipPath] = true
+	processor := NewZipProcessor(mockSysOps)
+
+	// 已取消的上下文不应提取任何文件
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	err = processor.ExtractZip(ctx, zipPath, extractDir, &ExtractOptions{OverwriteExisting: true})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got: %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(extractDir, "file1.txt")); !os.IsNotExist(err) {
+		t.Errorf("File should not be extracted after cancellation")
+	}
+}
+
 func TestZipProcessor_ExtractWithProgress(t *testing.T) {
 	// 创建临时目录
 	tempDir, err := os.MkdirTemp("", "ziptest_*")
@@ -362,7 +399,7 @@ func TestZipProcessor_ExtractWithProgress(t *testing.T) {
 	}
 
 	// 测试带进度的ZIP提取
-	err = processor.ExtractWithProgress(zipPath, extractDir, opts, progressCallback)
+	err = processor.ExtractWithProgress(context.Background(), zipPath, extractDir, opts, progressCallback)
 	if err != nil {
 		t.Errorf("ExtractWithProgress failed: %v", err)
 	}
@@ -1,6 +1,7 @@
 package types
 
 import (
+	"context"
 	"sync"
 	"time"
 )
@@ -18,6 +19,7 @@ type TLSConfig struct {
 type NetworkConfig struct {
 	TLS           *TLSConfig    `json:"tls"`             // TLS配置
 	ProxyURL      string        `json:"proxy_url"`       // 代理服务器URL
+	APIBaseURL    string        `json:"api_base_url"`    // GitHub API基础URL，空表示api.github.com
 	Timeout       time.Duration `json:"timeout"`         // 请求超时时间
 	RetryCount    int           `json:"retry_count"`     // 重试次数
 	RetryWaitTime time.Duration `json:"retry_wait_time"` // 重试等待时间
@@ -234,6 +236,8 @@ type InitOptions struct {
 	AIAssistant     string
 	ScriptType      string
 	GitHubToken     string
+	GitLabToken     string // SPECIFY_GITLAB_TOKEN：gitlab://模板源使用的令牌
+	GiteaToken      string // SPECIFY_GITEA_TOKEN：gitea://模板源使用的令牌
 	Verbose         bool
 	Debug           bool
 	// 新增的CLI标志
@@ -241,6 +245,13 @@ type InitOptions struct {
 	NoGit           bool   // --no-git 标志：跳过Git仓库初始化
 	IgnoreTools     bool   // --ignore-agent-tools 标志：忽略AI助手工具的可用性检查
 	SkipTLS         bool   // --skip-tls 标志：跳过TLS证书验证
+	OnConflict      string // --on-conflict 标志：文件冲突策略（skip/overwrite/rename/prompt/merge）
+	TemplateRepo    string        // --template-repo 标志：模板源（GitHub的owner/repo、gitlab://、gitea://或索引URL）
+	TemplateGit     string        // --template-git 标志：直接从git仓库获取模板（url@ref），优先于TemplateRepo
+	Checksum        string        // --checksum 标志：固定模板压缩包的校验和（sha256:<摘要>）
+	SigningKey      string        // --signing-key 标志：校验和清单的签名公钥（minisign或SSH公钥，或其文件路径）
+	LimitRate       string        // --limit-rate 标志：下载限速（如500k、2M），空表示不限速
+	Mirrors         string        // --mirrors 标志：逗号分隔的镜像地址，原始地址下载失败时依次尝试
 }
 
 // DownloadOptions 下载选项配置
@@ -251,6 +262,8 @@ type DownloadOptions struct {
 	Verbose         bool                   `json:"verbose"`          // 详细输出
 	ShowProgress    bool                   `json:"show_progress"`    // 显示进度
 	GitHubToken     string                 `json:"github_token"`     // GitHub令牌
+	GitLabToken     string                 `json:"gitlab_token"`     // GitLab令牌，只发送给gitlab://模板源
+	GiteaToken      string                 `json:"gitea_token"`      // Gitea令牌，只发送给gitea://模板源
 	SkipTLS         bool                   `json:"skip_tls"`         // 跳过TLS证书验证
 	NetworkConfig   *NetworkConfig         `json:"network_config"`   // 网络配置
 	HTTPConfig      *HTTPClientConfig      `json:"http_config"`      // HTTP客户端配置
@@ -259,9 +272,16 @@ type DownloadOptions struct {
 	ProgressDisplay ProgressDisplay        `json:"-"`                // 进度显示器（不序列化）
 	ProgressCallback func(*ProgressInfo)   `json:"-"`                // 进度回调（不序列化）
 	MaxConcurrent   int                    `json:"max_concurrent"`   // 最大并发下载数
+	LimitRate       int64                  `json:"limit_rate"`       // 下载限速（字节/秒），所有并发连接共享，0表示不限速
+	Mirrors         []string               `json:"mirrors"`          // 镜像基础URL，原始地址下载失败时按顺序尝试
 	VerifyChecksum  bool                   `json:"verify_checksum"`  // 验证校验和
 	Checksum        string                 `json:"checksum"`         // 预期校验和
 	ChecksumType    string                 `json:"checksum_type"`    // 校验和类型（md5, sha1, sha256）
+	SigningKey      string                 `json:"signing_key"`      // 校验和清单的签名公钥，设置后要求发布提供有效签名
+	OnConflict      string                 `json:"on_conflict"`      // 文件冲突策略（skip, overwrite, rename, prompt, merge）
+	TemplateRepo    string                 `json:"template_repo"`    // 模板源（格式见infrastructure.TemplateSource），空表示默认仓库
+	TemplateGit     string                 `json:"template_git"`     // git仓库模板源（url@ref），设置时忽略TemplateRepo
+	OnResolved      func(release *GitHubRelease, asset *Asset) `json:"-"` // 解析出发布版本和资源后的回调（不序列化）
 }
 
 // GitHubRelease GitHub发布信息
@@ -272,17 +292,30 @@ type GitHubRelease struct {
 
 // Asset GitHub发布资源
 type Asset struct {
+	ID                 int64  `json:"id"`
 	Name               string `json:"name"`
+	URL                string `json:"url"`          // 资源API地址（配合Accept: application/octet-stream下载）
 	BrowserDownloadURL string `json:"browser_download_url"`
+	ContentType        string `json:"content_type"` // 上传时声明的MIME类型
 	Size               int64  `json:"size"`
 }
 
+// RateLimit API速率限制状态（来自X-RateLimit-*和Retry-After响应头）
+type RateLimit struct {
+	Limit      int           `json:"limit"`                 // 每个时间窗口的请求上限
+	Remaining  int           `json:"remaining"`             // 剩余请求数
+	Used       int           `json:"used"`                  // 已使用的请求数
+	Reset      time.Time     `json:"reset"`                 // 配额重置时间
+	Resource   string        `json:"resource,omitempty"`    // 配额类别（core、search等）
+	RetryAfter time.Duration `json:"retry_after,omitempty"` // 次级速率限制要求的等待时间
+}
+
 // Step 步骤跟踪器中的单个步骤
 type Step struct {
-	Key     string
-	Label   string
-	Status  string
-	Detail  string
+	Key     string `json:"key"`
+	Label   string `json:"label"`
+	Status  string `json:"status"`
+	Detail  string `json:"detail,omitempty"`
 }
 
 // StepTracker 步骤跟踪器，用于显示进度
@@ -368,6 +401,52 @@ func (st *StepTracker) SetStepDone(key, detail string) {
 	}
 }
 
+// OutputFormat 命令输出格式
+//
+// OutputFormat 由全局 --output 标志设置，决定命令结果的呈现方式：
+// - text: 面向人的彩色输出（默认）
+// - json: 命令结束时向标准输出写入一个完整的JSON对象
+// - ndjson: 每个步骤状态变化写入一行JSON事件，最后写入一行结果
+//
+// 结构化模式下横幅和颜色被关闭，所有面向人的提示信息改写到标准错误，
+// 保证标准输出只包含可解析的JSON。
+type OutputFormat string
+
+// 输出格式常量
+const (
+	OutputText   OutputFormat = "text"
+	OutputJSON   OutputFormat = "json"
+	OutputNDJSON OutputFormat = "ndjson"
+)
+
+// OutputResult 结构化输出中的命令结果
+//
+// 字段说明：
+// - Type: 固定为"result"，便于在ndjson事件流中与"step"事件区分
+// - Command: 命令名称（init, download, check, version, config）
+// - Success: 命令是否成功
+// - Steps: 按添加顺序排列的步骤状态
+// - Data: 命令特定的结果数据（工具版本、发布版本、资源等）
+// - Error: 失败时的错误信息
+type OutputResult struct {
+	Type    string                 `json:"type"`
+	Command string                 `json:"command"`
+	Success bool                   `json:"success"`
+	Steps   []*Step                `json:"steps,omitempty"`
+	Data    map[string]interface{} `json:"data,omitempty"`
+	Error   *OutputError           `json:"error,omitempty"`
+}
+
+// OutputError 结构化输出中的错误信息
+//
+// Type 为错误分类（cancelled, network, error），网络错误额外给出
+// NetworkErrorType 的字符串形式（如TIMEOUT、NOT_FOUND）作为Kind。
+type OutputError struct {
+	Type    string `json:"type"`
+	Kind    string `json:"kind,omitempty"`
+	Message string `json:"message"`
+}
+
 // Config 应用程序配置
 type Config struct {
 	Agents      map[string]AgentInfo  `json:"agents"`
@@ -376,12 +455,27 @@ type Config struct {
 }
 
 // DefaultConfig 默认配置
+//
+// DefaultConfig 是各配置层（内置默认值、用户配置、项目配置、
+// 环境变量、命令行标志）合并后的有效默认值。
 type DefaultConfig struct {
-	AIAssistant string `json:"ai_assistant"`
-	ScriptType  string `json:"script_type"`
-	Timeout     time.Duration `json:"timeout"`
+	AIAssistant  string        `json:"ai_assistant"`
+	ScriptType   string        `json:"script_type"`
+	TemplateRepo string        `json:"template_repo"` // 模板源（GitHub的owner/repo或其他发布源URL）
+	TemplateGit  string        `json:"template_git"`  // git仓库模板源（url@ref）
+	Proxy        string        `json:"proxy"`         // HTTP(S)代理地址
+	Timeout      time.Duration `json:"timeout"`
+	Theme        string        `json:"theme"`         // UI主题名称
 }
 
+// DefaultTemplateRepo 默认的模板发布仓库
+const DefaultTemplateRepo = "github/spec-kit"
+
+// DefaultGitHubAPIURL 默认的GitHub API基础URL
+//
+// GitHub Enterprise Server的API位于 https://<host>/api/v3。
+const DefaultGitHubAPIURL = "https://api.github.com"
+
 // SystemInfo 系统信息
 //
 // SystemInfo 结构体封装了当前运行环境的完整系统信息，用于系统兼容性
@@ -606,13 +700,16 @@ type SystemOperations interface {
 // - 脚本类型模板: Shell/PowerShell脚本模板
 // - 项目结构模板: 标准项目目录结构
 //
+// 取消语义：
+// - Download 接收 context.Context，HTTP请求和解压循环都会响应取消
+//
 // 使用示例：
 //   provider := NewTemplateProvider()
-//   path, err := provider.Download(opts)
+//   path, err := provider.Download(ctx, opts)
 //   err = provider.Validate(path)
 //   info, err := provider.GetTemplateInfo(path)
 type TemplateProvider interface {
-	Download(opts DownloadOptions) (string, error)
+	Download(ctx context.Context, opts DownloadOptions) (string, error)
 	Validate(path string) error
 	GetTemplateInfo(path string) (map[string]interface{}, error)
 	ListTemplates(token string) ([]string, error)
@@ -695,6 +792,32 @@ type AuthProvider interface {
 	
 	// GetTokenSource 获取当前令牌来源的字符串描述
 	GetTokenSource() string
+
+	// GetTokenDetails 获取令牌类型、权限和过期时间
+	GetTokenDetails() (*TokenDetails, error)
+}
+
+// TokenDetails 令牌详细信息
+//
+// 经典令牌和OAuth令牌通过Scopes描述权限；细粒度个人访问令牌和GitHub App
+// 安装令牌没有scope，安装令牌的权限通过可访问仓库的Permissions描述。
+type TokenDetails struct {
+	Type        string          `json:"type"`                  // 令牌类型，如 "fine-grained personal access token"
+	Login       string          `json:"login,omitempty"`       // 令牌所属用户，安装令牌为空
+	Scopes      []string        `json:"scopes,omitempty"`      // X-OAuth-Scopes中的权限范围
+	Permissions map[string]bool `json:"permissions,omitempty"` // 安装令牌在可访问仓库上的权限
+	ExpiresAt   *time.Time      `json:"expires_at,omitempty"`  // 过期时间，nil表示不过期或未知
+}
+
+// Expired 令牌是否已过期
+func (d *TokenDetails) Expired() bool {
+	return d.ExpiresAt != nil && !d.ExpiresAt.After(time.Now())
+}
+
+// CloneOptions 克隆选项
+type CloneOptions struct {
+	Ref   string // 要检出的分支、标签或提交哈希，空表示默认分支
+	Depth int    // 浅克隆深度，0表示完整克隆
 }
 
 // GitOperations Git操作接口
@@ -715,27 +838,31 @@ type AuthProvider interface {
 // - 远程协作：克隆、推送、拉取
 // - 状态查询：工作目录状态、提交历史
 //
+// 取消语义：
+// - 所有方法都接收 context.Context，git子进程通过exec.CommandContext启动，
+//   ctx取消时子进程会被终止
+//
 // 使用示例：
 //   git := NewGitOperations()
-//   git.InitRepo("/path/to/project", false)
-//   git.AddAndCommit("/path/to/project", "Initial commit")
-//   git.AddRemote("/path/to/project", "origin", "https://github.com/user/repo.git")
+//   git.InitRepo(ctx, "/path/to/project", false)
+//   git.AddAndCommit(ctx, "/path/to/project", "Initial commit")
+//   git.AddRemote(ctx, "/path/to/project", "origin", "https://github.com/user/repo.git")
 type GitOperations interface {
-	IsRepo(path string) bool
-	InitRepo(path string, quiet bool) (bool, error)
-	AddAndCommit(path string, message string) error
-	GetStatus(path string) (string, error)
-	GetBranch(path string) (string, error)
-	CreateBranch(path, branchName string) error
-	SwitchBranch(path, branchName string) error
-	AddRemote(path, name, url string) error
-	Push(path, remote, branch string) error
-	Pull(path, remote, branch string) error
-	Clone(url, targetPath string) error
-	GetCommitHash(path string) (string, error)
-	GetRemoteURL(path, remote string) (string, error)
-	IsClean(path string) (bool, error)
-	HasUncommittedChanges(path string) (bool, error)
+	IsRepo(ctx context.Context, path string) bool
+	InitRepo(ctx context.Context, path string, quiet bool) (bool, error)
+	AddAndCommit(ctx context.Context, path string, message string) error
+	GetStatus(ctx context.Context, path string) (string, error)
+	GetBranch(ctx context.Context, path string) (string, error)
+	CreateBranch(ctx context.Context, path, branchName string) error
+	SwitchBranch(ctx context.Context, path, branchName string) error
+	AddRemote(ctx context.Context, path, name, url string) error
+	Push(ctx context.Context, path, remote, branch string) error
+	Pull(ctx context.Context, path, remote, branch string) error
+	Clone(ctx context.Context, url, targetPath string, opts *CloneOptions) error
+	GetCommitHash(ctx context.Context, path string) (string, error)
+	GetRemoteURL(ctx context.Context, path, remote string) (string, error)
+	IsClean(ctx context.Context, path string) (bool, error)
+	HasUncommittedChanges(ctx context.Context, path string) (bool, error)
 }
 
 // ToolChecker 工具检查器接口
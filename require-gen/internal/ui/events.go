@@ -0,0 +1,140 @@
+package ui
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+	"specify-cli/internal/types"
+)
+
+// EventStreamRenderer 步骤事件流渲染器
+//
+// EventStreamRenderer 实现 types.StepObserver 接口，每次步骤状态变化
+// 输出一行带时间戳的纯文本，替代在原地重绘的步骤树，适合CI日志等
+// 非交互式输出。
+//
+// 输出格式：
+//
+//	2025-01-02T15:04:05Z [Project Initialization] download_template running - Downloading project template
+//
+// GitHub Actions 模式（设置了 GITHUB_ACTIONS 环境变量）：
+//   - 步骤进入running时输出 ::group::，其后的日志会折叠到该步骤下
+//   - 步骤结束时输出结果行并以 ::endgroup:: 关闭分组
+//   - 步骤失败时额外输出 ::error 注解，使错误显示在运行摘要中
+type EventStreamRenderer struct {
+	out           io.Writer
+	title         string
+	githubActions bool
+	openGroup     string // 当前打开的分组对应的步骤Key
+	now           func() time.Time
+	mu            sync.Mutex
+}
+
+// NewEventStreamRenderer 创建写入out的事件流渲染器
+func NewEventStreamRenderer(title string, out io.Writer) *EventStreamRenderer {
+	return &EventStreamRenderer{
+		out:           out,
+		title:         title,
+		githubActions: os.Getenv("GITHUB_ACTIONS") == "true",
+		now:           time.Now,
+	}
+}
+
+// ShouldStreamEvents 是否应使用事件流渲染步骤
+//
+// 仅在文本输出模式下生效；标准输出不是终端（管道、重定向、CI）时自动启用。
+func ShouldStreamEvents() bool {
+	if IsStructuredOutput() {
+		return false
+	}
+	return !term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// OnStepChanged 实现types.StepObserver接口
+func (r *EventStreamRenderer) OnStepChanged(step *types.Step) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.githubActions {
+		r.renderGitHubActions(step)
+		return
+	}
+
+	fmt.Fprintln(r.out, r.formatLine(step))
+}
+
+// formatLine 生成单个状态变化的日志行
+//
+// 详情中的换行替换为" | "，每个状态变化始终只占一行。
+func (r *EventStreamRenderer) formatLine(step *types.Step) string {
+	line := fmt.Sprintf("%s [%s] %s %s",
+		r.now().UTC().Format(time.RFC3339), r.title, step.Key, step.Status)
+	if step.Detail != "" {
+		line += " - " + detailLineReplacer.Replace(step.Detail)
+	}
+	return line
+}
+
+// detailLineReplacer 将多行详情合并为一行
+var detailLineReplacer = strings.NewReplacer("\r\n", " | ", "\n", " | ", "\r", " | ")
+
+// renderGitHubActions 以GitHub Actions工作流命令输出状态变化
+func (r *EventStreamRenderer) renderGitHubActions(step *types.Step) {
+	switch step.Status {
+	case types.StatusRunning:
+		if r.openGroup != step.Key {
+			r.closeGroup()
+			fmt.Fprintf(r.out, "::group::%s\n", escapeWorkflowData(step.Label))
+			r.openGroup = step.Key
+		}
+		fmt.Fprintln(r.out, r.formatLine(step))
+
+	case types.StatusDone, types.StatusSkipped, types.StatusError:
+		fmt.Fprintln(r.out, r.formatLine(step))
+		if r.openGroup == step.Key {
+			r.closeGroup()
+		}
+		if step.Status == types.StatusError {
+			fmt.Fprintf(r.out, "::error title=%s::%s\n",
+				escapeWorkflowProperty(step.Label), escapeWorkflowData(step.Detail))
+		}
+
+	default:
+		fmt.Fprintln(r.out, r.formatLine(step))
+	}
+}
+
+// closeGroup 关闭当前打开的分组
+func (r *EventStreamRenderer) closeGroup() {
+	if r.openGroup == "" {
+		return
+	}
+	fmt.Fprintln(r.out, "::endgroup::")
+	r.openGroup = ""
+}
+
+// Close 关闭仍然打开的分组（步骤被中断时）
+func (r *EventStreamRenderer) Close() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closeGroup()
+}
+
+// escapeWorkflowData 转义工作流命令的消息部分
+func escapeWorkflowData(s string) string {
+	s = strings.ReplaceAll(s, "%", "%25")
+	s = strings.ReplaceAll(s, "\r", "%0D")
+	return strings.ReplaceAll(s, "\n", "%0A")
+}
+
+// escapeWorkflowProperty 转义工作流命令的属性值
+func escapeWorkflowProperty(s string) string {
+	s = escapeWorkflowData(s)
+	s = strings.ReplaceAll(s, ":", "%3A")
+	return strings.ReplaceAll(s, ",", "%2C")
+}
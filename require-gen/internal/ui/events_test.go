@@ -0,0 +1,64 @@
+package ui
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"specify-cli/internal/types"
+)
+
+// newTestEventRenderer 创建使用固定时间的事件流渲染器
+func newTestEventRenderer(t *testing.T, githubActions bool) (*EventStreamRenderer, *bytes.Buffer) {
+	t.Helper()
+	if githubActions {
+		t.Setenv("GITHUB_ACTIONS", "true")
+	} else {
+		t.Setenv("GITHUB_ACTIONS", "")
+	}
+
+	var buf bytes.Buffer
+	renderer := NewEventStreamRenderer("Project Initialization", &buf)
+	renderer.now = func() time.Time { return time.Date(2025, 1, 2, 15, 4, 5, 0, time.UTC) }
+	return renderer, &buf
+}
+
+// TestEventStreamRenderer 测试纯文本事件行
+func TestEventStreamRenderer(t *testing.T) {
+	renderer, buf := newTestEventRenderer(t, false)
+
+	renderer.OnStepChanged(&types.Step{Key: "download_template", Label: "Download template", Status: types.StatusRunning, Detail: "Downloading project template"})
+	renderer.OnStepChanged(&types.Step{Key: "download_template", Label: "Download template", Status: types.StatusDone})
+	renderer.Close()
+
+	expected := "2025-01-02T15:04:05Z [Project Initialization] download_template running - Downloading project template\n" +
+		"2025-01-02T15:04:05Z [Project Initialization] download_template done\n"
+	assert.Equal(t, expected, buf.String())
+}
+
+// TestEventStreamRenderer_GitHubActions 测试GitHub Actions的分组和错误注解
+func TestEventStreamRenderer_GitHubActions(t *testing.T) {
+	renderer, buf := newTestEventRenderer(t, true)
+
+	renderer.OnStepChanged(&types.Step{Key: "check_tools", Label: "Check tools", Status: types.StatusRunning})
+	renderer.OnStepChanged(&types.Step{Key: "check_tools", Label: "Check tools", Status: types.StatusDone, Detail: "git found"})
+	renderer.OnStepChanged(&types.Step{Key: "download", Label: "Download, extract: template", Status: types.StatusRunning})
+	renderer.OnStepChanged(&types.Step{Key: "download", Label: "Download, extract: template", Status: types.StatusError, Detail: "100% failed\nretry later"})
+	renderer.OnStepChanged(&types.Step{Key: "init_git", Label: "Initialize Git", Status: types.StatusRunning})
+	renderer.Close()
+
+	expected := "::group::Check tools\n" +
+		"2025-01-02T15:04:05Z [Project Initialization] check_tools running\n" +
+		"2025-01-02T15:04:05Z [Project Initialization] check_tools done - git found\n" +
+		"::endgroup::\n" +
+		"::group::Download, extract: template\n" +
+		"2025-01-02T15:04:05Z [Project Initialization] download running\n" +
+		"2025-01-02T15:04:05Z [Project Initialization] download error - 100% failed | retry later\n" +
+		"::endgroup::\n" +
+		"::error title=Download%2C extract%3A template::100%25 failed%0Aretry later\n" +
+		"::group::Initialize Git\n" +
+		"2025-01-02T15:04:05Z [Project Initialization] init_git running\n" +
+		"::endgroup::\n"
+	assert.Equal(t, expected, buf.String())
+}
@@ -0,0 +1,148 @@
+package ui
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/fatih/color"
+	"specify-cli/internal/types"
+)
+
+// 全局输出状态
+var (
+	outputFormat           = types.OutputText
+	resultWriter io.Writer = os.Stdout
+	outputMutex  sync.Mutex
+)
+
+// ParseOutputFormat 解析 --output 标志的取值
+func ParseOutputFormat(value string) (types.OutputFormat, error) {
+	switch types.OutputFormat(value) {
+	case "", types.OutputText:
+		return types.OutputText, nil
+	case types.OutputJSON:
+		return types.OutputJSON, nil
+	case types.OutputNDJSON:
+		return types.OutputNDJSON, nil
+	default:
+		return types.OutputText, fmt.Errorf("invalid output format '%s' (valid: text, json, ndjson)", value)
+	}
+}
+
+// SetOutputFormat 设置全局输出格式
+//
+// 切换到json或ndjson时：
+//   - 关闭颜色，横幅和步骤跟踪器不再渲染
+//   - 保存原始标准输出作为结果写入目标
+//   - 将os.Stdout和color.Output重定向到标准错误，
+//     这样各层直接打印的提示信息不会混入JSON结果
+//
+// 该函数应在命令执行前调用一次（由根命令的PersistentPreRunE完成）。
+func SetOutputFormat(format types.OutputFormat) {
+	outputMutex.Lock()
+	defer outputMutex.Unlock()
+
+	previous := outputFormat
+	outputFormat = format
+	if format == types.OutputText || previous != types.OutputText {
+		return
+	}
+
+	resultWriter = os.Stdout
+	os.Stdout = os.Stderr
+	color.Output = os.Stderr
+	color.NoColor = true
+}
+
+// GetOutputFormat 获取当前输出格式
+func GetOutputFormat() types.OutputFormat {
+	outputMutex.Lock()
+	defer outputMutex.Unlock()
+	return outputFormat
+}
+
+// IsStructuredOutput 当前是否为json或ndjson输出
+func IsStructuredOutput() bool {
+	return GetOutputFormat() != types.OutputText
+}
+
+// EmitResult 输出命令的结构化结果
+//
+// 文本模式下不输出任何内容；json模式输出带缩进的单个对象，
+// ndjson模式输出单行对象。tracker可以为nil。
+func EmitResult(command string, tracker *StepTracker, data map[string]interface{}, err error) {
+	if !IsStructuredOutput() {
+		return
+	}
+
+	result := &types.OutputResult{
+		Type:    "result",
+		Command: command,
+		Success: err == nil,
+		Data:    data,
+	}
+	if tracker != nil {
+		result.Steps = tracker.OrderedSteps()
+	}
+	if err != nil {
+		result.Error = NewOutputError(err)
+	}
+
+	writeJSONLine(result, GetOutputFormat() == types.OutputJSON)
+}
+
+// NewOutputError 将错误转换为结构化错误信息
+func NewOutputError(err error) *types.OutputError {
+	outErr := &types.OutputError{
+		Type:    "error",
+		Message: err.Error(),
+	}
+
+	var netErr *types.NetworkError
+	switch {
+	case errors.Is(err, context.Canceled):
+		outErr.Type = "cancelled"
+	case errors.As(err, &netErr):
+		outErr.Type = "network"
+		outErr.Kind = netErr.Type.String()
+	}
+
+	return outErr
+}
+
+// stepEvent ndjson模式下的步骤事件
+type stepEvent struct {
+	Type    string `json:"type"`
+	Tracker string `json:"tracker"`
+	*types.Step
+}
+
+// ndjsonStepObserver 将步骤状态变化写成ndjson事件
+type ndjsonStepObserver struct {
+	title string
+}
+
+// OnStepChanged 实现types.StepObserver接口
+func (o *ndjsonStepObserver) OnStepChanged(step *types.Step) {
+	stepCopy := *step
+	writeJSONLine(&stepEvent{Type: "step", Tracker: o.title, Step: &stepCopy}, false)
+}
+
+// writeJSONLine 向结果输出写入一个JSON值
+func writeJSONLine(v interface{}, indent bool) {
+	outputMutex.Lock()
+	defer outputMutex.Unlock()
+
+	encoder := json.NewEncoder(resultWriter)
+	if indent {
+		encoder.SetIndent("", "  ")
+	}
+	if err := encoder.Encode(v); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write output: %v\n", err)
+	}
+}
@@ -0,0 +1,193 @@
+package ui
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fatih/color"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"specify-cli/internal/types"
+)
+
+// captureOutput 将标准输出和标准错误重定向到临时文件，测试结束后恢复全局输出状态
+func captureOutput(t *testing.T) (stdout, stderr *os.File) {
+	t.Helper()
+
+	dir := t.TempDir()
+	var err error
+	stdout, err = os.Create(filepath.Join(dir, "stdout"))
+	require.NoError(t, err)
+	stderr, err = os.Create(filepath.Join(dir, "stderr"))
+	require.NoError(t, err)
+
+	origStdout, origStderr := os.Stdout, os.Stderr
+	origColorOutput, origNoColor := color.Output, color.NoColor
+	origFormat, origWriter := outputFormat, resultWriter
+	t.Cleanup(func() {
+		os.Stdout, os.Stderr = origStdout, origStderr
+		color.Output, color.NoColor = origColorOutput, origNoColor
+		outputFormat, resultWriter = origFormat, origWriter
+		stdout.Close()
+		stderr.Close()
+	})
+
+	os.Stdout, os.Stderr = stdout, stderr
+	outputFormat, resultWriter = types.OutputText, stdout
+	return stdout, stderr
+}
+
+// readOutput 读取捕获的输出
+func readOutput(t *testing.T, f *os.File) string {
+	t.Helper()
+	data, err := os.ReadFile(f.Name())
+	require.NoError(t, err)
+	return string(data)
+}
+
+// TestParseOutputFormat 测试--output取值解析
+func TestParseOutputFormat(t *testing.T) {
+	for value, want := range map[string]types.OutputFormat{
+		"":       types.OutputText,
+		"text":   types.OutputText,
+		"json":   types.OutputJSON,
+		"ndjson": types.OutputNDJSON,
+	} {
+		format, err := ParseOutputFormat(value)
+		require.NoError(t, err, value)
+		assert.Equal(t, want, format, value)
+	}
+
+	_, err := ParseOutputFormat("yaml")
+	assert.Error(t, err)
+}
+
+// TestSetOutputFormat 测试结构化输出时标准输出重定向到标准错误并关闭颜色
+func TestSetOutputFormat(t *testing.T) {
+	stdout, stderr := captureOutput(t)
+	color.NoColor = false
+
+	SetOutputFormat(types.OutputJSON)
+	assert.True(t, IsStructuredOutput())
+	assert.Same(t, stderr, os.Stdout)
+	assert.Same(t, stderr, color.Output)
+	assert.True(t, color.NoColor)
+
+	// 直接打印的提示信息进入标准错误，结果写入原始标准输出
+	fmt.Println("Downloading template...")
+	EmitResult("version", nil, map[string]interface{}{"version": "1.0.0"}, nil)
+
+	assert.Equal(t, "Downloading template...\n", readOutput(t, stderr))
+	assert.Contains(t, readOutput(t, stdout), `"command": "version"`)
+
+	// 再次切换格式不会重复重定向
+	SetOutputFormat(types.OutputNDJSON)
+	assert.Same(t, stdout, resultWriter)
+	assert.Equal(t, types.OutputNDJSON, GetOutputFormat())
+}
+
+// TestEmitResult 测试json和ndjson结果的格式
+func TestEmitResult(t *testing.T) {
+	t.Run("text", func(t *testing.T) {
+		stdout, _ := captureOutput(t)
+		EmitResult("init", nil, nil, nil)
+		assert.Empty(t, readOutput(t, stdout))
+	})
+
+	t.Run("json", func(t *testing.T) {
+		stdout, _ := captureOutput(t)
+		SetOutputFormat(types.OutputJSON)
+
+		tracker := NewStepTracker("Download")
+		tracker.AddStep("fetch", "Fetch release")
+		tracker.AddStep("extract", "Extract template")
+		tracker.SetStepDone("fetch", "v1.0.0")
+
+		EmitResult("download", tracker, map[string]interface{}{"release": "v1.0.0"}, nil)
+
+		expected := `{
+  "type": "result",
+  "command": "download",
+  "success": true,
+  "steps": [
+    {
+      "key": "fetch",
+      "label": "Fetch release",
+      "status": "done",
+      "detail": "v1.0.0"
+    },
+    {
+      "key": "extract",
+      "label": "Extract template",
+      "status": "pending"
+    }
+  ],
+  "data": {
+    "release": "v1.0.0"
+  }
+}
+`
+		assert.Equal(t, expected, readOutput(t, stdout))
+	})
+
+	t.Run("ndjson", func(t *testing.T) {
+		stdout, _ := captureOutput(t)
+		SetOutputFormat(types.OutputNDJSON)
+
+		tracker := NewStepTracker("Download")
+		tracker.AddStep("fetch", "Fetch release")
+		tracker.SetStepRunning("fetch", "")
+		tracker.SetStepError("fetch", "timeout")
+
+		netErr := &types.NetworkError{Type: types.NetworkErrorTypeTimeout, Message: "request timed out"}
+		EmitResult("download", tracker, nil, fmt.Errorf("failed to get latest release: %w", netErr))
+
+		lines := strings.Split(strings.TrimSpace(readOutput(t, stdout)), "\n")
+		require.Len(t, lines, 3)
+		assert.JSONEq(t, `{"type":"step","tracker":"Download","key":"fetch","label":"Fetch release","status":"running"}`, lines[0])
+		assert.JSONEq(t, `{"type":"step","tracker":"Download","key":"fetch","label":"Fetch release","status":"error","detail":"timeout"}`, lines[1])
+		assert.JSONEq(t, `{
+			"type": "result",
+			"command": "download",
+			"success": false,
+			"steps": [{"key": "fetch", "label": "Fetch release", "status": "error", "detail": "timeout"}],
+			"error": {"type": "network", "kind": "TIMEOUT", "message": "failed to get latest release: request timed out"}
+		}`, lines[2])
+	})
+}
+
+// TestNewOutputError 测试错误分类
+func TestNewOutputError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want *types.OutputError
+	}{
+		{
+			name: "generic",
+			err:  errors.New("template not found"),
+			want: &types.OutputError{Type: "error", Message: "template not found"},
+		},
+		{
+			name: "cancelled",
+			err:  fmt.Errorf("initialization interrupted: %w", context.Canceled),
+			want: &types.OutputError{Type: "cancelled", Message: "initialization interrupted: context canceled"},
+		},
+		{
+			name: "network",
+			err:  &types.NetworkError{Type: types.NetworkErrorTypeNotFound, Message: "asset not found"},
+			want: &types.OutputError{Type: "network", Kind: "NOT_FOUND", Message: "asset not found"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, NewOutputError(tt.err))
+		})
+	}
+}
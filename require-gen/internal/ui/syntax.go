@@ -117,6 +117,11 @@ func NewSyntaxHighlighter(language string, theme *SyntaxTheme) *SyntaxHighlighte
 
 // Highlight 高亮代码
 func (sh *SyntaxHighlighter) Highlight(code string) string {
+	// 差异格式按行着色，不适用关键字规则
+	if sh.language == "diff" {
+		return sh.highlightDiff(code)
+	}
+
 	rules, exists := languageRules[sh.language]
 	if !exists {
 		return code // 不支持的语言，返回原始代码
@@ -145,6 +150,24 @@ func (sh *SyntaxHighlighter) Highlight(code string) string {
 	return result
 }
 
+// highlightDiff 高亮统一差异格式（+新增行、-删除行、@@块头）
+func (sh *SyntaxHighlighter) highlightDiff(code string) string {
+	lines := strings.Split(code, "\n")
+	for i, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			lines[i] = sh.theme.Keyword.Sprint(line)
+		case strings.HasPrefix(line, "@@"):
+			lines[i] = sh.theme.Type.Sprint(line)
+		case strings.HasPrefix(line, "+"):
+			lines[i] = sh.theme.String.Sprint(line)
+		case strings.HasPrefix(line, "-"):
+			lines[i] = sh.theme.Operator.Sprint(line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
 // highlightStrings 高亮字符串
 func (sh *SyntaxHighlighter) highlightStrings(code string) string {
 	// 双引号字符串
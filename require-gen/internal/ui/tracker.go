@@ -2,6 +2,7 @@ package ui
 
 import (
 	"fmt"
+	"os"
 	"sort"
 	"strings"
 	"sync"
@@ -14,9 +15,11 @@ import (
 type StepTracker struct {
 	title       string
 	steps       map[string]*types.Step
+	order       []string // 步骤添加顺序
 	statusOrder map[string]int
 	mutex       sync.RWMutex
 	observers   []types.StepObserver
+	events      *EventStreamRenderer // 非终端输出时的事件流渲染器
 	
 	// 嵌套支持
 	children    []*StepTracker
@@ -75,6 +78,17 @@ func NewStepTracker(title string, options ...StepTrackerOption) *StepTracker {
 	for _, option := range options {
 		option(tracker)
 	}
+
+	// ndjson模式下实时输出步骤事件
+	if GetOutputFormat() == types.OutputNDJSON {
+		tracker.observers = append(tracker.observers, &ndjsonStepObserver{title: title})
+	}
+
+	// 标准输出不是终端时按行输出状态变化，代替原地重绘
+	if ShouldStreamEvents() {
+		tracker.events = NewEventStreamRenderer(title, os.Stdout)
+		tracker.observers = append(tracker.observers, tracker.events)
+	}
 	
 	return tracker
 }
@@ -90,6 +104,9 @@ func (st *StepTracker) AddStep(key, label string) {
 		Status: types.StatusPending,
 		Detail: "",
 	}
+	if _, exists := st.steps[key]; !exists {
+		st.order = append(st.order, key)
+	}
 	st.steps[key] = step
 }
 
@@ -156,8 +173,32 @@ func (st *StepTracker) GetAllSteps() []*types.Step {
 	return steps
 }
 
+// OrderedSteps 按添加顺序返回所有步骤的快照
+func (st *StepTracker) OrderedSteps() []*types.Step {
+	st.mutex.RLock()
+	defer st.mutex.RUnlock()
+
+	steps := make([]*types.Step, 0, len(st.order))
+	for _, key := range st.order {
+		step := *st.steps[key]
+		steps = append(steps, &step)
+	}
+	return steps
+}
+
 // Display 显示步骤跟踪器
+//
+// 结构化输出模式（json/ndjson）下不渲染，步骤状态由EmitResult统一输出；
+// 事件流模式下状态变化已逐行输出，这里只关闭仍然打开的日志分组。
 func (st *StepTracker) Display() {
+	if IsStructuredOutput() {
+		return
+	}
+	if st.events != nil {
+		st.events.Close()
+		return
+	}
+
 	st.mutex.RLock()
 	defer st.mutex.RUnlock()
 
@@ -222,7 +222,13 @@ func (ui *UIManager) CreatePanel(content, title string, options ...PanelOption)
 }
 
 // ShowBanner displays the application banner with enhanced styling
+//
+// The banner is suppressed in structured output mode (--output json|ndjson).
 func ShowBanner() {
+	if IsStructuredOutput() {
+		return
+	}
+
 	// Use enhanced banner for better visual effect
 	ShowBannerEnhanced()
 }